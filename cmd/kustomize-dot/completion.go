@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// completionFlag is the flag, which urfave/cli appends to the arguments, when
+// asking for shell completions.
+const completionFlag = "--generate-bash-completion"
+
+// completers maps the names of the filter flags to the functions, which return
+// the values of the flags found in the resources.
+var completers = map[string]func(resources []*resource.Resource) ([]string, error){
+	"drop-kind":       completeKinds,
+	"keep-kind":       completeKinds,
+	"drop-owner-kind": completeKinds,
+	"keep-owner-kind": completeKinds,
+	"same-rank-kind":  completeKinds,
+	"drop-namespace":  completeNamespaces,
+	"keep-namespace":  completeNamespaces,
+	"drop-origin-path": func(resources []*resource.Resource) ([]string, error) {
+		return parser.Origins(resources)
+	},
+	"keep-origin-path": func(resources []*resource.Resource) ([]string, error) {
+		return parser.Origins(resources)
+	},
+}
+
+// completeKinds returns the kinds of the given resources.
+func completeKinds(resources []*resource.Resource) ([]string, error) {
+	return parser.Kinds(resources), nil
+}
+
+// completeNamespaces returns the namespaces of the given resources.
+func completeNamespaces(resources []*resource.Resource) ([]string, error) {
+	return parser.Namespaces(resources), nil
+}
+
+// completeFilterValues returns a [cli.BashCompleteFunc] for the given command,
+// which completes the values of the filter flags against the resources from
+// the file passed with -f. Otherwise the flags of the command are completed.
+func completeFilterValues(cmd *cli.Command) cli.BashCompleteFunc {
+	complete := cli.DefaultCompleteWithFlags(cmd)
+
+	return func(ctx *cli.Context) {
+		values, ok := filterValues(ctx, os.Args)
+		if !ok {
+			complete(ctx)
+			return
+		}
+
+		for _, v := range values {
+			fmt.Fprintln(ctx.App.Writer, v)
+		}
+	}
+}
+
+// filterValues returns the values of the filter flag, which is being
+// completed in the given arguments. It returns false, if the arguments don't
+// end with a filter flag, or the resources file cannot be read.
+func filterValues(ctx *cli.Context, args []string) ([]string, bool) {
+	if len(args) < 2 || args[len(args)-1] != completionFlag {
+		return nil, false
+	}
+
+	prev := strings.TrimLeft(args[len(args)-2], "-")
+	var complete func(resources []*resource.Resource) ([]string, error)
+	for _, flag := range ctx.Command.Flags {
+		if slices.Contains(flag.Names(), prev) {
+			complete = completers[flag.Names()[0]]
+			break
+		}
+	}
+	if complete == nil || ctx.Path("file") == "" {
+		return nil, false
+	}
+
+	resources, err := readResources(ctx.Path("file"))
+	if err != nil {
+		return nil, false
+	}
+	values, err := complete(resources)
+	if err != nil {
+		return nil, false
+	}
+
+	return values, true
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestFilterValues(t *testing.T) {
+	path := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc   string
+		file   string
+		args   []string
+		want   []string
+		wantOK bool
+	}
+
+	origins := []string{
+		"examples/helloWorld/configMap.yaml",
+		"examples/helloWorld/deployment.yaml",
+		"examples/helloWorld/service.yaml",
+	}
+	testCases := []testCase{
+		{
+			desc:   "kinds",
+			file:   path,
+			args:   []string{"kustomize-dot", "generate", "-f", path, "--keep-kind", completionFlag},
+			want:   []string{"ConfigMap", "Deployment", "Service"},
+			wantOK: true,
+		},
+		{
+			desc:   "alias",
+			file:   path,
+			args:   []string{"kustomize-dot", "generate", "-f", path, "-kk", completionFlag},
+			want:   []string{"ConfigMap", "Deployment", "Service"},
+			wantOK: true,
+		},
+		{
+			desc:   "namespaces",
+			file:   path,
+			args:   []string{"kustomize-dot", "generate", "-f", path, "--drop-namespace", completionFlag},
+			want:   []string{"default"},
+			wantOK: true,
+		},
+		{
+			desc:   "origins",
+			file:   path,
+			args:   []string{"kustomize-dot", "generate", "-f", path, "--keep-origin-path", completionFlag},
+			want:   origins,
+			wantOK: true,
+		},
+		{
+			desc: "not a filter",
+			file: path,
+			args: []string{"kustomize-dot", "generate", "-f", path, "--layout", completionFlag},
+		},
+		{
+			desc: "not completing",
+			file: path,
+			args: []string{"kustomize-dot", "generate", "-f", path, "--keep-kind"},
+		},
+		{
+			desc: "no resources file",
+			args: []string{"kustomize-dot", "generate", "--keep-kind", completionFlag},
+		},
+		{
+			desc: "missing resources file",
+			file: path + ".missing",
+			args: []string{"kustomize-dot", "generate", "-f", path + ".missing", "--keep-kind", completionFlag},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var flags []string
+			if tc.file != "" {
+				flags = []string{"--file", tc.file}
+			}
+			ctx := newTestContext(t, newGenerateCommand(), flags...)
+
+			got, ok := filterValues(ctx, tc.args)
+			if ok != tc.wantOK {
+				t.Fatalf("want ok %t, got %t", tc.wantOK, ok)
+			}
+			for _, want := range tc.want {
+				if !slices.Contains(got, want) {
+					t.Fatalf("want values containing %q, got %q", want, got)
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("want values %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCompleteFilterValues(t *testing.T) {
+	path := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc string
+		args []string
+		want []string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "generate",
+			args: []string{"generate", "-f", path, "--drop-kind"},
+			want: []string{"ConfigMap", "Deployment", "Service"},
+		},
+		{
+			desc: "render",
+			args: []string{"render", "-f", path, "--keep-namespace"},
+			want: []string{"default"},
+		},
+		{
+			desc: "prune",
+			args: []string{"prune", "-f", path, "--drop-kind"},
+			want: []string{"ConfigMap", "Deployment", "Service"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			args := append(tc.args, completionFlag)
+			saved := os.Args
+			os.Args = append([]string{"kustomize-dot"}, args...)
+			t.Cleanup(func() {
+				os.Args = saved
+			})
+
+			out, _, err := runApp(t, args...)
+			if err != nil {
+				t.Fatalf("completion failed: %s", err)
+			}
+			if got := strings.Fields(out); !slices.Equal(got, tc.want) {
+				t.Fatalf("want completions %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// Flags are completed, unless a filter value is being completed
+	args := []string{"generate", "-f", path, "--keep", completionFlag}
+	saved := os.Args
+	os.Args = append([]string{"kustomize-dot"}, args...)
+	defer func() {
+		os.Args = saved
+	}()
+
+	out, _, err := runApp(t, args...)
+	if err != nil {
+		t.Fatalf("completion failed: %s", err)
+	}
+	if !strings.Contains(out, "--keep-kind") {
+		t.Fatalf("want flag completions, got %q", out)
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// errUnknownConfigKey is returned when the config file contains a key, which
+// does not name a flag of the generate or render commands.
+var errUnknownConfigKey = errors.New("unknown config key")
+
+// errInvalidConfigValue is returned when a config value cannot be used as the
+// value of the respective flag.
+var errInvalidConfigValue = errors.New("invalid config value")
+
+// configFileName is the name of the project-local config file.
+const configFileName = ".kustomize-dot.yaml"
+
+// configFiles returns the paths of the config files in order of increasing
+// precedence, i.e. the user config file from $XDG_CONFIG_HOME, followed by the
+// project-local config file. When a config file was explicitly specified, only
+// that file is returned.
+func configFiles(ctx *cli.Context) []string {
+	if path := ctx.Path("config"); path != "" {
+		return []string{path}
+	}
+
+	files := make([]string, 0)
+	if dir, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(dir, "kustomize-dot", "config.yaml"))
+	}
+	files = append(files, configFileName)
+
+	return files
+}
+
+// loadConfig reads and merges the config files. The config files map the
+// names of the generate and render command flags to their default values.
+// Missing config files are skipped, unless the config file was explicitly
+// specified.
+func loadConfig(ctx *cli.Context) (map[string]any, error) {
+	config := make(map[string]any)
+	explicit := ctx.Path("config") != ""
+
+	for _, path := range configFiles(ctx) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && !explicit {
+				continue
+			}
+			return nil, err
+		}
+
+		var values map[string]any
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for name, value := range values {
+			config[name] = value
+		}
+	}
+
+	return config, nil
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// configValues returns the config value as a list of flag values. Lists are
+// returned item by item, so that slice flags receive each of them, and
+// mappings are returned as key/value pairs, e.g. Deployment=yellow.
+func configValues(value any) []string {
+	values := make([]string, 0)
+	switch v := value.(type) {
+	case nil:
+	case []any:
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			if attrs, ok := v[key].(map[string]any); ok {
+				// Node attributes, e.g. Deployment:shape=component
+				values = append(values, key+kindSeparator+strings.Join(configValues(attrs), ","))
+				continue
+			}
+			values = append(values, fmt.Sprintf("%s%s%v", key, kvSeparator, v[key]))
+		}
+	default:
+		values = append(values, fmt.Sprint(v))
+	}
+
+	return values
+}
+
+// applyConfig sets the flags of the command, which were not set on the
+// command-line or via environment variables, to their values from the selected
+// filter presets, followed by the selected profile, and the config files.
+func applyConfig(ctx *cli.Context) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := loadProfiles(config)
+	if err != nil {
+		return err
+	}
+	delete(config, profilesConfigKey)
+
+	presets, err := loadPresets(config)
+	if err != nil {
+		return err
+	}
+	delete(config, presetsConfigKey)
+
+	// The config file is shared by the commands, so keys of the other
+	// commands are skipped.
+	known := make(map[string]bool)
+	for _, cmd := range []*cli.Command{newGenerateCommand(), newRenderCommand()} {
+		for _, flag := range cmd.Flags {
+			for _, name := range flag.Names() {
+				known[name] = true
+			}
+		}
+	}
+
+	flags := make(map[string]bool)
+	for _, flag := range ctx.Command.Flags {
+		for _, name := range flag.Names() {
+			flags[name] = true
+		}
+	}
+
+	sources := []map[string]any{config}
+	name := ctx.String("profile")
+	if name == "" && config["profile"] != nil {
+		name = fmt.Sprint(config["profile"])
+	}
+	if name != "" {
+		profile, err := getProfile(profiles, name)
+		if err != nil {
+			return err
+		}
+		sources = []map[string]any{profile, config}
+	}
+
+	names := ctx.StringSlice("preset")
+	if len(names) == 0 {
+		names = configValues(config["preset"])
+	}
+	if len(names) > 0 {
+		preset, err := mergePresets(presets, names)
+		if err != nil {
+			return err
+		}
+		sources = append([]map[string]any{preset}, sources...)
+	}
+
+	for _, source := range sources {
+		for _, name := range sortedKeys(source) {
+			if !known[name] {
+				return fmt.Errorf("%w: %s", errUnknownConfigKey, name)
+			}
+			if !flags[name] || ctx.IsSet(name) {
+				continue
+			}
+			for _, value := range configValues(source[name]) {
+				if err := ctx.Set(name, value); err != nil {
+					return fmt.Errorf("%w: %s: %w", errInvalidConfigValue, name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// configSpecFields maps the names of the generate command flags to the names
+// of the plugin spec fields, where the names do not follow from the flag
+// names.
+var configSpecFields = map[string]string{
+	"query":       "Queries",
+	"depth":       "FocusDepth",
+	"node-attr":   "NodeAttributes",
+	"graph-attr":  "GraphAttributes",
+	"orphan-attr": "OrphanAttributes",
+}
+
+// configSpecField returns the plugin spec field, which corresponds to the flag
+// with the given name.
+func configSpecField(name string) (reflect.StructField, bool) {
+	candidates := []string{configSpecFields[name]}
+	if candidates[0] == "" {
+		camel := strings.ReplaceAll(name, "-", "")
+		candidates = []string{camel, camel + "s", camel + "es"}
+	}
+
+	specType := reflect.TypeOf(pluginSpec{})
+	for _, candidate := range candidates {
+		field, ok := specType.FieldByNameFunc(func(s string) bool {
+			return strings.EqualFold(s, candidate)
+		})
+		if ok {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// configSpec converts the config values to plugin spec values, which are
+// keyed by the spec field names. Config keys, which have no plugin spec
+// counterpart, e.g. output or watch, are skipped.
+func configSpec(config map[string]any) (map[string]any, error) {
+	spec := make(map[string]any)
+	for name, value := range config {
+		field, ok := configSpecField(name)
+		if !ok {
+			continue
+		}
+
+		values := configValues(value)
+		switch field.Type {
+		case reflect.TypeOf([]string{}):
+			spec[field.Name] = values
+		case reflect.TypeOf(map[string]string{}):
+			var pairs []*kv
+			var err error
+			switch name {
+			case "highlight-name-regex":
+				pairs, err = parseRegexpColors(values...)
+			case "graph-attr", "orphan-attr":
+				pairs, err = parseGraphAttributes(values...)
+			default:
+				pairs, err = parseKV(values...)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", errInvalidConfigValue, name, err)
+			}
+			m := make(map[string]string, len(pairs))
+			for _, pair := range pairs {
+				m[pair.key] = pair.val
+			}
+			spec[field.Name] = m
+		case reflect.TypeOf(map[string]map[string]string{}):
+			items, err := parseNodeAttributes(values...)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", errInvalidConfigValue, name, err)
+			}
+			m := make(map[string]map[string]string, len(items))
+			for _, item := range items {
+				m[item.kind] = item.attrs
+			}
+			spec[field.Name] = m
+		default:
+			spec[field.Name] = value
+		}
+	}
+
+	return spec, nil
+}
+
+// applyConfigSpec sets the fields of the plugin spec to their values from the
+// config files. The spec from the function config is applied on top of it.
+func applyConfigSpec(ctx *cli.Context, spec *pluginSpec) error {
+	config, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	values, err := configSpec(config)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, spec)
+}
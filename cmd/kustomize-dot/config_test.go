@@ -0,0 +1,323 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+)
+
+// writeTestFile writes the given data to the file at the given path,
+// creating its directory.
+func writeTestFile(t *testing.T, path string, data string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("creating directory failed: %s", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("writing %s failed: %s", path, err)
+	}
+}
+
+// graphResources returns which of the hello world resources are part of the
+// given graph.
+func graphResources(graph string) []string {
+	names := make([]string, 0)
+	for _, name := range []string{testConfigMap, testDeployment, testService} {
+		if strings.Contains(graph, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func TestConfigValues(t *testing.T) {
+	type testCase struct {
+		desc  string
+		value any
+		want  []string
+	}
+
+	testCases := []testCase{
+		{desc: "null", value: nil, want: []string{}},
+		{desc: "scalar", value: "LR", want: []string{"LR"}},
+		{desc: "bool", value: true, want: []string{"true"}},
+		{desc: "list", value: []any{"Secret", 1}, want: []string{"Secret", "1"}},
+		{
+			desc:  "mapping",
+			value: map[string]any{"service": "yellow", "deployment": "lightgreen"},
+			want:  []string{"deployment=lightgreen", "service=yellow"},
+		},
+		{
+			desc:  "node attributes",
+			value: map[string]any{"Deployment": map[string]any{"shape": "component", "color": "red"}},
+			want:  []string{"Deployment:color=red,shape=component"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := configValues(tc.value); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want values %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc          string
+		userConfig    string
+		projectConfig string
+		otherConfig   string
+		args          []string
+		env           map[string]string
+		want          []string
+		wantErr       error
+		wantErrText   string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "no config",
+			want: []string{testConfigMap, testDeployment, testService},
+		},
+		{
+			desc:       "user config",
+			userConfig: "drop-kind: [ConfigMap]\n",
+			want:       []string{testDeployment, testService},
+		},
+		{
+			desc:          "project config",
+			projectConfig: "drop-kind: [Service]\n",
+			want:          []string{testConfigMap, testDeployment},
+		},
+		{
+			desc:          "project config overrides user config",
+			userConfig:    "drop-kind: [ConfigMap]\n",
+			projectConfig: "drop-kind: [Service]\n",
+			want:          []string{testConfigMap, testDeployment},
+		},
+		{
+			desc:          "configs are merged",
+			userConfig:    "drop-kind: [ConfigMap]\n",
+			projectConfig: "keep-namespace: [default]\n",
+			want:          []string{testDeployment, testService},
+		},
+		{
+			desc:          "flags override config",
+			projectConfig: "drop-kind: [ConfigMap]\n",
+			args:          []string{"--drop-kind", "Service"},
+			want:          []string{testConfigMap, testDeployment},
+		},
+		{
+			desc:          "environment variables override config",
+			projectConfig: "drop-kind: [ConfigMap]\n",
+			env:           map[string]string{"KUSTOMIZE_DOT_DROP_KIND": "Service"},
+			want:          []string{testConfigMap, testDeployment},
+		},
+		{
+			desc:          "explicit config only",
+			userConfig:    "drop-kind: [ConfigMap]\n",
+			projectConfig: "drop-kind: [Service]\n",
+			otherConfig:   "drop-kind: [Deployment]\n",
+			args:          []string{"--config", "other.yaml"},
+			want:          []string{testConfigMap, testService},
+		},
+		{
+			desc:          "explicit config from environment",
+			projectConfig: "drop-kind: [Service]\n",
+			otherConfig:   "drop-kind: [Deployment]\n",
+			env:           map[string]string{"KUSTOMIZE_DOT_CONFIG": "other.yaml"},
+			want:          []string{testConfigMap, testService},
+		},
+		{
+			desc:    "missing explicit config",
+			args:    []string{"--config", "other.yaml"},
+			wantErr: os.ErrNotExist,
+		},
+		{
+			desc:          "render command keys are skipped",
+			projectConfig: "engine: neato\ndrop-kind: [ConfigMap]\n",
+			want:          []string{testDeployment, testService},
+		},
+		{
+			desc:          "unknown key",
+			projectConfig: "drop-kinds: [ConfigMap]\n",
+			wantErr:       errUnknownConfigKey,
+		},
+		{
+			desc:          "invalid value",
+			projectConfig: "timeout: soon\n",
+			wantErr:       errInvalidConfigValue,
+		},
+		{
+			desc:          "invalid YAML",
+			projectConfig: "drop-kind: [ConfigMap\n",
+			wantErrText:   configFileName,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			userDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", userDir)
+			if tc.userConfig != "" {
+				writeTestFile(t, filepath.Join(userDir, "kustomize-dot", "config.yaml"), tc.userConfig)
+			}
+			dir := t.TempDir()
+			chdir(t, dir)
+			if tc.projectConfig != "" {
+				writeTestFile(t, filepath.Join(dir, configFileName), tc.projectConfig)
+			}
+			if tc.otherConfig != "" {
+				writeTestFile(t, filepath.Join(dir, "other.yaml"), tc.otherConfig)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			// The config flag belongs to the app
+			args := []string{}
+			if len(tc.args) > 0 && tc.args[0] == "--config" {
+				args = append(args, tc.args...)
+			}
+			args = append(args, "generate", "-f", resources)
+			if len(tc.args) > 0 && tc.args[0] != "--config" {
+				args = append(args, tc.args...)
+			}
+
+			out, _, err := runApp(t, args...)
+			switch {
+			case tc.wantErr != nil:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("want error %v, got %v", tc.wantErr, err)
+				}
+				return
+			case tc.wantErrText != "":
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrText) {
+					t.Fatalf("want error containing %q, got %v", tc.wantErrText, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("generating graph failed: %s", err)
+			}
+
+			if got := graphResources(out); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want resources %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConfigRender(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	// The Graphviz command is replaced by one, which writes the DOT graph
+	// as it is.
+	graphviz := filepath.Join(dir, "graphviz")
+	writeTestFile(t, graphviz, "#!/bin/sh\ncat\n")
+	if err := os.Chmod(graphviz, 0o755); err != nil {
+		t.Fatalf("chmod failed: %s", err)
+	}
+	command := parser.GraphvizCommand
+	t.Cleanup(func() {
+		parser.GraphvizCommand = command
+	})
+
+	writeTestFile(t, filepath.Join(dir, configFileName), "graphviz: "+graphviz+"\ndrop-kind: [Service]\n")
+
+	out, _, err := runApp(t, "render", "-f", resources)
+	if err != nil {
+		t.Fatalf("rendering graph failed: %s", err)
+	}
+	want := []string{testConfigMap, testDeployment}
+	if got := graphResources(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want resources %q, got %q", want, got)
+	}
+}
+
+func TestConfigSpec(t *testing.T) {
+	config := map[string]any{
+		"layout":         "TB",
+		"drop-kind":      []any{"Secret"},
+		"highlight-kind": []any{"service=yellow"},
+		"depth":          2,
+		"output":         "graph.dot",
+	}
+
+	spec, err := configSpec(config)
+	if err != nil {
+		t.Fatalf("converting config failed: %s", err)
+	}
+	want := map[string]any{
+		"Layout":         "TB",
+		"DropKinds":      []string{"Secret"},
+		"HighlightKinds": map[string]string{"service": "yellow"},
+		"FocusDepth":     2,
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Fatalf("want spec %v, got %v", want, spec)
+	}
+
+	if _, err := configSpec(map[string]any{"highlight-kind": []any{"service"}}); !errors.Is(err, errInvalidConfigValue) {
+		t.Fatalf("want error %v, got %v", errInvalidConfigValue, err)
+	}
+}
+
+func TestApplyConfigSpec(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	writeTestFile(t, filepath.Join(dir, configFileName), "layout: TB\ndrop-kind: [Secret]\nhighlight-kind: [service=yellow]\nwatch: true\n")
+
+	spec := pluginSpec{Layout: "LR", KeepKinds: []string{"Deployment"}}
+	if err := applyConfigSpec(newTestContext(t, newPluginCommand()), &spec); err != nil {
+		t.Fatalf("applying config failed: %s", err)
+	}
+
+	want := pluginSpec{
+		Layout:         "TB",
+		KeepKinds:      []string{"Deployment"},
+		DropKinds:      []string{"Secret"},
+		HighlightKinds: map[string]string{"service": "yellow"},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Fatalf("want spec %+v, got %+v", want, spec)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// errUnknownExplorerCommand is returned when the explorer was given an
+// unknown command.
+var errUnknownExplorerCommand = errors.New("unknown command")
+
+// errInvalidExplorerArgs is returned when an explorer command was given
+// invalid arguments.
+var errInvalidExplorerArgs = errors.New("invalid arguments")
+
+// explorerPrompt is the prompt of the interactive explorer.
+const explorerPrompt = "kustomize-dot> "
+
+// explorerHelp describes the commands of the interactive explorer.
+const explorerHelp = `Commands:
+  list [text]              list the resources in the view, optionally only
+                           the ones containing the given text
+  show <resource>          show the origin, transformations and dependencies
+                           of a resource, given by name or by list number
+  filter <name> <value>    apply a filter, e.g. filter drop-kind Secret
+  filters                  list the applied filters
+  focus <resource> [depth] reduce the view to the neighbourhood of a resource
+  unfocus                  remove the focus
+  reset                    remove all filters and the focus
+  export <file> [format]   export the view, e.g. as dot, svg, png, mermaid or
+                           json, which defaults to the file extension
+  help                     show this help
+  quit                     leave the explorer
+`
+
+// explorerFilters maps the names of the filters, which can be applied in the
+// explorer, to the respective parser options.
+var explorerFilters = map[string]func(string) parser.Option{
+	"drop-kind":        parser.WithDropKind,
+	"keep-kind":        parser.WithKeepKind,
+	"drop-namespace":   parser.WithDropNamespace,
+	"keep-namespace":   parser.WithKeepNamespace,
+	"drop-group":       parser.WithDropGroup,
+	"keep-group":       parser.WithKeepGroup,
+	"drop-owner-kind":  parser.WithDropOwnerKind,
+	"keep-owner-kind":  parser.WithKeepOwnerKind,
+	"drop-has-label":   parser.WithDropHasLabel,
+	"keep-has-label":   parser.WithKeepHasLabel,
+	"keep-resource":    parser.WithKeepResource,
+	"drop-name-prefix": parser.WithDropNamePrefix,
+	"keep-name-prefix": parser.WithKeepNamePrefix,
+	"drop-name-suffix": parser.WithDropNameSuffix,
+	"keep-name-suffix": parser.WithKeepNameSuffix,
+	"drop-origin-path": parser.WithDropOriginPath,
+	"keep-origin-path": parser.WithKeepOriginPath,
+}
+
+// explorerFormats maps file extensions to the formats used for exporting the
+// view.
+var explorerFormats = map[string]parser.Format{
+	".dot":     parser.FormatDot,
+	".gv":      parser.FormatDot,
+	".svg":     parser.FormatSVG,
+	".png":     parser.FormatPNG,
+	".mmd":     parser.FormatMermaid,
+	".mermaid": parser.FormatMermaid,
+	".json":    parser.FormatJSON,
+}
+
+// explorerFilter is a filter applied in the explorer.
+type explorerFilter struct {
+	name  string
+	value string
+}
+
+// explorer is an interactive, line-based explorer of resources, which reads
+// commands from a prompt and prints their results.
+type explorer struct {
+	// resources contains all resources being explored
+	resources []*resource.Resource
+
+	// filters contains the applied filters
+	filters []explorerFilter
+
+	// focus is the resource, on which the view is focused
+	focus string
+
+	// focusDepth is the depth of the neighbourhood of the focus
+	focusDepth int
+
+	// listed contains the resources of the last listing, which can be
+	// referenced by their number.
+	listed []string
+
+	// out is where the explorer writes its output
+	out io.Writer
+}
+
+// newExploreCommand returns the command for exploring resources using an
+// interactive prompt.
+func newExploreCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:   "explore",
+		Usage:  "explore the resources using an interactive prompt",
+		Action: execExploreCommand,
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:     "file",
+				Usage:    "file containing the Kubernetes resources",
+				Required: true,
+				Aliases:  []string{"f"},
+			},
+		},
+	}
+
+	return cmd
+}
+
+// execExploreCommand runs the interactive explorer of resources, which reads
+// commands line by line from stdin.
+func execExploreCommand(ctx *cli.Context) error {
+	resources, err := readResources(ctx.Path("file"))
+	if err != nil {
+		return err
+	}
+
+	e := &explorer{
+		resources:  resources,
+		filters:    make([]explorerFilter, 0),
+		focusDepth: 1,
+		out:        os.Stdout,
+	}
+
+	return e.run(os.Stdin)
+}
+
+// parser returns a new [parser.Parser] configured with the filters and the
+// focus of the view.
+func (e *explorer) parser() *parser.Parser {
+	opts := make([]parser.Option, 0, len(e.filters)+2)
+	for _, f := range e.filters {
+		opts = append(opts, explorerFilters[f.name](f.value))
+	}
+	if e.focus != "" {
+		opts = append(opts, parser.WithFocus(e.focus), parser.WithFocusDepth(e.focusDepth))
+	}
+
+	return parser.New(opts...)
+}
+
+// resolve returns the name of the resource, which is given either by name,
+// or by its number in the last listing.
+func (e *explorer) resolve(arg string) string {
+	if n, err := strconv.Atoi(arg); err == nil && n >= 1 && n <= len(e.listed) {
+		return e.listed[n-1]
+	}
+
+	return arg
+}
+
+// run reads commands from the given reader, and executes them until the quit
+// command, or the end of input.
+func (e *explorer) run(in io.Reader) error {
+	fmt.Fprintf(e.out, "Exploring %d resources, type help for the list of commands\n", len(e.resources))
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(e.out, explorerPrompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(e.out)
+			return scanner.Err()
+		}
+
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "quit" || args[0] == "exit" {
+			return nil
+		}
+		if err := e.exec(args[0], args[1:]); err != nil {
+			fmt.Fprintf(e.out, "error: %s\n", err)
+		}
+	}
+}
+
+// exec executes the explorer command with the given arguments.
+func (e *explorer) exec(command string, args []string) error {
+	switch command {
+	case "help":
+		fmt.Fprint(e.out, explorerHelp)
+		return nil
+	case "list", "ls":
+		return e.list(args)
+	case "show":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: show <resource>", errInvalidExplorerArgs)
+		}
+		return e.show(e.resolve(args[0]))
+	case "filter":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: filter <name> <value>", errInvalidExplorerArgs)
+		}
+		if _, ok := explorerFilters[args[0]]; !ok {
+			names := make([]string, 0, len(explorerFilters))
+			for name := range explorerFilters {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("%w: unknown filter %s, expected one of %s", errInvalidExplorerArgs, args[0], strings.Join(names, ", "))
+		}
+		e.filters = append(e.filters, explorerFilter{name: args[0], value: args[1]})
+		return nil
+	case "filters":
+		for _, f := range e.filters {
+			fmt.Fprintf(e.out, "%s %s\n", f.name, f.value)
+		}
+		if e.focus != "" {
+			fmt.Fprintf(e.out, "focus %s %d\n", e.focus, e.focusDepth)
+		}
+		return nil
+	case "focus":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("%w: focus <resource> [depth]", errInvalidExplorerArgs)
+		}
+		depth := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("%w: invalid depth %s", errInvalidExplorerArgs, args[1])
+			}
+			depth = n
+		}
+		e.focus = e.resolve(args[0])
+		e.focusDepth = depth
+		return nil
+	case "unfocus":
+		e.focus = ""
+		return nil
+	case "reset":
+		e.filters = e.filters[:0]
+		e.focus = ""
+		return nil
+	case "export":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("%w: export <file> [format]", errInvalidExplorerArgs)
+		}
+		return e.export(args[0], args[1:]...)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownExplorerCommand, command)
+	}
+}
+
+// list lists the resources in the view, which contain the given text.
+func (e *explorer) list(args []string) error {
+	g, err := e.parser().ParseGraph(e.resources)
+	if err != nil {
+		return err
+	}
+
+	text := strings.ToLower(strings.Join(args, " "))
+	names := g.GetVertexValues()
+	sort.Strings(names)
+	e.listed = e.listed[:0]
+	for _, name := range names {
+		if !strings.Contains(strings.ToLower(name), text) {
+			continue
+		}
+		// Only resources are listed, and not their origins
+		if _, err := parser.New().ProvenanceOf(e.resources, name); err != nil {
+			continue
+		}
+		e.listed = append(e.listed, name)
+		fmt.Fprintf(e.out, "%4d  %s\n", len(e.listed), name)
+	}
+	if len(e.listed) == 0 {
+		fmt.Fprintln(e.out, "no resources in view")
+	}
+
+	return nil
+}
+
+// show shows the provenance and the dependencies of the given resource.
+func (e *explorer) show(name string) error {
+	pv, err := parser.New().ProvenanceOf(e.resources, name)
+	if err != nil {
+		return err
+	}
+	if err := writeProvenanceText(e.out, pv); err != nil {
+		return err
+	}
+
+	g, err := e.parser().ParseGraph(e.resources)
+	if err != nil {
+		return err
+	}
+	if !g.VertexExists(pv.Resource) {
+		fmt.Fprintln(e.out, "  not in view")
+		return nil
+	}
+
+	outgoing := make([]string, 0)
+	incoming := make([]string, 0)
+	for _, edge := range g.GetEdges() {
+		switch pv.Resource {
+		case edge.From:
+			outgoing = append(outgoing, edge.To)
+		case edge.To:
+			incoming = append(incoming, edge.From)
+		}
+	}
+	sort.Strings(outgoing)
+	sort.Strings(incoming)
+	for _, v := range outgoing {
+		fmt.Fprintf(e.out, "  -> %s\n", v)
+	}
+	for _, v := range incoming {
+		fmt.Fprintf(e.out, "  <- %s\n", v)
+	}
+
+	return nil
+}
+
+// export writes the view to the given file in the given format, which
+// defaults to the one matching the file extension.
+func (e *explorer) export(path string, format ...string) error {
+	f, ok := explorerFormats[strings.ToLower(filepath.Ext(path))]
+	if len(format) > 0 {
+		f, ok = parser.Format(format[0]), true
+	}
+	if !ok {
+		return fmt.Errorf("%w: unknown format of %s", errInvalidExplorerArgs, path)
+	}
+
+	render, err := parser.NewRenderer(f)
+	if err != nil {
+		return err
+	}
+	g, err := e.parser().ParseGraph(e.resources)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(path, func(w io.Writer) error { return render(g, w) }); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "exported view to %s\n", path)
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+)
+
+func newTestExplorer(t *testing.T) (*explorer, *bytes.Buffer) {
+	t.Helper()
+
+	resources, err := parser.ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	var out bytes.Buffer
+	e := &explorer{
+		resources:  resources,
+		filters:    make([]explorerFilter, 0),
+		focusDepth: 1,
+		out:        &out,
+	}
+
+	return e, &out
+}
+
+func TestExplorerRun(t *testing.T) {
+	e, out := newTestExplorer(t)
+
+	input := strings.NewReader("list\n\nbogus\nfilter drop-kind\nfocus\nquit\nlist deployment\n")
+	if err := e.run(input); err != nil {
+		t.Fatalf("running explorer failed: %s", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"Exploring 3 resources",
+		"   1  default/configmap/the-map\n",
+		"   2  default/deployment/the-deployment\n",
+		"   3  default/service/the-service\n",
+		"error: unknown command: bogus\n",
+		"error: invalid arguments: filter <name> <value>\n",
+		"error: invalid arguments: focus <resource> [depth]\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want output containing %q, got %q", want, got)
+		}
+	}
+
+	// The commands after quit must not be executed
+	if n := strings.Count(got, explorerPrompt); n != 6 {
+		t.Fatalf("want 6 prompts, got %d", n)
+	}
+}
+
+func TestExplorerExec(t *testing.T) {
+	e, out := newTestExplorer(t)
+
+	if err := e.exec("filter", []string{"drop-kind", "ConfigMap"}); err != nil {
+		t.Fatalf("applying filter failed: %s", err)
+	}
+	if err := e.exec("filter", []string{"drop-color", "red"}); !errors.Is(err, errInvalidExplorerArgs) {
+		t.Fatalf("want error %v for unknown filter, got %v", errInvalidExplorerArgs, err)
+	}
+	if err := e.exec("focus", []string{"default/service/the-service", "-1"}); !errors.Is(err, errInvalidExplorerArgs) {
+		t.Fatalf("want error %v for negative depth, got %v", errInvalidExplorerArgs, err)
+	}
+
+	out.Reset()
+	if err := e.exec("list", nil); err != nil {
+		t.Fatalf("listing resources failed: %s", err)
+	}
+	want := []string{"default/deployment/the-deployment", "default/service/the-service"}
+	if strings.Join(e.listed, ",") != strings.Join(want, ",") {
+		t.Fatalf("want listed resources %v, got %v", want, e.listed)
+	}
+
+	out.Reset()
+	if err := e.exec("filters", nil); err != nil {
+		t.Fatalf("listing filters failed: %s", err)
+	}
+	if got := out.String(); got != "drop-kind ConfigMap\n" {
+		t.Fatalf("want filters %q, got %q", "drop-kind ConfigMap\n", got)
+	}
+
+	if err := e.exec("focus", []string{"2", "3"}); err != nil {
+		t.Fatalf("focusing view failed: %s", err)
+	}
+	if e.focus != "default/service/the-service" || e.focusDepth != 3 {
+		t.Fatalf("want focus on default/service/the-service with depth 3, got %s with depth %d", e.focus, e.focusDepth)
+	}
+
+	if err := e.exec("reset", nil); err != nil {
+		t.Fatalf("resetting view failed: %s", err)
+	}
+	if len(e.filters) != 0 || e.focus != "" {
+		t.Fatalf("want no filters and focus after reset, got %v and %q", e.filters, e.focus)
+	}
+}
+
+func TestExplorerResolve(t *testing.T) {
+	e, _ := newTestExplorer(t)
+	e.listed = []string{"default/configmap/the-map", "default/service/the-service"}
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{arg: "1", want: "default/configmap/the-map"},
+		{arg: "2", want: "default/service/the-service"},
+		{arg: "0", want: "0"},
+		{arg: "3", want: "3"},
+		{arg: "-1", want: "-1"},
+		{arg: "default/deployment/the-deployment", want: "default/deployment/the-deployment"},
+	}
+
+	for _, test := range tests {
+		if got := e.resolve(test.arg); got != test.want {
+			t.Fatalf("want %s resolved to %s, got %s", test.arg, test.want, got)
+		}
+	}
+}
+
+func TestExplorerExport(t *testing.T) {
+	e, _ := newTestExplorer(t)
+	dir := t.TempDir()
+
+	tests := []struct {
+		file    string
+		format  []string
+		prefix  string
+		wantErr error
+	}{
+		{file: "graph.dot", prefix: "strict digraph {"},
+		{file: "graph.GV", prefix: "strict digraph {"},
+		{file: "graph.json", prefix: "{"},
+		{file: "graph.txt", format: []string{"dot"}, prefix: "strict digraph {"},
+		{file: "graph.txt", wantErr: errInvalidExplorerArgs},
+		{file: "graph.dot", format: []string{"bogus"}, wantErr: parser.ErrUnsupportedFormat},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(dir, test.file)
+		os.Remove(path)
+
+		err := e.export(path, test.format...)
+		if test.wantErr != nil {
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("want error %v exporting %s, got %v", test.wantErr, test.file, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("exporting %s failed: %s", test.file, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s failed: %s", test.file, err)
+		}
+		if !strings.HasPrefix(string(data), test.prefix) {
+			t.Fatalf("want %s starting with %q, got %q", test.file, test.prefix, data)
+		}
+	}
+}
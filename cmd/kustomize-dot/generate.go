@@ -26,11 +26,17 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
 
 	"github.com/dnaeon/kustomize-dot/pkg/parser"
 	"github.com/urfave/cli/v2"
-	"gopkg.in/dnaeon/go-graph.v1"
 	"sigs.k8s.io/kustomize/api/resource"
 )
 
@@ -55,126 +61,1304 @@ func newGenerateCommand() *cli.Command {
 				Required: true,
 				Aliases:  []string{"f"},
 			},
+			&cli.StringSliceFlag{
+				Name:    "output",
+				Usage:   "file to write the graph to, or - for stdout, may be repeated to write the graph in multiple formats",
+				Value:   cli.NewStringSlice("-"),
+				Aliases: []string{"o"},
+				EnvVars: []string{"KUSTOMIZE_DOT_OUTPUT"},
+			},
+			&cli.StringFlag{
+				Name:    "split-by",
+				Usage:   "write a separate graph per namespace or per origin repo into the output directory, either namespace or repo",
+				EnvVars: []string{"KUSTOMIZE_DOT_SPLIT_BY"},
+			},
+			&cli.PathFlag{
+				Name:    "output-dir",
+				Usage:   "directory to write the separate graphs to, when splitting the graph",
+				Value:   ".",
+				EnvVars: []string{"KUSTOMIZE_DOT_OUTPUT_DIR"},
+			},
+			&cli.BoolFlag{
+				Name:    "watch",
+				Usage:   "watch the resources file and regenerate the graph whenever it changes",
+				Value:   false,
+				Aliases: []string{"w"},
+				EnvVars: []string{"KUSTOMIZE_DOT_WATCH"},
+			},
+			&cli.DurationFlag{
+				Name:    "watch-interval",
+				Usage:   "interval at which the resources file is polled for changes in watch mode",
+				Value:   time.Second,
+				EnvVars: []string{"KUSTOMIZE_DOT_WATCH_INTERVAL"},
+			},
+			&cli.BoolFlag{
+				Name:    "fail-on-empty",
+				Usage:   "exit with an error instead of writing the graph, when it has no vertices",
+				Value:   false,
+				EnvVars: []string{"KUSTOMIZE_DOT_FAIL_ON_EMPTY"},
+			},
+			&cli.BoolFlag{
+				Name:    "debug",
+				Usage:   "log the parsed resources, dropped resources and the duration of each stage to stderr",
+				Value:   false,
+				Aliases: []string{"v"},
+				EnvVars: []string{"KUSTOMIZE_DOT_DEBUG"},
+			},
+			&cli.BoolFlag{
+				Name:    "explain",
+				Usage:   "write whether each resource is kept, and which of the filters decided so, instead of the graph",
+				Value:   false,
+				EnvVars: []string{"KUSTOMIZE_DOT_EXPLAIN"},
+			},
+			&cli.DurationFlag{
+				Name:    "timeout",
+				Usage:   "cancel generating the graph, when it takes longer than the given duration, e.g. 30s",
+				EnvVars: []string{"KUSTOMIZE_DOT_TIMEOUT"},
+			},
+			&cli.BoolFlag{
+				Name:    "open",
+				Usage:   "open the output file with the default viewer, once it has been written",
+				Value:   false,
+				EnvVars: []string{"KUSTOMIZE_DOT_OPEN"},
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Usage:   "output format of the graph, e.g. dot, svg, png, mermaid or json",
+				Value:   parser.FormatDot.String(),
+				EnvVars: []string{"KUSTOMIZE_DOT_FORMAT"},
+			},
+			&cli.StringFlag{
+				Name:    "profile",
+				Usage:   "preset of options, e.g. minimal, full, topology, or a profile from the config file",
+				EnvVars: []string{"KUSTOMIZE_DOT_PROFILE"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "preset",
+				Usage:   "named filter preset from the config file, may be repeated to combine presets",
+				EnvVars: []string{"KUSTOMIZE_DOT_PRESET"},
+			},
+			&cli.PathFlag{
+				Name:    "previous-file",
+				Usage:   "file containing the Kubernetes resources of a previous build, against which added and modified resources are emphasized",
+				EnvVars: []string{"KUSTOMIZE_DOT_PREVIOUS_FILE"},
+			},
 			&cli.StringSliceFlag{
 				Name:    "highlight-kind",
 				Usage:   "highlight resources of a given kind with specified color",
 				Aliases: []string{"kind-color", "hk"},
-				EnvVars: []string{"HIGHLIGHT_KIND", "KIND_COLOR"},
+				EnvVars: []string{"KUSTOMIZE_DOT_HIGHLIGHT_KIND", "HIGHLIGHT_KIND", "KIND_COLOR"},
 			},
 			&cli.StringSliceFlag{
 				Name:    "highlight-namespace",
 				Usage:   "highlight resources from a given namespace with specified color",
 				Aliases: []string{"namespace-color", "hn"},
-				EnvVars: []string{"HIGHLIGHT_NAMESPACE", "NAMESPACE_COLOR"},
+				EnvVars: []string{"KUSTOMIZE_DOT_HIGHLIGHT_NAMESPACE", "HIGHLIGHT_NAMESPACE", "NAMESPACE_COLOR"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "highlight-name-regex",
+				Usage:   "highlight resources, whose names match the regular expression, with specified color, e.g. '.*-prod.*=red'",
+				EnvVars: []string{"KUSTOMIZE_DOT_HIGHLIGHT_NAME_REGEX"},
+			},
+			&cli.BoolFlag{
+				Name:    "gradient-highlights",
+				Usage:   "fill resources highlighted by both namespace and kind with a gradient of the two colors",
+				EnvVars: []string{"KUSTOMIZE_DOT_GRADIENT_HIGHLIGHTS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "edge-style",
+				Usage:   "style of the edges of the given type, e.g. 'patch=bold', where the type is origin, patch, relationship or image",
+				EnvVars: []string{"KUSTOMIZE_DOT_EDGE_STYLE"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "node-attr",
+				Usage:   "set Dot attributes on all resources of the given kind, e.g. 'Deployment:style=filled,shape=component'",
+				EnvVars: []string{"KUSTOMIZE_DOT_NODE_ATTR"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "graph-attr",
+				Usage:   "set Dot attribute on the graph, e.g. 'splines=ortho'",
+				EnvVars: []string{"KUSTOMIZE_DOT_GRAPH_ATTR"},
 			},
 			&cli.StringSliceFlag{
 				Name:    "drop-kind",
-				Usage:   "drop resources of the given kind",
+				Usage:   "drop resources of the given kind, e.g. Deployment or apps/v1/Deployment",
 				Aliases: []string{"dk"},
-				EnvVars: []string{"DROP_KIND"},
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_KIND", "DROP_KIND"},
 			},
 			&cli.StringSliceFlag{
 				Name:    "drop-namespace",
 				Usage:   "drop all resources from the given namespace",
 				Aliases: []string{"dn"},
-				EnvVars: []string{"DROP_NAMESPACE"},
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_NAMESPACE", "DROP_NAMESPACE"},
 			},
 			&cli.StringSliceFlag{
 				Name:    "keep-kind",
-				Usage:   "keep resources of the given kind only",
+				Usage:   "keep resources of the given kind only, e.g. Deployment or apps/v1/Deployment",
 				Aliases: []string{"kk"},
-				EnvVars: []string{"KEEP_KIND"},
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_KIND", "KEEP_KIND"},
 			},
 			&cli.StringSliceFlag{
 				Name:    "keep-namespace",
 				Usage:   "keep resources from the given namespace only",
 				Aliases: []string{"kn"},
-				EnvVars: []string{"KEEP_NAMESPACE"},
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_NAMESPACE", "KEEP_NAMESPACE"},
+			},
+			&cli.BoolFlag{
+				Name:    "case-sensitive",
+				Usage:   "match the kind, namespace and group filters case-sensitively",
+				EnvVars: []string{"KUSTOMIZE_DOT_CASE_SENSITIVE"},
+			},
+			&cli.BoolFlag{
+				Name:    "exact-match",
+				Usage:   "match the kind, namespace and group filters literally, without expanding wildcards",
+				EnvVars: []string{"KUSTOMIZE_DOT_EXACT_MATCH"},
+			},
+			&cli.BoolFlag{
+				Name:    "drop-cluster-scoped",
+				Usage:   "drop all cluster-scoped resources",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_CLUSTER_SCOPED"},
+			},
+			&cli.BoolFlag{
+				Name:    "keep-cluster-scoped-only",
+				Usage:   "keep cluster-scoped resources only",
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_CLUSTER_SCOPED_ONLY"},
+			},
+			&cli.BoolFlag{
+				Name:    "local-only",
+				Usage:   "keep resources originating from the local kustomization only",
+				EnvVars: []string{"KUSTOMIZE_DOT_LOCAL_ONLY"},
+			},
+			&cli.BoolFlag{
+				Name:    "remote-only",
+				Usage:   "keep resources originating from remote repositories only",
+				EnvVars: []string{"KUSTOMIZE_DOT_REMOTE_ONLY"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-group",
+				Usage:   "drop resources from the given API group, e.g. rbac.authorization.k8s.io or apps/v1",
+				Aliases: []string{"dg"},
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_GROUP"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-group",
+				Usage:   "keep resources from the given API group only, e.g. monitoring.coreos.com or apps/v1",
+				Aliases: []string{"kg"},
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_GROUP"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-origin-path",
+				Usage:   "drop resources, whose origin path matches the given glob pattern, e.g. 'base/**'",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_ORIGIN_PATH"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-origin-path",
+				Usage:   "keep resources, whose origin path matches the given glob pattern only, e.g. 'overlays/prod/**'",
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_ORIGIN_PATH"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-owner-kind",
+				Usage:   "drop resources owned by a resource of the given kind, e.g. Deployment or apps/v1/Deployment",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_OWNER_KIND"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-owner-kind",
+				Usage:   "keep resources owned by a resource of the given kind only, e.g. Deployment or apps/v1/Deployment",
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_OWNER_KIND"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-has-label",
+				Usage:   "drop resources, which have the given label",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_HAS_LABEL"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-has-label",
+				Usage:   "keep resources, which have the given label only",
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_HAS_LABEL"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-resource",
+				Usage:   "keep the given resource only, e.g. default/configmap/the-map",
+				Aliases: []string{"kres"},
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_RESOURCE"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-name-regex",
+				Usage:   "drop resources, whose names match the given regular expression",
+				Aliases: []string{"dr"},
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_NAME_REGEX"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-name-regex",
+				Usage:   "keep resources, whose names match the given regular expression only",
+				Aliases: []string{"kr"},
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_NAME_REGEX"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-name-prefix",
+				Usage:   "drop resources, whose names start with the given prefix",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_NAME_PREFIX"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "drop-name-suffix",
+				Usage:   "drop resources, whose names end with the given suffix",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_NAME_SUFFIX"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-name-prefix",
+				Usage:   "keep resources, whose names start with the given prefix only",
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_NAME_PREFIX"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-name-suffix",
+				Usage:   "keep resources, whose names end with the given suffix only",
+				EnvVars: []string{"KUSTOMIZE_DOT_KEEP_NAME_SUFFIX"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "filter-expr",
+				Usage:   "keep resources, for which the given CEL expression evaluates to true only",
+				EnvVars: []string{"KUSTOMIZE_DOT_FILTER_EXPR"},
+			},
+			&cli.PathFlag{
+				Name:    "filter-file",
+				Usage:   "file containing ordered keep, drop and highlight rules",
+				EnvVars: []string{"KUSTOMIZE_DOT_FILTER_FILE"},
+			},
+			&cli.PathFlag{
+				Name:    "style-file",
+				Usage:   "file containing ordered style rules",
+				EnvVars: []string{"KUSTOMIZE_DOT_STYLE_FILE"},
+			},
+			&cli.StringFlag{
+				Name:    "filter-precedence",
+				Usage:   "evaluate the filtering options or the filter rules first, either options or rules",
+				Value:   parser.FilterPrecedenceOptions.String(),
+				EnvVars: []string{"KUSTOMIZE_DOT_FILTER_PRECEDENCE"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "query",
+				Usage:   "select resources using the given jq query, e.g. '.[] | select(.kind == \"Service\")'",
+				Aliases: []string{"q"},
+				EnvVars: []string{"KUSTOMIZE_DOT_QUERY"},
+			},
+			&cli.BoolFlag{
+				Name:    "drop-generated",
+				Usage:   "drop resources produced by the ConfigMap and Secret generators",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_GENERATED"},
+			},
+			&cli.BoolFlag{
+				Name:    "prune-unreachable",
+				Usage:   "drop vertices, which are not reachable from any resource after filtering",
+				EnvVars: []string{"KUSTOMIZE_DOT_PRUNE_UNREACHABLE"},
+			},
+			&cli.BoolFlag{
+				Name:    "drop-orphans",
+				Usage:   "drop vertices, which are not connected to any other vertex",
+				EnvVars: []string{"KUSTOMIZE_DOT_DROP_ORPHANS"},
+			},
+			&cli.BoolFlag{
+				Name:    "mark-orphans",
+				Usage:   "mark resources, which select nothing from the build, e.g. PodDisruptionBudgets matching no workload",
+				EnvVars: []string{"KUSTOMIZE_DOT_MARK_ORPHANS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "orphan-attr",
+				Usage:   "set Dot attribute on the marked orphan resources, e.g. 'color=orange'",
+				EnvVars: []string{"KUSTOMIZE_DOT_ORPHAN_ATTR"},
+			},
+			&cli.IntFlag{
+				Name:    "max-vertices",
+				Usage:   "max number of vertices of the graph, before the on-exceed action is taken",
+				EnvVars: []string{"KUSTOMIZE_DOT_MAX_VERTICES"},
+			},
+			&cli.IntFlag{
+				Name:    "max-edges",
+				Usage:   "max number of edges of the graph, before the on-exceed action is taken",
+				EnvVars: []string{"KUSTOMIZE_DOT_MAX_EDGES"},
+			},
+			&cli.StringFlag{
+				Name:    "on-exceed",
+				Usage:   "action taken, when the graph exceeds max-vertices or max-edges, either fail or collapse",
+				Value:   parser.ThresholdActionFail.String(),
+				EnvVars: []string{"KUSTOMIZE_DOT_ON_EXCEED"},
+			},
+			&cli.BoolFlag{
+				Name:    "collapse",
+				Usage:   "collapse the resources into one vertex per namespace and kind",
+				EnvVars: []string{"KUSTOMIZE_DOT_COLLAPSE"},
+			},
+			&cli.BoolFlag{
+				Name:    "origins-only",
+				Usage:   "graph only the origins of resources",
+				EnvVars: []string{"KUSTOMIZE_DOT_ORIGINS_ONLY"},
+			},
+			&cli.BoolFlag{
+				Name:    "no-origins",
+				Usage:   "graph only resources and the relationships between them",
+				EnvVars: []string{"KUSTOMIZE_DOT_NO_ORIGINS"},
+			},
+			&cli.BoolFlag{
+				Name:    "detail",
+				Usage:   "include container images, replica count and service type in the vertex labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_DETAIL"},
+			},
+			&cli.StringFlag{
+				Name:    "node-label-template",
+				Usage:   "Go template used to render the labels of resource vertices, e.g. '{{ .Kind }}\\n{{ .Name }}'",
+				EnvVars: []string{"KUSTOMIZE_DOT_NODE_LABEL_TEMPLATE"},
+			},
+			&cli.StringFlag{
+				Name:    "legend",
+				Usage:   "add a legend describing the shapes, styles and colors used in the graph, either bottom, right or off",
+				Value:   legendOff,
+				EnvVars: []string{"KUSTOMIZE_DOT_LEGEND"},
+			},
+			&cli.BoolFlag{
+				Name:    "tooltips",
+				Usage:   "show the metadata of resources as tooltips of the vertices",
+				EnvVars: []string{"KUSTOMIZE_DOT_TOOLTIPS"},
+			},
+			&cli.StringFlag{
+				Name:    "source-links",
+				Usage:   "link remote origins to their files in the forge, either github, gitlab or a Go template",
+				EnvVars: []string{"KUSTOMIZE_DOT_SOURCE_LINKS"},
+			},
+			&cli.BoolFlag{
+				Name:    "icons",
+				Usage:   "embed the bundled Kubernetes icons in the resource vertices",
+				EnvVars: []string{"KUSTOMIZE_DOT_ICONS"},
+			},
+			&cli.PathFlag{
+				Name:    "icons-dir",
+				Usage:   "directory with Kubernetes icons, which override the bundled icons, implies --icons",
+				EnvVars: []string{"KUSTOMIZE_DOT_ICONS_DIR"},
+			},
+			&cli.BoolFlag{
+				Name:    "show-images",
+				Usage:   "add the container images of workloads as vertices",
+				EnvVars: []string{"KUSTOMIZE_DOT_SHOW_IMAGES"},
+			},
+			&cli.BoolFlag{
+				Name:    "show-patches",
+				Usage:   "connect resources to the kustomizations, whose patches modified them",
+				EnvVars: []string{"KUSTOMIZE_DOT_SHOW_PATCHES"},
+			},
+			&cli.BoolFlag{
+				Name:    "group-remotes",
+				Usage:   "group origins from the same remote repository and ref into a cluster",
+				EnvVars: []string{"KUSTOMIZE_DOT_GROUP_REMOTES"},
+			},
+			&cli.BoolFlag{
+				Name:    "separate-ranks",
+				Usage:   "place origins and resources on separate ranks",
+				EnvVars: []string{"KUSTOMIZE_DOT_SEPARATE_RANKS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "same-rank-kind",
+				Usage:   "place all resources of the given kind on the same rank",
+				EnvVars: []string{"KUSTOMIZE_DOT_SAME_RANK_KIND"},
+			},
+			&cli.StringFlag{
+				Name:    "theme",
+				Usage:   "visual theme of the graph, e.g. light, dark, pastel or colorblind-safe",
+				Value:   parser.ThemeDefault.String(),
+				EnvVars: []string{"KUSTOMIZE_DOT_THEME"},
+			},
+			&cli.StringFlag{
+				Name:    "background",
+				Usage:   "background color of the graph, which overrides the background of the theme",
+				EnvVars: []string{"KUSTOMIZE_DOT_BACKGROUND"},
+			},
+			&cli.BoolFlag{
+				Name:    "transparent",
+				Usage:   "render the graph on a transparent background",
+				EnvVars: []string{"KUSTOMIZE_DOT_TRANSPARENT"},
+			},
+			&cli.BoolFlag{
+				Name:    "auto-contrast",
+				Usage:   "switch the font color of highlighted resources to white or black, depending on how dark the fill color is",
+				EnvVars: []string{"KUSTOMIZE_DOT_AUTO_CONTRAST"},
+			},
+			&cli.StringFlag{
+				Name:    "auto-color",
+				Usage:   "automatically assign a distinct color to each namespace or kind, either none, namespaces or kinds",
+				Value:   parser.AutoColorNone.String(),
+				EnvVars: []string{"KUSTOMIZE_DOT_AUTO_COLOR"},
+			},
+			&cli.StringFlag{
+				Name:    "font-name",
+				Usage:   "font name of the graph, vertex and edge labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_FONT_NAME"},
+			},
+			&cli.Float64Flag{
+				Name:    "font-size",
+				Usage:   "font size in points of the graph, vertex and edge labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_FONT_SIZE"},
+			},
+			&cli.StringFlag{
+				Name:    "graph-font-name",
+				Usage:   "font name of the graph and cluster labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_GRAPH_FONT_NAME"},
+			},
+			&cli.Float64Flag{
+				Name:    "graph-font-size",
+				Usage:   "font size in points of the graph and cluster labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_GRAPH_FONT_SIZE"},
+			},
+			&cli.StringFlag{
+				Name:    "node-font-name",
+				Usage:   "font name of the vertex labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_NODE_FONT_NAME"},
+			},
+			&cli.Float64Flag{
+				Name:    "node-font-size",
+				Usage:   "font size in points of the vertex labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_NODE_FONT_SIZE"},
+			},
+			&cli.StringFlag{
+				Name:    "edge-font-name",
+				Usage:   "font name of the edge labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_EDGE_FONT_NAME"},
+			},
+			&cli.Float64Flag{
+				Name:    "edge-font-size",
+				Usage:   "font size in points of the edge labels",
+				EnvVars: []string{"KUSTOMIZE_DOT_EDGE_FONT_SIZE"},
+			},
+			&cli.Float64Flag{
+				Name:    "dpi",
+				Usage:   "resolution of rendered images in dots per inch",
+				EnvVars: []string{"KUSTOMIZE_DOT_DPI"},
+			},
+			&cli.Float64Flag{
+				Name:    "max-width",
+				Usage:   "max width of rendered images in inches, larger graphs are scaled down",
+				EnvVars: []string{"KUSTOMIZE_DOT_MAX_WIDTH"},
+			},
+			&cli.Float64Flag{
+				Name:    "max-height",
+				Usage:   "max height of rendered images in inches, larger graphs are scaled down",
+				EnvVars: []string{"KUSTOMIZE_DOT_MAX_HEIGHT"},
+			},
+			&cli.StringFlag{
+				Name:    "ratio",
+				Usage:   "aspect ratio of rendered images, either fill, compress, expand, auto or a height to width ratio, e.g. 0.5",
+				EnvVars: []string{"KUSTOMIZE_DOT_RATIO"},
+			},
+			&cli.StringFlag{
+				Name:    "resource-shape",
+				Usage:   "shape of resource vertices",
+				Value:   parser.DefaultResourceShape,
+				EnvVars: []string{"KUSTOMIZE_DOT_RESOURCE_SHAPE"},
+			},
+			&cli.StringFlag{
+				Name:    "origin-shape",
+				Usage:   "shape of origin vertices",
+				Value:   parser.DefaultOriginShape,
+				EnvVars: []string{"KUSTOMIZE_DOT_ORIGIN_SHAPE"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "focus",
+				Usage:   "keep only the neighbourhood of the given vertex, e.g. default/deployment/my-app",
+				EnvVars: []string{"KUSTOMIZE_DOT_FOCUS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "root",
+				Usage:   "vertex from which the max depth is measured, defaults to the vertices without outgoing edges",
+				EnvVars: []string{"KUSTOMIZE_DOT_ROOT"},
+			},
+			&cli.IntFlag{
+				Name:    "max-depth",
+				Usage:   "max number of hops from the root vertices to keep, negative values mean unlimited",
+				Value:   -1,
+				EnvVars: []string{"KUSTOMIZE_DOT_MAX_DEPTH"},
+			},
+			&cli.IntFlag{
+				Name:    "depth",
+				Usage:   "max number of hops from the focus vertices to keep",
+				Value:   1,
+				EnvVars: []string{"KUSTOMIZE_DOT_FOCUS_DEPTH"},
+			},
+			&cli.BoolFlag{
+				Name:    "longest-path",
+				Usage:   "highlight the longest path of the graph",
+				EnvVars: []string{"KUSTOMIZE_DOT_LONGEST_PATH"},
+			},
+			&cli.StringFlag{
+				Name:    "longest-path-to",
+				Usage:   "highlight the longest path of the graph, which ends at the given vertex",
+				EnvVars: []string{"KUSTOMIZE_DOT_LONGEST_PATH_TO"},
 			},
 		},
 	}
 
+	cmd.BashComplete = completeFilterValues(cmd)
+
 	return cmd
 }
 
 // execGenerateCommand runs the command for generating dot representation of the
 // Kubernetes resources.
 func execGenerateCommand(ctx *cli.Context) error {
-	layout, err := getLayoutDirection(ctx)
+	// config file
+	if err := applyConfig(ctx); err != nil {
+		return err
+	}
+
+	paths, err := getOutputPaths(ctx)
 	if err != nil {
 		return err
 	}
 
+	// format option. Unless specified, the format of each output is derived
+	// from its file extension, when writing multiple outputs.
+	outputs := make([]graphOutput, 0, len(paths))
+	for _, path := range paths {
+		format := parser.Format(ctx.String("format"))
+		if len(paths) > 1 && !ctx.IsSet("format") {
+			if f, ok := extensionFormat(path); ok {
+				format = f
+			}
+		}
+		if _, err := parser.NewRenderer(format); err != nil {
+			return err
+		}
+		output := graphOutput{
+			path: path,
+			ext:  formatExtension(format),
+			newRenderer: func(c context.Context) (parser.Renderer, error) {
+				return parser.NewRendererContext(c, format)
+			},
+		}
+		outputs = append(outputs, output)
+	}
+
+	return generateGraph(ctx, outputs)
+}
+
+// rendererFunc returns the [parser.Renderer] of the graph, which stops any
+// external tool it runs, when the given context is cancelled.
+type rendererFunc func(ctx context.Context) (parser.Renderer, error)
+
+// graphOutput is an output the graph is written to, along with the renderer of
+// its format.
+type graphOutput struct {
+	// path is the file to write the graph to, or - for stdout
+	path string
+
+	// ext is the extension of the files written, when the graph is split
+	ext string
+
+	// newRenderer returns the renderer of the graph
+	newRenderer rendererFunc
+}
+
+// generateProgress tracks how far generating the graphs got, so that it can
+// be reported, when generating the graphs is cancelled.
+type generateProgress struct {
+	// stage describes what was being done, e.g. rendering the graph
+	stage string
+
+	// resources is the number of resources read
+	resources int
+
+	// graphs is the number of graphs to write
+	graphs int
+
+	// written is the number of graphs written
+	written int
+}
+
+// cancelled returns the error describing the progress made, before
+// generating the graphs was cancelled after the given duration.
+func (gp *generateProgress) cancelled(elapsed time.Duration, cause error) error {
+	return fmt.Errorf(
+		"%w while %s after %s, %d resources read, %d of %d graphs written: %w",
+		errCancelled, gp.stage, elapsed.Round(time.Millisecond), gp.resources, gp.written, gp.graphs, cause,
+	)
+}
+
+// generateGraph generates the graph of the Kubernetes resources using the
+// options from the CLI context, and writes it to each of the given outputs.
+// The graph is parsed only once, regardless of the number of outputs. When the
+// graph is split, the separate graphs are written to files with the extension
+// of each output. Generating the graph is cancelled on timeout, or when the
+// app is interrupted.
+func generateGraph(ctx *cli.Context, outputs []graphOutput) error {
+	opts, err := graphOptions(contextOptions{ctx})
+	if err != nil {
+		return err
+	}
+
+	// debug option
+	if ctx.Bool("debug") {
+		opts = append(opts, parser.WithLogger(newDebugLogger()))
+	}
+
+	// split-by option
+	var splitBy parser.SplitBy
+	if value := ctx.String("split-by"); value != "" {
+		splitBy, err = getSplitBy(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read the resources and generate the graph
+	file := ctx.Path("file")
+	outputDir := ctx.Path("output-dir")
+	openOutput := ctx.Bool("open")
+	failOnEmpty := ctx.Bool("fail-on-empty")
+	explain := ctx.Bool("explain")
+	if openOutput && splitBy != "" {
+		return errOpenSplit
+	}
+	for _, output := range outputs {
+		if openOutput && (output.path == "" || output.path == "-") {
+			return errOpenStdout
+		}
+	}
+	if explain && len(outputs) > 1 {
+		return errExplainOutputs
+	}
+
+	// timeout option
+	timeout := ctx.Duration("timeout")
+	if timeout < 0 {
+		return fmt.Errorf("%w: %s", errInvalidTimeout, timeout)
+	}
+
+	// The interrupt cancels the graph being generated, and stops watching
+	// the resources in watch mode.
+	sigCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt)
+	defer stop()
+
+	// write parses the resources once, and writes the graph to each of the
+	// given paths using the renderer of the output at the same index.
+	write := func(runCtx context.Context, progress *generateProgress, paths []string, resources []*resource.Resource) error {
+		progress.stage = "parsing the resources"
+		p := parser.New(opts...)
+		g, err := p.ParseGraphContext(runCtx, resources)
+		if err != nil {
+			return err
+		}
+
+		// fail-on-empty option
+		if failOnEmpty && g.IsEmpty() {
+			return errEmptyGraph
+		}
+
+		for i, output := range outputs {
+			progress.stage = "rendering the graph"
+			render, err := output.newRenderer(runCtx)
+			if err != nil {
+				return err
+			}
+			err = writeOutput(paths[i], func(w io.Writer) error {
+				return render(g, w)
+			})
+			if err != nil {
+				return err
+			}
+			progress.written++
+		}
+
+		return nil
+	}
+
+	run := func(runCtx context.Context, progress *generateProgress) error {
+		progress.stage = "reading the resources"
+		resources, err := readResourcesContext(runCtx, file)
+		if err != nil {
+			return err
+		}
+		progress.resources = len(resources)
+
+		// explain option
+		if explain {
+			return writeExplanations(outputs[0].path, parser.New(opts...), resources)
+		}
+
+		if splitBy != "" {
+			groups, err := parser.Split(resources, splitBy)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(outputDir, 0o755); err != nil {
+				return err
+			}
+			progress.graphs = len(groups) * len(outputs)
+			for _, group := range sortedKeys(groups) {
+				paths := make([]string, 0, len(outputs))
+				for _, output := range outputs {
+					paths = append(paths, filepath.Join(outputDir, splitFileName(group)+output.ext))
+				}
+				if err := write(runCtx, progress, paths, groups[group]); err != nil {
+					return fmt.Errorf("%s: %w", group, err)
+				}
+			}
+			return nil
+		}
+
+		paths := make([]string, 0, len(outputs))
+		for _, output := range outputs {
+			paths = append(paths, output.path)
+		}
+		progress.graphs = len(paths)
+		if err := write(runCtx, progress, paths, resources); err != nil {
+			return err
+		}
+
+		// open option. In watch mode the viewer is expected to reload
+		// the files, so they are opened only once.
+		if openOutput {
+			openOutput = false
+			for _, path := range paths {
+				if err := openFile(path); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	generate := func() error {
+		runCtx, cancel := sigCtx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			runCtx, cancel = context.WithTimeoutCause(sigCtx, timeout, fmt.Errorf("%w of %s", errTimeout, timeout))
+		}
+		defer cancel()
+
+		started := time.Now()
+		progress := &generateProgress{}
+		err := run(runCtx, progress)
+		if err != nil && runCtx.Err() != nil {
+			cause := context.Cause(runCtx)
+			if sigCtx.Err() != nil {
+				cause = errInterrupted
+			}
+			return progress.cancelled(time.Since(started), cause)
+		}
+
+		return err
+	}
+
+	if !ctx.Bool("watch") {
+		return generate()
+	}
+
+	// watch option
+	return watchPath(sigCtx, file, ctx.Duration("watch-interval"), generate)
+}
+
+// graphOptions returns the parser options, which configure the graph, from the
+// given option source.
+func graphOptions(src optionSource) ([]parser.Option, error) {
+	layout, err := getLayoutDirection(src)
+	if err != nil {
+		return nil, err
+	}
+
 	// graph layout direction
 	opts := make([]parser.Option, 0)
 	opts = append(opts, parser.WithLayoutDirection(layout))
 
+	// graph mode
+	mode, err := getGraphMode(src)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, parser.WithGraphMode(mode))
+
+	// detail option
+	if src.Bool("detail") {
+		opts = append(opts, parser.WithShowDetails())
+	}
+
+	// node-label-template option
+	if text := src.String("node-label-template"); text != "" {
+		lt, err := parser.NewLabelTemplate(text)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithLabelTemplate(lt))
+	}
+
+	// legend option
+	position, err := getLegendPosition(src.String("legend"))
+	if err != nil {
+		return nil, err
+	}
+	if position != "" {
+		opts = append(opts, parser.WithLegendPosition(position))
+	}
+
+	// tooltips option
+	if src.Bool("tooltips") {
+		opts = append(opts, parser.WithTooltips())
+	}
+
+	// source-links option
+	if value := src.String("source-links"); value != "" {
+		lt, err := newSourceLinkTemplate(value)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithSourceLinks(lt))
+	}
+
+	// icons and icons-dir options
+	if dir := src.Path("icons-dir"); dir != "" || src.Bool("icons") {
+		opts = append(opts, parser.WithIcons(dir))
+	}
+
+	// show-images option
+	if src.Bool("show-images") {
+		opts = append(opts, parser.WithShowImages())
+	}
+
+	// show-patches option
+	if src.Bool("show-patches") {
+		opts = append(opts, parser.WithShowPatches())
+	}
+
+	// group-remotes option
+	if src.Bool("group-remotes") {
+		opts = append(opts, parser.WithGroupRemotes())
+	}
+
+	// separate-ranks option
+	if src.Bool("separate-ranks") {
+		opts = append(opts, parser.WithSeparateRanks())
+	}
+
+	// same-rank-kind option
+	for _, kind := range src.StringSlice("same-rank-kind") {
+		opts = append(opts, parser.WithSameRankKind(kind))
+	}
+
+	// theme option
+	theme, err := getTheme(src.String("theme"))
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, parser.WithTheme(theme))
+
+	// background and transparent options
+	if src.Bool("transparent") {
+		opts = append(opts, parser.WithTransparentBackground())
+	} else if background := src.String("background"); background != "" {
+		opts = append(opts, parser.WithBackground(background))
+	}
+
+	// auto-contrast option
+	if src.Bool("auto-contrast") {
+		opts = append(opts, parser.WithFontContrast())
+	}
+
+	// auto-color option
+	autoColor, err := getAutoColor(src.String("auto-color"))
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, parser.WithAutoColor(autoColor))
+
+	// font options
+	fontName := src.String("font-name")
+	fontSize := src.Float64("font-size")
+	opts = append(
+		opts,
+		parser.WithGraphFont(
+			cmp.Or(src.String("graph-font-name"), fontName),
+			cmp.Or(src.Float64("graph-font-size"), fontSize),
+		),
+		parser.WithNodeFont(
+			cmp.Or(src.String("node-font-name"), fontName),
+			cmp.Or(src.Float64("node-font-size"), fontSize),
+		),
+		parser.WithEdgeFont(
+			cmp.Or(src.String("edge-font-name"), fontName),
+			cmp.Or(src.Float64("edge-font-size"), fontSize),
+		),
+	)
+
+	// dpi, max-width, max-height and ratio options
+	opts = append(opts, parser.WithDPI(src.Float64("dpi")))
+	opts = append(opts, parser.WithMaxSize(src.Float64("max-width"), src.Float64("max-height")))
+	if value := src.String("ratio"); value != "" {
+		ratio, err := getRatio(value)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithRatio(ratio))
+	}
+
+	// vertex shapes
+	opts = append(opts, parser.WithResourceShape(src.String("resource-shape")))
+	opts = append(opts, parser.WithOriginShape(src.String("origin-shape")))
+
 	// highlight-kind options
-	hkValues := ctx.StringSlice("highlight-kind")
+	hkValues := src.StringSlice("highlight-kind")
 	hkPairs, err := parseKV(hkValues...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, pair := range hkPairs {
 		opts = append(opts, parser.WithHighlightKind(pair.key, pair.val))
 	}
 
 	// highlight-namespace options
-	hnValues := ctx.StringSlice("highlight-namespace")
+	hnValues := src.StringSlice("highlight-namespace")
 	hnPairs, err := parseKV(hnValues...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, pair := range hnPairs {
 		opts = append(opts, parser.WithHighlightNamespace(pair.key, pair.val))
 	}
 
+	// highlight-name-regex options
+	hrPairs, err := parseRegexpColors(src.StringSlice("highlight-name-regex")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range hrPairs {
+		res, err := compileRegexps(pair.key)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithHighlightNameRegexp(res[0], pair.val))
+	}
+
+	// gradient-highlights option
+	if src.Bool("gradient-highlights") {
+		opts = append(opts, parser.WithGradientHighlights())
+	}
+
+	// edge-style options
+	esPairs, err := parseKV(src.StringSlice("edge-style")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range esPairs {
+		edgeType := parser.EdgeType(pair.key)
+		if err := parser.ValidateEdgeStyle(edgeType, pair.val); err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithEdgeStyle(edgeType, pair.val))
+	}
+
+	// node-attr options
+	nodeAttrs, err := parseNodeAttributes(src.StringSlice("node-attr")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, na := range nodeAttrs {
+		opts = append(opts, parser.WithNodeAttributes(na.kind, na.attrs))
+	}
+
+	// graph-attr options
+	gaPairs, err := parseGraphAttributes(src.StringSlice("graph-attr")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range gaPairs {
+		opts = append(opts, parser.WithGraphAttribute(pair.key, pair.val))
+	}
+
+	// mark-orphans and orphan-attr options. The orphan attributes imply
+	// marking the orphans.
+	oaPairs, err := parseGraphAttributes(src.StringSlice("orphan-attr")...)
+	if err != nil {
+		return nil, err
+	}
+	if src.Bool("mark-orphans") || len(oaPairs) > 0 {
+		attrs := make(map[string]string, len(oaPairs))
+		for _, pair := range oaPairs {
+			attrs[pair.key] = pair.val
+		}
+		opts = append(opts, parser.WithOrphanStyle(attrs))
+	}
+
+	// style-file option
+	if styleFile := src.Path("style-file"); styleFile != "" {
+		styles, err := parser.StylesFromPath(styleFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithStyles(styles...))
+	}
+
+	// filter options
+	filterOpts, err := filterOptions(src)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, filterOpts...)
+
+	// root and max-depth options
+	for _, v := range src.StringSlice("root") {
+		opts = append(opts, parser.WithRoot(v))
+	}
+	opts = append(opts, parser.WithMaxDepth(src.Int("max-depth")))
+
+	// prune-unreachable option
+	if src.Bool("prune-unreachable") {
+		opts = append(opts, parser.WithPruneUnreachable())
+	}
+
+	// drop-orphans option
+	if src.Bool("drop-orphans") {
+		opts = append(opts, parser.WithDropOrphans())
+	}
+
+	// max-vertices, max-edges, on-exceed and collapse options
+	action, err := getThresholdAction(src.String("on-exceed"))
+	if err != nil {
+		return nil, err
+	}
+	opts = append(
+		opts,
+		parser.WithMaxVertices(src.Int("max-vertices")),
+		parser.WithMaxEdges(src.Int("max-edges")),
+		parser.WithThresholdAction(action),
+	)
+	if src.Bool("collapse") {
+		opts = append(opts, parser.WithCollapse())
+	}
+
+	// focus options
+	for _, v := range src.StringSlice("focus") {
+		opts = append(opts, parser.WithFocus(v))
+	}
+	opts = append(opts, parser.WithFocusDepth(src.Int("depth")))
+
+	// longest-path options
+	if target := src.String("longest-path-to"); target != "" {
+		opts = append(opts, parser.WithLongestPathTo(target))
+	} else if src.Bool("longest-path") {
+		opts = append(opts, parser.WithLongestPath())
+	}
+
+	// previous-file option
+	if previousFile := src.Path("previous-file"); previousFile != "" {
+		previous, err := parser.ResourcesFromPath(previousFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithPreviousResources(previous))
+	}
+
+	return opts, nil
+}
+
+// filterOptions returns the parser options, which select the resources to keep
+// and drop, from the given option source.
+func filterOptions(src optionSource) ([]parser.Option, error) {
+	opts := make([]parser.Option, 0)
+
+	// case-sensitive and exact-match options
+	caseSensitive := src.Bool("case-sensitive")
+	if caseSensitive {
+		opts = append(opts, parser.WithCaseSensitive())
+	}
+	if src.Bool("exact-match") {
+		opts = append(opts, parser.WithExactMatch())
+	}
+
+	// pair returns the keep and drop filters of the options with the given
+	// names
+	pair := func(keep string, drop string, foldCase bool) filterPair {
+		fp := filterPair{
+			name:     fmt.Sprintf("%s and %s", src.optionName(keep), src.optionName(drop)),
+			keep:     src.StringSlice(keep),
+			drop:     src.StringSlice(drop),
+			foldCase: foldCase,
+		}
+
+		return fp
+	}
+
+	// Make sure that no value is both kept and dropped
+	err := checkConflictingFilters(
+		pair("keep-kind", "drop-kind", !caseSensitive),
+		pair("keep-namespace", "drop-namespace", !caseSensitive),
+		pair("keep-group", "drop-group", !caseSensitive),
+		pair("keep-owner-kind", "drop-owner-kind", !caseSensitive),
+		pair("keep-has-label", "drop-has-label", false),
+		pair("keep-origin-path", "drop-origin-path", false),
+		pair("keep-name-regex", "drop-name-regex", false),
+		pair("keep-name-prefix", "drop-name-prefix", false),
+		pair("keep-name-suffix", "drop-name-suffix", false),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// drop-kind options
-	dkValues := ctx.StringSlice("drop-kind")
+	dkValues := src.StringSlice("drop-kind")
 	for _, dk := range dkValues {
 		opts = append(opts, parser.WithDropKind(dk))
 	}
 
 	// drop-namespace options
-	dnValues := ctx.StringSlice("drop-namespace")
+	dnValues := src.StringSlice("drop-namespace")
 	for _, dn := range dnValues {
 		opts = append(opts, parser.WithDropNamespace(dn))
 	}
 
 	// keep-kind options
-	kkValues := ctx.StringSlice("keep-kind")
+	kkValues := src.StringSlice("keep-kind")
 	for _, kk := range kkValues {
 		opts = append(opts, parser.WithKeepKind(kk))
 	}
 
 	// keep-namespace options
-	knValues := ctx.StringSlice("keep-namespace")
+	knValues := src.StringSlice("keep-namespace")
 	for _, kn := range knValues {
 		opts = append(opts, parser.WithKeepNamespace(kn))
 	}
 
-	// Read the resources and generate the graph
-	var resources []*resource.Resource
+	// drop-cluster-scoped and keep-cluster-scoped-only options
+	dropClusterScoped := src.Bool("drop-cluster-scoped")
+	keepClusterScopedOnly := src.Bool("keep-cluster-scoped-only")
+	if dropClusterScoped && keepClusterScopedOnly {
+		return nil, fmt.Errorf("%w: %s and %s", errConflictingFilters, src.optionName("drop-cluster-scoped"), src.optionName("keep-cluster-scoped-only"))
+	}
+	if dropClusterScoped {
+		opts = append(opts, parser.WithDropClusterScoped())
+	}
+	if keepClusterScopedOnly {
+		opts = append(opts, parser.WithKeepClusterScopedOnly())
+	}
 
-	file := ctx.Path("file")
-	if file == "-" {
-		// Special case for resources passed on stdin
-		resources, err = parser.ResourcesFromReader(os.Stdin)
+	// local-only and remote-only options
+	localOnly := src.Bool("local-only")
+	remoteOnly := src.Bool("remote-only")
+	if localOnly && remoteOnly {
+		return nil, fmt.Errorf("%w: %s and %s", errConflictingFilters, src.optionName("local-only"), src.optionName("remote-only"))
+	}
+	if localOnly {
+		opts = append(opts, parser.WithLocalOnly())
+	}
+	if remoteOnly {
+		opts = append(opts, parser.WithRemoteOnly())
+	}
+
+	// drop-group options
+	for _, dg := range src.StringSlice("drop-group") {
+		opts = append(opts, parser.WithDropGroup(dg))
+	}
+
+	// keep-group options
+	for _, kg := range src.StringSlice("keep-group") {
+		opts = append(opts, parser.WithKeepGroup(kg))
+	}
+
+	// drop-origin-path options
+	for _, pattern := range src.StringSlice("drop-origin-path") {
+		opts = append(opts, parser.WithDropOriginPath(pattern))
+	}
+
+	// keep-origin-path options
+	for _, pattern := range src.StringSlice("keep-origin-path") {
+		opts = append(opts, parser.WithKeepOriginPath(pattern))
+	}
+
+	// drop-owner-kind and keep-owner-kind options
+	for _, kind := range src.StringSlice("drop-owner-kind") {
+		opts = append(opts, parser.WithDropOwnerKind(kind))
+	}
+	for _, kind := range src.StringSlice("keep-owner-kind") {
+		opts = append(opts, parser.WithKeepOwnerKind(kind))
+	}
+
+	// drop-has-label and keep-has-label options
+	for _, label := range src.StringSlice("drop-has-label") {
+		opts = append(opts, parser.WithDropHasLabel(label))
+	}
+	for _, label := range src.StringSlice("keep-has-label") {
+		opts = append(opts, parser.WithKeepHasLabel(label))
+	}
+
+	// keep-resource options
+	for _, v := range src.StringSlice("keep-resource") {
+		opts = append(opts, parser.WithKeepResource(v))
+	}
+
+	// drop-name-regex options
+	drValues, err := compileRegexps(src.StringSlice("drop-name-regex")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, re := range drValues {
+		opts = append(opts, parser.WithDropNameRegexp(re))
+	}
+
+	// keep-name-regex options
+	krValues, err := compileRegexps(src.StringSlice("keep-name-regex")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, re := range krValues {
+		opts = append(opts, parser.WithKeepNameRegexp(re))
+	}
+
+	// drop-name-prefix and drop-name-suffix options
+	for _, prefix := range src.StringSlice("drop-name-prefix") {
+		opts = append(opts, parser.WithDropNamePrefix(prefix))
+	}
+	for _, suffix := range src.StringSlice("drop-name-suffix") {
+		opts = append(opts, parser.WithDropNameSuffix(suffix))
+	}
+
+	// keep-name-prefix and keep-name-suffix options
+	for _, prefix := range src.StringSlice("keep-name-prefix") {
+		opts = append(opts, parser.WithKeepNamePrefix(prefix))
+	}
+	for _, suffix := range src.StringSlice("keep-name-suffix") {
+		opts = append(opts, parser.WithKeepNameSuffix(suffix))
+	}
+
+	// filter-expr options
+	for _, expr := range src.StringSlice("filter-expr") {
+		f, err := parser.NewFilterExpr(expr)
 		if err != nil {
-			return err
+			return nil, err
 		}
-	} else {
-		resources, err = parser.ResourcesFromPath(file)
+		opts = append(opts, parser.WithFilterExpr(f))
+	}
+
+	// filter-file option
+	if filterFile := src.Path("filter-file"); filterFile != "" {
+		rules, err := parser.RulesFromPath(filterFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		opts = append(opts, parser.WithRules(rules...))
 	}
 
-	p := parser.New(opts...)
-	g, err := p.Parse(resources)
+	// filter-precedence option
+	precedence, err := getFilterPrecedence(src.String("filter-precedence"))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	opts = append(opts, parser.WithFilterPrecedence(precedence))
+
+	// query options
+	for _, expr := range src.StringSlice("query") {
+		q, err := parser.NewQuery(expr)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithQuery(q))
+	}
+
+	// drop-generated option
+	if src.Bool("drop-generated") {
+		opts = append(opts, parser.WithDropGenerated())
 	}
 
-	return graph.WriteDot(g, os.Stdout)
+	return opts, nil
 }
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+)
+
+// errFileExists is returned when the init command would overwrite an
+// existing file.
+var errFileExists = errors.New("file already exists")
+
+// scaffoldFile is a file written by the init command.
+type scaffoldFile struct {
+	// name is the name of the file
+	name string
+
+	// content is the content of the file
+	content string
+}
+
+// scaffoldFiles are the files written by the init command, i.e. a
+// kustomization using the KRM Function as a transformer, the config of the
+// transformer, and the config and styles used by the CLI.
+var scaffoldFiles = []scaffoldFile{
+	{
+		name: "kustomization.yaml",
+		content: `---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+metadata:
+  name: kustomize-dot
+
+buildMetadata:
+  - originAnnotations
+
+resources:
+  - https://github.com/kubernetes-sigs/kustomize//examples/helloWorld/?timeout=120
+
+transformers:
+  - transformer.yaml
+`,
+	},
+	{
+		name: "transformer.yaml",
+		content: `---
+apiVersion: dnaeon.github.io/v1
+kind: KustomizeDot
+metadata:
+  name: kustomize-dot
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: dnaeon/kustomize-dot:latest
+spec:
+  # Graph layout direction - TB, BT, LR or RL
+  layout: LR
+
+  # Add a legend at the bottom or right of the graph, or off
+  legend: bottom
+
+  # Drop resources of the given kinds
+  dropKinds:
+    # - Secret
+
+  # Highlight resources of the given kinds
+  highlightKinds:
+    service: yellow
+    deployment: lightgreen
+`,
+	},
+	{
+		name: configFileName,
+		content: `# Defaults of the generate and render command flags
+layout: LR
+legend: bottom
+style-file: style.yaml
+
+# Drop resources of the given kinds
+drop-kind:
+  # - Secret
+
+# Highlight resources of the given kinds
+highlight-kind:
+  - service=yellow
+  - deployment=lightgreen
+
+# Named filter presets, which are selected with --preset
+presets:
+  no-config:
+    drop-kind:
+      - ConfigMap
+      - Secret
+`,
+	},
+	{
+		name: "style.yaml",
+		content: `# Styles applied to the resources in order, so that later styles override
+# earlier ones
+styles:
+  - kind: Deployment
+    shape: box3d
+  - kind: ConfigMap
+    fillColor: lightyellow
+`,
+	},
+}
+
+// newInitCommand returns the command for writing a sample kustomization, which
+// uses the KRM Function, along with a sample config of the CLI.
+func newInitCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:   "init",
+		Usage:  "write a sample kustomization using the KRM function, and a sample config",
+		Action: execInitCommand,
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:    "dir",
+				Usage:   "directory to write the sample files to",
+				Value:   ".",
+				Aliases: []string{"d"},
+				EnvVars: []string{"KUSTOMIZE_DOT_INIT_DIR"},
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "overwrite existing files",
+				Value: false,
+			},
+		},
+	}
+
+	return cmd
+}
+
+// execInitCommand writes the sample files. Unless forced, no file is written,
+// when any of them already exists.
+func execInitCommand(ctx *cli.Context) error {
+	dir := ctx.Path("dir")
+	if !ctx.Bool("force") {
+		for _, file := range scaffoldFiles {
+			path := filepath.Join(dir, file.name)
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%w: %s", errFileExists, path)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, file := range scaffoldFiles {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, []byte(file.content), 0o644); err != nil {
+			return err
+		}
+		printMessage("wrote %s", path)
+	}
+
+	printMessage("build the graph using: kustomize build --enable-alpha-plugins %s", dir)
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// scaffoldFileNames returns the names of the files written by the init
+// command in sorted order.
+func scaffoldFileNames() []string {
+	names := make([]string, 0, len(scaffoldFiles))
+	for _, file := range scaffoldFiles {
+		names = append(names, file.name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func TestInitCommand(t *testing.T) {
+	type testCase struct {
+		desc     string
+		args     []string
+		env      bool
+		existing string
+		wantErr  error
+	}
+
+	testCases := []testCase{
+		{desc: "new directory"},
+		{desc: "environment variable", env: true},
+		{desc: "existing file", existing: "kustomization.yaml", wantErr: errFileExists},
+		{desc: "existing file forced", existing: "style.yaml", args: []string{"--force"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "nested", "example")
+			args := []string{"init"}
+			if tc.env {
+				t.Setenv("KUSTOMIZE_DOT_INIT_DIR", dir)
+			} else {
+				args = append(args, "--dir", dir)
+			}
+			if tc.existing != "" {
+				writeTestFile(t, filepath.Join(dir, tc.existing), "existing")
+			}
+
+			_, msgs, err := runApp(t, append(args, tc.args...)...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+
+			if tc.wantErr != nil {
+				// No file is written, when any of them exists
+				if names := dirEntries(t, dir); !slices.Equal(names, []string{tc.existing}) {
+					t.Fatalf("want only %s, got %q", tc.existing, names)
+				}
+				data, _ := os.ReadFile(filepath.Join(dir, tc.existing))
+				if string(data) != "existing" {
+					t.Fatalf("want %s unchanged, got %q", tc.existing, data)
+				}
+				return
+			}
+
+			if names := dirEntries(t, dir); !slices.Equal(names, scaffoldFileNames()) {
+				t.Fatalf("want files %q, got %q", scaffoldFileNames(), names)
+			}
+			for _, file := range scaffoldFiles {
+				path := filepath.Join(dir, file.name)
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("reading %s failed: %s", file.name, err)
+				}
+				if string(data) != file.content {
+					t.Fatalf("want %s written, got %q", file.name, data)
+				}
+				if !strings.Contains(msgs, "wrote "+path+"\n") {
+					t.Fatalf("want message about %s, got %q", path, msgs)
+				}
+			}
+			if !strings.Contains(msgs, "kustomize build --enable-alpha-plugins "+dir) {
+				t.Fatalf("want build instructions, got %q", msgs)
+			}
+		})
+	}
+}
+
+func TestScaffoldFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := runApp(t, "init", "--dir", dir); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+
+	// The kustomization uses the transformer
+	data, err := os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	if err != nil {
+		t.Fatalf("reading kustomization failed: %s", err)
+	}
+	var kustomization struct {
+		Transformers []string `yaml:"transformers"`
+	}
+	if err := yaml.Unmarshal(data, &kustomization); err != nil {
+		t.Fatalf("parsing kustomization failed: %s", err)
+	}
+	if !reflect.DeepEqual(kustomization.Transformers, []string{"transformer.yaml"}) {
+		t.Fatalf("want transformer.yaml used, got %q", kustomization.Transformers)
+	}
+
+	// The transformer config is valid
+	schema, err := pluginSchema()
+	if err != nil {
+		t.Fatalf("creating schema failed: %s", err)
+	}
+	node, err := yaml.ReadFile(filepath.Join(dir, "transformer.yaml"))
+	if err != nil {
+		t.Fatalf("reading transformer config failed: %s", err)
+	}
+	if problems := schema.validate(node, ""); len(problems) > 0 {
+		t.Fatalf("want valid transformer config, got %v", problems)
+	}
+
+	// The CLI config, its preset and the styles are used
+	resources := writeTestResources(t, fixtures.HelloWorld)
+	chdir(t, dir)
+	out, _, err := runApp(t, "generate", "-f", resources)
+	if err != nil {
+		t.Fatalf("generating graph failed: %s", err)
+	}
+	want := []string{testConfigMap, testDeployment, testService}
+	if got := graphResources(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want resources %q, got %q", want, got)
+	}
+	for _, attr := range []string{`rankdir="LR"`, `shape="box3d"`, "yellow", "lightgreen"} {
+		if !strings.Contains(out, attr) {
+			t.Fatalf("want graph containing %s, got %q", attr, out)
+		}
+	}
+
+	out, _, err = runApp(t, "generate", "-f", resources, "--preset", "no-config")
+	if err != nil {
+		t.Fatalf("generating graph failed: %s", err)
+	}
+	want = []string{testDeployment, testService}
+	if got := graphResources(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want resources %q, got %q", want, got)
+	}
+}
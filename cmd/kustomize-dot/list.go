@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// newListCommand returns the command for listing the distinct kinds,
+// namespaces and origins of resources.
+func newListCommand() *cli.Command {
+	listers := []struct {
+		name  string
+		usage string
+		list  func(resources []*resource.Resource) ([]string, error)
+	}{
+		{
+			name:  "kinds",
+			usage: "list the kinds of resources",
+			list: func(resources []*resource.Resource) ([]string, error) {
+				return parser.Kinds(resources), nil
+			},
+		},
+		{
+			name:  "namespaces",
+			usage: "list the namespaces of resources",
+			list: func(resources []*resource.Resource) ([]string, error) {
+				return parser.Namespaces(resources), nil
+			},
+		},
+		{
+			name:  "origins",
+			usage: "list the origin paths of resources",
+			list:  parser.Origins,
+		},
+	}
+
+	subcommands := make([]*cli.Command, 0, len(listers))
+	for _, l := range listers {
+		subcommand := &cli.Command{
+			Name:  l.name,
+			Usage: l.usage,
+			Flags: []cli.Flag{
+				&cli.PathFlag{
+					Name:     "file",
+					Usage:    "file containing the Kubernetes resources",
+					Required: true,
+					Aliases:  []string{"f"},
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				resources, err := readResources(ctx.Path("file"))
+				if err != nil {
+					return err
+				}
+
+				values, err := l.list(resources)
+				if err != nil {
+					return err
+				}
+				for _, v := range values {
+					fmt.Fprintln(os.Stdout, v)
+				}
+
+				return nil
+			},
+		}
+		subcommands = append(subcommands, subcommand)
+	}
+
+	cmd := &cli.Command{
+		Name:        "list",
+		Usage:       "list the distinct kinds, namespaces or origins of resources",
+		Aliases:     []string{"ls"},
+		Subcommands: subcommands,
+	}
+
+	return cmd
+}
@@ -32,10 +32,14 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-func main() {
+// version is the version of the app, which is set at build time for releases.
+var version = "0.1.0"
+
+// newApp returns the app along with its commands.
+func newApp() *cli.App {
 	app := &cli.App{
 		Name:                 "kustomize-dot",
-		Version:              "0.1.0",
+		Version:              version,
 		EnableBashCompletion: true,
 		Suggest:              true,
 		Usage:                "tool for generating graphs from kustomize resources",
@@ -45,12 +49,37 @@ func main() {
 				Email: "dnaeon@gmail.com",
 			},
 		},
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:    "config",
+				Usage:   "config file with the default values of flags",
+				EnvVars: []string{"KUSTOMIZE_DOT_CONFIG"},
+			},
+			newQuietFlag(),
+		},
+		Before: setupQuiet,
 		Commands: []*cli.Command{
 			newGenerateCommand(),
+			newRenderCommand(),
+			newPruneCommand(),
+			newQueryCommand(),
+			newListCommand(),
+			newExploreCommand(),
+			newTUICommand(),
+			newInitCommand(),
+			newSchemaCommand(),
+			newSelfUpdateCommand(),
 			newPluginCommand(),
 		},
 	}
 
+	addQuietFlag(app.Commands)
+
+	return app
+}
+
+func main() {
+	app := newApp()
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+// writeTestResources writes the given resources to a file in a temporary
+// directory, and returns its path.
+func writeTestResources(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "resources.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("writing resources failed: %s", err)
+	}
+
+	return path
+}
+
+// TestMain runs the tests with an empty user config directory, so that only
+// the config files written by the tests are loaded.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "kustomize-dot-config")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating config directory failed: %s\n", err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// chdir changes the working directory to the given directory for the rest
+// of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting working directory failed: %s", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("changing working directory failed: %s", err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+// runApp runs the app with the given arguments, and returns what it wrote to
+// stdout, and the messages it wrote to stderr.
+func runApp(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+
+	return runAppContext(context.Background(), t, args...)
+}
+
+// runAppContext is like runApp, but runs the app with the given context.
+func runAppContext(ctx context.Context, t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe failed: %s", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&out, r)
+		close(done)
+	}()
+
+	var msgs bytes.Buffer
+	saved := messages
+	messages = &msgs
+	defer func() {
+		messages = saved
+	}()
+
+	err = newApp().RunContext(ctx, append([]string{"kustomize-dot"}, args...))
+	w.Close()
+	<-done
+	r.Close()
+
+	return out.String(), msgs.String(), err
+}
+
+func TestRunApp(t *testing.T) {
+	path := writeTestResources(t, fixtures.HelloWorld)
+
+	out, _, err := runApp(t, "generate", "-f", path)
+	if err != nil {
+		t.Fatalf("generating graph failed: %s", err)
+	}
+	if !strings.HasPrefix(out, "strict digraph") {
+		t.Fatalf("want graph written to stdout, got %q", out)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"reflect"
+
+	"github.com/urfave/cli/v2"
+)
+
+// optionSource provides the values of the options, which configure the
+// parser, by the names of the generate command flags. It is implemented by the
+// CLI context and by the plugin spec, so that the generate command and the KRM
+// Function build the parser options in the same way.
+type optionSource interface {
+	String(name string) string
+	Path(name string) string
+	Bool(name string) bool
+	Int(name string) int
+	Float64(name string) float64
+	StringSlice(name string) []string
+
+	// optionName returns the name of the option, which is used in error
+	// messages.
+	optionName(name string) string
+}
+
+// contextOptions provides the values of the options from the CLI context.
+type contextOptions struct {
+	*cli.Context
+}
+
+// optionName implements the [optionSource] interface.
+func (co contextOptions) optionName(name string) string {
+	return "--" + name
+}
+
+// specOptions provides the values of the options from the plugin spec. The
+// options, whose spec field is not set, and the options without a spec field,
+// e.g. style-file, take the default value of the generate command flag.
+type specOptions struct {
+	spec  *pluginSpec
+	flags map[string]cli.Flag
+}
+
+// newSpecOptions returns the [specOptions] of the given plugin spec.
+func newSpecOptions(spec *pluginSpec) *specOptions {
+	flags := make(map[string]cli.Flag)
+	for _, flag := range newGenerateCommand().Flags {
+		for _, name := range flag.Names() {
+			flags[name] = flag
+		}
+	}
+
+	so := &specOptions{
+		spec:  spec,
+		flags: flags,
+	}
+
+	return so
+}
+
+// value returns the value of the spec field, which corresponds to the option
+// with the given name, if the field is set.
+func (so *specOptions) value(name string) (reflect.Value, bool) {
+	field, ok := configSpecField(name)
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	v := reflect.ValueOf(so.spec).Elem().FieldByIndex(field.Index)
+	switch {
+	case v.Kind() == reflect.Pointer && !v.IsNil():
+		return v.Elem(), true
+	case v.Kind() == reflect.Pointer, v.IsZero():
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
+
+// String implements the [optionSource] interface.
+func (so *specOptions) String(name string) string {
+	if v, ok := so.value(name); ok {
+		return v.String()
+	}
+
+	switch flag := so.flags[name].(type) {
+	case *cli.StringFlag:
+		return flag.Value
+	case *cli.PathFlag:
+		return flag.Value
+	}
+
+	return ""
+}
+
+// Path implements the [optionSource] interface.
+func (so *specOptions) Path(name string) string {
+	return so.String(name)
+}
+
+// Bool implements the [optionSource] interface.
+func (so *specOptions) Bool(name string) bool {
+	if v, ok := so.value(name); ok {
+		return v.Bool()
+	}
+	if flag, ok := so.flags[name].(*cli.BoolFlag); ok {
+		return flag.Value
+	}
+
+	return false
+}
+
+// Int implements the [optionSource] interface.
+func (so *specOptions) Int(name string) int {
+	if v, ok := so.value(name); ok {
+		return int(v.Int())
+	}
+	if flag, ok := so.flags[name].(*cli.IntFlag); ok {
+		return flag.Value
+	}
+
+	return 0
+}
+
+// Float64 implements the [optionSource] interface.
+func (so *specOptions) Float64(name string) float64 {
+	if v, ok := so.value(name); ok {
+		return v.Float()
+	}
+	if flag, ok := so.flags[name].(*cli.Float64Flag); ok {
+		return flag.Value
+	}
+
+	return 0
+}
+
+// StringSlice implements the [optionSource] interface. Mappings are returned
+// as key/value pairs in sorted order, in the same way as the mappings of the
+// config files, e.g. Deployment=yellow.
+func (so *specOptions) StringSlice(name string) []string {
+	if v, ok := so.value(name); ok {
+		return configValues(interfaceValue(v))
+	}
+	if flag, ok := so.flags[name].(*cli.StringSliceFlag); ok && flag.Value != nil {
+		return flag.Value.Value()
+	}
+
+	return nil
+}
+
+// optionName implements the [optionSource] interface by returning the name
+// of the spec field, e.g. keepKinds.
+func (so *specOptions) optionName(name string) string {
+	field, ok := configSpecField(name)
+	if !ok {
+		return name
+	}
+
+	return yamlName(field)
+}
+
+// interfaceValue returns the value of the given spec field in the form of the
+// values of the config files, where mappings are of type map[string]any.
+func interfaceValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = interfaceValue(iter.Value())
+		}
+		return m
+	case reflect.Slice:
+		items := make([]any, 0, v.Len())
+		for i := range v.Len() {
+			items = append(items, interfaceValue(v.Index(i)))
+		}
+		return items
+	}
+
+	return v.Interface()
+}
@@ -27,11 +27,22 @@ package main
 
 import (
 	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/dnaeon/kustomize-dot/pkg/parser"
 	"github.com/urfave/cli/v2"
-	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework/command"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -49,6 +60,121 @@ type pluginSpec struct {
 	// Layout contains the layout direction
 	Layout string `yaml:"layout"`
 
+	// Format is the output format of the graph, e.g. dot, mermaid, svg or
+	// json.
+	Format string `yaml:"format"`
+
+	// OriginsOnly specifies whether to graph only the origins of
+	// resources.
+	OriginsOnly bool `yaml:"originsOnly"`
+
+	// NoOrigins specifies whether to graph only resources and the
+	// relationships between them.
+	NoOrigins bool `yaml:"noOrigins"`
+
+	// Detail specifies whether to include container images, replica count
+	// and service type in the vertex labels.
+	Detail bool `yaml:"detail"`
+
+	// NodeLabelTemplate is the Go template used to render the labels of
+	// resource vertices.
+	NodeLabelTemplate string `yaml:"nodeLabelTemplate"`
+
+	// Legend specifies the position of the legend describing the shapes,
+	// styles and colors used in the graph, either bottom, right or off.
+	Legend pluginLegend `yaml:"legend"`
+
+	// Tooltips specifies whether to show the metadata of resources as
+	// tooltips of the vertices.
+	Tooltips bool `yaml:"tooltips"`
+
+	// SourceLinks specifies how remote origins link to their files in the
+	// forge, either github, gitlab or a Go template.
+	SourceLinks string `yaml:"sourceLinks"`
+
+	// Icons specifies whether to embed the bundled Kubernetes icons in the
+	// resource vertices.
+	Icons bool `yaml:"icons"`
+
+	// IconsDir is the directory with Kubernetes icons, which override the
+	// bundled icons. Setting it implies embedding the icons.
+	IconsDir string `yaml:"iconsDir"`
+
+	// ShowImages specifies whether to add the container images of
+	// workloads as vertices to the graph.
+	ShowImages bool `yaml:"showImages"`
+
+	// ShowPatches specifies whether to connect resources to the
+	// kustomizations, whose patches modified them.
+	ShowPatches bool `yaml:"showPatches"`
+
+	// GroupRemotes specifies whether to group origins from the same
+	// remote repository and ref into a cluster.
+	GroupRemotes bool `yaml:"groupRemotes"`
+
+	// SeparateRanks specifies whether to place origins and resources on
+	// separate ranks.
+	SeparateRanks bool `yaml:"separateRanks"`
+
+	// SameRankKinds contains the kinds of resources, which are placed on
+	// the same rank, one rank per kind.
+	SameRankKinds []string `yaml:"sameRankKinds"`
+
+	// Theme specifies the visual theme of the graph.
+	Theme string `yaml:"theme"`
+
+	// Background specifies the background color of the graph, which
+	// overrides the background of the theme.
+	Background string `yaml:"background"`
+
+	// Transparent specifies whether to render the graph on a transparent
+	// background.
+	Transparent bool `yaml:"transparent"`
+
+	// AutoContrast specifies whether to switch the font color of
+	// highlighted resources to white or black, depending on how dark the
+	// fill color is.
+	AutoContrast bool `yaml:"autoContrast"`
+
+	// AutoColor specifies whether each namespace or kind is automatically
+	// assigned a distinct color.
+	AutoColor string `yaml:"autoColor"`
+
+	// FontName and FontSize specify the font of the graph, vertex and
+	// edge labels, unless overridden by the more specific fonts below.
+	FontName string  `yaml:"fontName"`
+	FontSize float64 `yaml:"fontSize"`
+
+	// GraphFontName and GraphFontSize specify the font of the graph and
+	// cluster labels.
+	GraphFontName string  `yaml:"graphFontName"`
+	GraphFontSize float64 `yaml:"graphFontSize"`
+
+	// NodeFontName and NodeFontSize specify the font of the vertex labels.
+	NodeFontName string  `yaml:"nodeFontName"`
+	NodeFontSize float64 `yaml:"nodeFontSize"`
+
+	// EdgeFontName and EdgeFontSize specify the font of the edge labels.
+	EdgeFontName string  `yaml:"edgeFontName"`
+	EdgeFontSize float64 `yaml:"edgeFontSize"`
+
+	// DPI specifies the resolution of rendered images in dots per inch.
+	DPI float64 `yaml:"dpi"`
+
+	// MaxWidth and MaxHeight specify the maximum size of rendered images
+	// in inches.
+	MaxWidth  float64 `yaml:"maxWidth"`
+	MaxHeight float64 `yaml:"maxHeight"`
+
+	// Ratio specifies the aspect ratio of rendered images.
+	Ratio string `yaml:"ratio"`
+
+	// ResourceShape specifies the shape of resource vertices.
+	ResourceShape string `yaml:"resourceShape"`
+
+	// OriginShape specifies the shape of origin vertices.
+	OriginShape string `yaml:"originShape"`
+
 	// HighlightKinds contains the mapping between Kubernetes resource kind
 	// and the color with which to paint it.
 	HighlightKinds map[string]string `yaml:"highlightKinds"`
@@ -58,6 +184,29 @@ type pluginSpec struct {
 	// namespace.
 	HighlightNamespaces map[string]string `yaml:"highlightNamespaces"`
 
+	// HighlightNameRegex contains the mapping between regular expressions
+	// matching the names of resources and the color with which to paint
+	// the matching resources. The regular expressions are applied in
+	// sorted order.
+	HighlightNameRegex map[string]string `yaml:"highlightNameRegex"`
+
+	// GradientHighlights specifies whether resources highlighted by both
+	// namespace and kind are filled with a gradient of the two colors.
+	GradientHighlights bool `yaml:"gradientHighlights"`
+
+	// EdgeStyles contains the mapping between the types of edges, i.e.
+	// origin, patch, relationship or image, and their style.
+	EdgeStyles map[string]string `yaml:"edgeStyles"`
+
+	// NodeAttributes contains the mapping between resource kinds and the
+	// Dot attributes, which are set on the resources of the given kind
+	// as is. The kinds are applied in sorted order.
+	NodeAttributes map[string]map[string]string `yaml:"nodeAttributes"`
+
+	// GraphAttributes contains the Dot attributes, which are set on the
+	// graph as is.
+	GraphAttributes map[string]string `yaml:"graphAttributes"`
+
 	// DropKinds contains the resource kinds which will be dropped from the
 	// graph.
 	DropKinds []string `yaml:"dropKinds"`
@@ -72,17 +221,722 @@ type pluginSpec struct {
 
 	// KeepNamespaces contains the list of namespaces to keep, along with
 	// the resources from them. Anything else will be dropped.
-	KeepNamespaces []string `yaml:"keepNamespace"`
+	KeepNamespaces []string `yaml:"keepNamespaces"`
+
+	// DropClusterScoped specifies whether to drop all cluster-scoped
+	// resources.
+	DropClusterScoped bool `yaml:"dropClusterScoped"`
+
+	// KeepClusterScopedOnly specifies whether to keep only cluster-scoped
+	// resources.
+	KeepClusterScopedOnly bool `yaml:"keepClusterScopedOnly"`
+
+	// LocalOnly specifies whether to keep only resources originating from
+	// the local kustomization.
+	LocalOnly bool `yaml:"localOnly"`
+
+	// RemoteOnly specifies whether to keep only resources originating
+	// from remote repositories.
+	RemoteOnly bool `yaml:"remoteOnly"`
+
+	// CaseSensitive specifies whether the kind, namespace and group filters
+	// are matched case-sensitively.
+	CaseSensitive bool `yaml:"caseSensitive"`
+
+	// ExactMatch specifies whether the kind, namespace and group filters are
+	// matched literally, without expanding wildcards.
+	ExactMatch bool `yaml:"exactMatch"`
+
+	// DropGroups contains the list of API groups to drop, along with all
+	// resources from them.
+	DropGroups []string `yaml:"dropGroups"`
+
+	// KeepGroups contains the list of API groups to keep, along with the
+	// resources from them. Anything else will be dropped.
+	KeepGroups []string `yaml:"keepGroups"`
+
+	// DropOriginPaths contains the list of glob patterns. Resources, whose
+	// origin path matches any of them will be dropped.
+	DropOriginPaths []string `yaml:"dropOriginPaths"`
+
+	// KeepOriginPaths contains the list of glob patterns. Resources, whose
+	// origin path doesn't match any of them will be dropped.
+	KeepOriginPaths []string `yaml:"keepOriginPaths"`
+
+	// DropOwnerKinds contains the list of kinds. Resources owned by a
+	// resource of any of the kinds will be dropped.
+	DropOwnerKinds []string `yaml:"dropOwnerKinds"`
+
+	// KeepOwnerKinds contains the list of kinds. Resources, which are not
+	// owned by a resource of any of the kinds will be dropped.
+	KeepOwnerKinds []string `yaml:"keepOwnerKinds"`
+
+	// DropHasLabels contains the list of label keys. Resources having
+	// any of them will be dropped.
+	DropHasLabels []string `yaml:"dropHasLabels"`
+
+	// KeepHasLabels contains the list of label keys. Resources, which
+	// don't have any of them will be dropped.
+	KeepHasLabels []string `yaml:"keepHasLabels"`
+
+	// KeepResources contains the list of resources to keep, e.g.
+	// default/configmap/the-map. Anything else will be dropped.
+	KeepResources []string `yaml:"keepResources"`
+
+	// DropNameRegex contains the list of regular expressions. Resources,
+	// whose names match any of them will be dropped.
+	DropNameRegex []string `yaml:"dropNameRegex"`
+
+	// KeepNameRegex contains the list of regular expressions. Resources,
+	// whose names don't match any of them will be dropped.
+	KeepNameRegex []string `yaml:"keepNameRegex"`
+
+	// DropNamePrefixes contains the list of prefixes. Resources, whose
+	// names start with any of them will be dropped.
+	DropNamePrefixes []string `yaml:"dropNamePrefixes"`
+
+	// DropNameSuffixes contains the list of suffixes. Resources, whose
+	// names end with any of them will be dropped.
+	DropNameSuffixes []string `yaml:"dropNameSuffixes"`
+
+	// KeepNamePrefixes contains the list of prefixes. Resources, whose
+	// names don't start with any of them will be dropped.
+	KeepNamePrefixes []string `yaml:"keepNamePrefixes"`
+
+	// KeepNameSuffixes contains the list of suffixes. Resources, whose
+	// names don't end with any of them will be dropped.
+	KeepNameSuffixes []string `yaml:"keepNameSuffixes"`
+
+	// FilterExprs contains the list of CEL expressions. Resources, for
+	// which any of them doesn't evaluate to true will be dropped.
+	FilterExprs []string `yaml:"filterExprs"`
+
+	// Rules contains the ordered list of rules, which keep, drop and
+	// highlight resources.
+	Rules []*parser.Rule `yaml:"rules"`
+
+	// Styles contains the ordered list of style rules, which set the
+	// color, shape and pen width of the matching resources.
+	Styles []*parser.StyleRule `yaml:"styles"`
+
+	// FilterPrecedence specifies whether the filtering options or the
+	// rules are evaluated first.
+	FilterPrecedence string `yaml:"filterPrecedence"`
+
+	// Queries contains the list of jq queries, which select the resources
+	// before constructing the graph.
+	Queries []string `yaml:"queries"`
+
+	// DropGenerated specifies whether to drop the resources produced by
+	// the ConfigMap and Secret generators.
+	DropGenerated bool `yaml:"dropGenerated"`
+
+	// PruneUnreachable specifies whether to drop vertices, which are not
+	// reachable from any resource.
+	PruneUnreachable bool `yaml:"pruneUnreachable"`
+
+	// DropOrphans specifies whether to drop vertices, which are not
+	// connected to any other vertex.
+	DropOrphans bool `yaml:"dropOrphans"`
+
+	// MarkOrphans specifies whether to mark the resources, which select
+	// nothing from the build, e.g. PodDisruptionBudgets matching no
+	// workload.
+	MarkOrphans bool `yaml:"markOrphans"`
+
+	// OrphanAttributes contains the Dot attributes, which are set on the
+	// marked orphan resources on top of the default orphan style. Setting
+	// them implies marking the orphans.
+	OrphanAttributes map[string]string `yaml:"orphanAttributes"`
+
+	// MaxVertices and MaxEdges specify the max number of vertices and
+	// edges of the graph, before the OnExceed action is taken.
+	MaxVertices int `yaml:"maxVertices"`
+	MaxEdges    int `yaml:"maxEdges"`
+
+	// OnExceed specifies the action taken, when the graph exceeds the max
+	// number of vertices or edges, either fail or collapse.
+	OnExceed string `yaml:"onExceed"`
+
+	// Collapse specifies whether to collapse the resources into one vertex
+	// per namespace and kind.
+	Collapse bool `yaml:"collapse"`
+
+	// Roots contains the list of vertices, from which the max depth is
+	// measured.
+	Roots []string `yaml:"roots"`
+
+	// MaxDepth specifies the max number of hops from the root vertices to
+	// keep.
+	MaxDepth *int `yaml:"maxDepth"`
+
+	// Focus contains the list of vertices, whose neighbourhood will be
+	// kept. Anything else will be dropped.
+	Focus []string `yaml:"focus"`
+
+	// FocusDepth specifies the max number of hops from the focus vertices
+	// to keep.
+	FocusDepth int `yaml:"focusDepth"`
+
+	// LongestPath specifies whether to highlight the longest path of the
+	// graph.
+	LongestPath bool `yaml:"longestPath"`
+
+	// LongestPathTo specifies the vertex, at which the highlighted longest
+	// path ends.
+	LongestPathTo string `yaml:"longestPathTo"`
+
+	// PreviousFile specifies the path to a file containing the resources
+	// of a previous build, against which added and modified resources are
+	// emphasized.
+	PreviousFile string `yaml:"previousFile"`
+
+	// Selector selects the resources, which the plugin operates on. The
+	// resources, which are not selected, are passed through unchanged.
+	Selector *pluginSelector `yaml:"selector"`
+
+	// Exclude excludes the matching resources from the ones, which the
+	// plugin operates on.
+	Exclude *pluginSelector `yaml:"exclude"`
+
+	// ConfigMap specifies the ConfigMap, which contains the graph.
+	ConfigMap pluginConfigMap `yaml:"configMap"`
+
+	// OutputFile specifies the path to the file, where the graph is
+	// written instead of the ConfigMap, e.g. a directory mounted into the
+	// container of the function.
+	OutputFile string `yaml:"outputFile"`
+
+	// Mode specifies whether the plugin has generator semantics, i.e. it
+	// emits only the ConfigMap containing the graph, or transformer
+	// semantics, i.e. it returns the resources along with the ConfigMap, or
+	// annotates the resources with their provenance instead.
+	Mode pluginMode `yaml:"mode"`
+
+	// SplitBy specifies the property of resources, e.g. namespace or
+	// repo, by which the graph is split into separate graphs, each of
+	// which is emitted in its own ConfigMap.
+	SplitBy string `yaml:"splitBy"`
+
+	// Passthrough specifies whether to return the resources along with
+	// the ConfigMap containing the graph, instead of replacing them. It
+	// is the same as the transformer mode.
+	Passthrough bool `yaml:"passthrough"`
+}
+
+// pluginMode is the mode of the plugin, which matches the field of the
+// kustomization the KRM Function is wired into.
+type pluginMode string
+
+// String implements the [fmt.Stringer] interface.
+func (pm pluginMode) String() string {
+	return string(pm)
+}
+
+const (
+	// pluginModeGenerator emits only the ConfigMap containing the graph.
+	pluginModeGenerator pluginMode = "generator"
+
+	// pluginModeTransformer returns the resources along with the ConfigMap
+	// containing the graph.
+	pluginModeTransformer pluginMode = "transformer"
+
+	// pluginModeAnnotate returns the resources, where the ones kept by the
+	// filters are annotated with their normalized provenance, instead of
+	// the ConfigMap containing the graph.
+	pluginModeAnnotate pluginMode = "annotate"
+)
+
+// pluginModes returns the list of supported plugin modes.
+func pluginModes() []pluginMode {
+	return []pluginMode{
+		pluginModeGenerator,
+		pluginModeTransformer,
+		pluginModeAnnotate,
+	}
+}
+
+// pluginSelector matches resources in the same way as the [framework.Selector]
+// of KRM Functions. A resource matches, if it matches all of the fields.
+type pluginSelector struct {
+	// Names is the list of names to match
+	Names []string `yaml:"names"`
+
+	// Namespaces is the list of namespaces to match
+	Namespaces []string `yaml:"namespaces"`
+
+	// Kinds is the list of kinds to match
+	Kinds []string `yaml:"kinds"`
+
+	// APIVersions is the list of API versions to match
+	APIVersions []string `yaml:"apiVersions"`
+
+	// Labels are the labels to match
+	Labels map[string]string `yaml:"labels"`
+
+	// Annotations are the annotations to match
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// filter returns the items matched by the selector.
+func (ps *pluginSelector) filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
+	selector := &framework.Selector{
+		Names:       ps.Names,
+		Namespaces:  ps.Namespaces,
+		Kinds:       ps.Kinds,
+		APIVersions: ps.APIVersions,
+		Labels:      ps.Labels,
+		Annotations: ps.Annotations,
+	}
+
+	return selector.Filter(items)
+}
+
+// selectItems returns the items matched by the selector of the spec, which
+// are not matched by its exclusion.
+func (spec *pluginSpec) selectItems(items []*yaml.RNode) ([]*yaml.RNode, error) {
+	selected := items
+	if spec.Selector != nil {
+		matched, err := spec.Selector.filter(items)
+		if err != nil {
+			return nil, err
+		}
+		selected = matched
+	}
+
+	if spec.Exclude != nil {
+		excluded, err := spec.Exclude.filter(selected)
+		if err != nil {
+			return nil, err
+		}
+		selected = slices.DeleteFunc(slices.Clone(selected), func(item *yaml.RNode) bool {
+			return slices.Contains(excluded, item)
+		})
+	}
+
+	return selected, nil
+}
+
+// pluginConfigMap contains the config of the ConfigMap emitted by the plugin.
+type pluginConfigMap struct {
+	// Name is the name of the ConfigMap
+	Name string `yaml:"name"`
+
+	// Namespace is the namespace of the ConfigMap
+	Namespace string `yaml:"namespace"`
+
+	// Labels are the labels of the ConfigMap
+	Labels map[string]string `yaml:"labels"`
+
+	// Key is the data key, which contains the graph. It defaults to the
+	// output format, e.g. dot.
+	Key string `yaml:"key"`
+}
+
+// splitGroupAnnotation is the annotation of the ConfigMap, which contains the
+// group of the graph, when the graph is split.
+const splitGroupAnnotation = "kustomize-dot.dnaeon.github.io/split-group"
+
+const (
+	// defaultConfigMapName is the default name of the ConfigMap emitted by
+	// the plugin.
+	defaultConfigMapName = "kustomize-dot"
+
+	// defaultConfigMapNamespace is the default namespace of the ConfigMap
+	// emitted by the plugin.
+	defaultConfigMapNamespace = "default"
+)
+
+// pluginLegend is the position of the legend in the plugin spec. The true
+// and false values are accepted as well, since the legend used to be a
+// boolean field.
+type pluginLegend string
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (pl *pluginLegend) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*pl = pluginLegend(strconv.FormatBool(enabled))
+		return nil
+	}
+
+	var position string
+	if err := json.Unmarshal(data, &position); err != nil {
+		return err
+	}
+	*pl = pluginLegend(position)
+
+	return nil
+}
+
+// Default implements the [framework.Defaulter] interface by setting the
+// defaults of the fields, which were not configured.
+func (pc *pluginConfig) Default() error {
+	pc.Spec.Layout = cmp.Or(pc.Spec.Layout, parser.LayoutDirectionLR.String())
+	pc.Spec.Format = cmp.Or(pc.Spec.Format, parser.FormatDot.String())
+	if pc.Spec.Mode == "" {
+		pc.Spec.Mode = pluginModeGenerator
+		if pc.Spec.Passthrough {
+			pc.Spec.Mode = pluginModeTransformer
+		}
+	}
+
+	return nil
+}
+
+// validatePluginConfig validates the function config against the schema of
+// the plugin config, and returns the problems found as results, which refer
+// to the invalid fields.
+func validatePluginConfig(functionConfig *yaml.RNode) (framework.Results, error) {
+	schema, err := pluginSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := functionConfig.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	ref := &yaml.ResourceIdentifier{
+		TypeMeta: meta.TypeMeta,
+		NameMeta: meta.ObjectMeta.NameMeta,
+	}
+
+	problems := schema.validate(functionConfig, "")
+	results := make(framework.Results, 0, len(problems))
+	for _, path := range sortedKeys(problems) {
+		result := &framework.Result{
+			Message:     problems[path],
+			Severity:    framework.Error,
+			ResourceRef: ref,
+			Field:       &framework.Field{Path: path},
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// configMapFunctionConfig converts the given ConfigMap function config to the
+// plugin config, whose spec fields are the data keys of the ConfigMap. The
+// data values are converted to the types of the spec fields, so that lists
+// may be given as comma-separated values, e.g. Secret,ConfigMap, and string
+// mappings as comma-separated key/value pairs, e.g. service=yellow. Other
+// values are parsed as YAML, e.g. the rules.
+func configMapFunctionConfig(functionConfig *yaml.RNode) (*yaml.RNode, error) {
+	schema, err := pluginSchema()
+	if err != nil {
+		return nil, err
+	}
+	specSchema := schema.Properties["spec"]
+
+	spec := yaml.NewMapRNode(nil)
+	data := functionConfig.GetDataMap()
+	for _, key := range sortedKeys(data) {
+		value, err := configMapValue(specSchema.Properties[key], data[key])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", errInvalidConfigValue, key, err)
+		}
+		if err := spec.PipeE(yaml.SetField(key, value)); err != nil {
+			return nil, err
+		}
+	}
+
+	config := functionConfig.Copy()
+	if err := config.PipeE(yaml.Clear("data")); err != nil {
+		return nil, err
+	}
+	if err := config.PipeE(yaml.SetField("spec", spec)); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// configMapValue converts the value of a ConfigMap data key to the type of
+// the spec field with the given schema. Unknown fields are kept as strings,
+// so that they are reported by the validation.
+func configMapValue(schema *jsonSchema, value string) (*yaml.RNode, error) {
+	if schema == nil || schema.Type == "string" {
+		return yaml.NewStringRNode(value), nil
+	}
+	if strings.TrimSpace(value) == "" {
+		return yaml.MakeNullNode(), nil
+	}
+
+	node, err := yaml.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if node.YNode().Kind != yaml.ScalarNode || node.YNode().ShortTag() != yaml.NodeTagString {
+		return node, nil
+	}
+
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	switch schema.Type {
+	case "array":
+		return yaml.NewListRNode(items...), nil
+	case "object":
+		if props, ok := schema.AdditionalProperties.(*jsonSchema); !ok || props.Type != "string" {
+			return node, nil
+		}
+		pairs, err := parseRegexpColors(items...)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(pairs))
+		for _, pair := range pairs {
+			m[pair.key] = pair.val
+		}
+		return yaml.NewMapRNode(&m), nil
+	}
+
+	return node, nil
+}
+
+// graphConfigMap returns the ConfigMap containing the given graph of the
+// given group, when the graph is split. The name of the ConfigMap of a group
+// is suffixed with the group, which is annotated on the ConfigMap as well.
+func graphConfigMap(spec *pluginSpec, group string, graph []byte) (*yaml.RNode, error) {
+	// Binary formats, e.g. png, are stored in the binary data of the
+	// ConfigMap, since its data may contain UTF-8 text only.
+	dataField, data := "data", string(graph)
+	if !utf8.Valid(graph) {
+		dataField, data = "binaryData", base64.StdEncoding.EncodeToString(graph)
+	}
+
+	name := cmp.Or(spec.ConfigMap.Name, defaultConfigMapName)
+	metadata := map[string]any{
+		"namespace": cmp.Or(spec.ConfigMap.Namespace, defaultConfigMapNamespace),
+	}
+	if group != "" {
+		name = fmt.Sprintf("%s-%s", name, configMapNameSuffix(group))
+		metadata["annotations"] = map[string]string{splitGroupAnnotation: group}
+	}
+	metadata["name"] = name
+	if len(spec.ConfigMap.Labels) > 0 {
+		metadata["labels"] = spec.ConfigMap.Labels
+	}
+
+	out, err := parser.NewResourceFactory().FromMapWithName(
+		name,
+		map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   metadata,
+			dataField: map[string]string{
+				cmp.Or(spec.ConfigMap.Key, spec.Format): data,
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &out.RNode, nil
+}
+
+// configMapNameSuffix returns the suffix of the ConfigMap name of the given
+// group, which is a valid DNS subdomain, e.g. the repo
+// https://github.com/org/repo becomes github.com-org-repo.
+func configMapNameSuffix(group string) string {
+	suffix := strings.ToLower(strings.ReplaceAll(splitFileName(group), "_", "-"))
+
+	return strings.Trim(suffix, "-.")
+}
+
+// splitOutputFile returns the path to the output file of the graph of the
+// given group, when the graph is split, e.g. graph-monitoring.dot.
+func splitOutputFile(path string, group string) string {
+	if group == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), splitFileName(group), ext)
+}
+
+// splitGroupError returns the given error prefixed with the group, when the
+// graph is split.
+func splitGroupError(group string, err error) error {
+	if group == "" {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", group, err)
+}
+
+// resourceRef returns the reference to the given resource, which is used by
+// the results of the plugin.
+func resourceRef(r *yaml.RNode) *yaml.ResourceIdentifier {
+	ref := &yaml.ResourceIdentifier{
+		TypeMeta: yaml.TypeMeta{
+			APIVersion: r.GetApiVersion(),
+			Kind:       r.GetKind(),
+		},
+		NameMeta: yaml.NameMeta{
+			Name:      r.GetName(),
+			Namespace: r.GetNamespace(),
+		},
+	}
+
+	return ref
+}
+
+// parseItems returns the resources parsed from the given items. Items, which
+// cannot be parsed, and local configs, which are ignored by kustomize, are
+// skipped and reported as results, so that the gaps in the graph are
+// explained.
+func parseItems(items []*yaml.RNode) ([]*resource.Resource, framework.Results) {
+	factory := parser.NewResourceFactory()
+	resources := make([]*resource.Resource, 0, len(items))
+	results := make(framework.Results, 0)
+	for _, item := range items {
+		if item.IsNilOrEmpty() {
+			continue
+		}
+
+		parsed, err := factory.ResourcesFromRNodes([]*yaml.RNode{item})
+		switch {
+		case err != nil:
+			result := &framework.Result{
+				Message:     fmt.Sprintf("resource skipped: %s", err),
+				Severity:    framework.Warning,
+				ResourceRef: resourceRef(item),
+			}
+			results = append(results, result)
+		case len(parsed) == 0:
+			result := &framework.Result{
+				Message:     "local config skipped",
+				Severity:    framework.Info,
+				ResourceRef: resourceRef(item),
+			}
+			results = append(results, result)
+		}
+		resources = append(resources, parsed...)
+	}
+
+	return resources, results
+}
+
+// pluginResults returns the results describing the graph of the given
+// resources, i.e. the number of resources kept and dropped by the filters of
+// the parser, and warnings about resources without origin, when the origins
+// are part of the graph.
+func pluginResults(p *parser.Parser, resources []*resource.Resource, origins bool) (framework.Results, error) {
+	explanations, err := p.Explain(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := 0
+	dropped := make(map[string]int)
+	for _, e := range explanations {
+		if e.Kept {
+			kept++
+			continue
+		}
+		for _, filter := range e.Filters {
+			dropped[filter]++
+		}
+	}
+
+	message := fmt.Sprintf("%d resources, %d kept and %d dropped", len(resources), kept, len(resources)-kept)
+	if len(dropped) > 0 {
+		filters := make([]string, 0, len(dropped))
+		for _, filter := range sortedKeys(dropped) {
+			filters = append(filters, fmt.Sprintf("%s (%d)", filter, dropped[filter]))
+		}
+		message += " by " + strings.Join(filters, ", ")
+	}
+	results := framework.Results{
+		{Message: message, Severity: framework.Info},
+	}
+
+	if !origins {
+		return results, nil
+	}
+
+	missing := make([]*resource.Resource, 0)
+	for _, r := range resources {
+		origin, err := r.GetOrigin()
+		if err != nil {
+			return nil, err
+		}
+		if origin == nil {
+			missing = append(missing, r)
+		}
+	}
+
+	// Report the build option, instead of each resource, when none of the
+	// resources have origin
+	if len(missing) > 0 && len(missing) == len(resources) {
+		result := &framework.Result{
+			Message:  "resources have no origin, enable the originAnnotations build option of the kustomization",
+			Severity: framework.Warning,
+		}
+		return append(results, result), nil
+	}
+	for _, r := range missing {
+		result := &framework.Result{
+			Message:     "resource has no origin",
+			Severity:    framework.Warning,
+			ResourceRef: resourceRef(&r.RNode),
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// annotateResources annotates the given resources, which are kept by the
+// filters of the parser, with their normalized provenance. The resources share
+// the nodes of the items they were parsed from, so the items are annotated as
+// well.
+func annotateResources(p *parser.Parser, resources []*resource.Resource) error {
+	kept, err := p.Filter(resources)
+	if err != nil {
+		return err
+	}
+	provenances, err := p.ProvenanceOfResources(kept)
+	if err != nil {
+		return err
+	}
+
+	for i, r := range kept {
+		annotations := r.GetAnnotations()
+		maps.Copy(annotations, provenances[i].Annotations())
+		if err := r.SetAnnotations(annotations); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // newPluginCommand returns the command for running kustomize-dot as KRM
 // Function plugin
 func newPluginCommand() *cli.Command {
 	cmd := &cli.Command{
-		Name:    "plugin",
-		Usage:   "run as KRM Function plugin",
-		Aliases: []string{"p"},
-		Action:  execPluginCommand,
+		Name:      "plugin",
+		Usage:     "run as KRM Function plugin",
+		Aliases:   []string{"p"},
+		ArgsUsage: "[CONFIG]",
+		Action:    execPluginCommand,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "legacy",
+				Usage:   "run as legacy exec plugin, which reads the config from the given file and the resources from stdin",
+				Value:   false,
+				EnvVars: []string{"KUSTOMIZE_DOT_LEGACY"},
+			},
+		},
 	}
 
 	return cmd
@@ -96,83 +950,236 @@ func newPluginCommand() *cli.Command {
 func execPluginCommand(ctx *cli.Context) error {
 	var config pluginConfig
 
-	fn := func(items []*yaml.RNode) ([]*yaml.RNode, error) {
-		opts := make([]parser.Option, 0)
+	// Config file
+	if err := applyConfigSpec(ctx, &config.Spec); err != nil {
+		return err
+	}
+	processor := newPluginProcessor(config)
 
-		// Layout direction
-		opts = append(opts, parser.WithLayoutDirection(parser.LayoutDirection(config.Spec.Layout)))
+	// Legacy exec plugin
+	if ctx.Bool("legacy") {
+		return execLegacyPlugin(ctx.Args().First(), processor, os.Stdin, os.Stdout)
+	}
 
-		// Highlight Resource Kinds
-		for kind, color := range config.Spec.HighlightKinds {
-			opts = append(opts, parser.WithHighlightKind(kind, color))
-		}
+	cmd := command.Build(processor, command.StandaloneDisabled, false)
 
-		// Highlight Namespaces
-		for ns, color := range config.Spec.HighlightNamespaces {
-			opts = append(opts, parser.WithHighlightNamespace(ns, color))
-		}
+	return cmd.Execute()
+}
+
+// newPluginProcessor returns the processor of the resource lists passed to the
+// plugin, which generates the graph of the resources using the given config.
+// The function config of the resource list is loaded on top of the config.
+func newPluginProcessor(config pluginConfig) framework.ResourceListProcessor {
+	// results contains the diagnostics of generating the graph, which are
+	// reported along with the resources.
+	var results framework.Results
 
-		// Drop Resource Kinds
-		for _, kind := range config.Spec.DropKinds {
-			opts = append(opts, parser.WithDropKind(kind))
+	fn := func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+		// Output format
+		format := parser.Format(config.Spec.Format)
+		render, err := parser.NewRenderer(format)
+		if err != nil {
+			return nil, err
 		}
 
-		// Drop Namespaces
-		for _, ns := range config.Spec.DropNamespaces {
-			opts = append(opts, parser.WithDropNamespace(ns))
+		// Graph options, which are shared with the generate command
+		opts, err := graphOptions(newSpecOptions(&config.Spec))
+		if err != nil {
+			return nil, err
 		}
 
-		// Keep Resource Kinds
-		for _, kind := range config.Spec.KeepKinds {
-			opts = append(opts, parser.WithKeepKind(kind))
+		// Filter rules
+		for i, rule := range config.Spec.Rules {
+			if err := rule.Validate(); err != nil {
+				return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+			}
 		}
+		opts = append(opts, parser.WithRules(config.Spec.Rules...))
 
-		// Keep Namespaces
-		for _, ns := range config.Spec.KeepNamespaces {
-			opts = append(opts, parser.WithKeepNamespace(ns))
+		// Style rules
+		for i, style := range config.Spec.Styles {
+			if err := style.Validate(); err != nil {
+				return nil, fmt.Errorf("style #%d: %w", i+1, err)
+			}
 		}
+		opts = append(opts, parser.WithStyles(config.Spec.Styles...))
 
-		// Parse resources and generate the graph
-		resources, err := parser.ResourcesFromRNodes(items)
+		// Selected resources
+		selected, err := config.Spec.selectItems(items)
 		if err != nil {
-			return nil, fmt.Errorf("cannot parse resources: %w", err)
+			return nil, err
 		}
 
+		// Parse resources and generate the graph
+		resources, skipped := parseItems(selected)
 		p := parser.New(opts...)
-		g, err := p.Parse(resources)
+
+		// Results
+		results, err = pluginResults(p, resources, !config.Spec.NoOrigins)
 		if err != nil {
-			return nil, fmt.Errorf("cannot generate graph: %w", err)
+			return nil, err
 		}
+		results = append(skipped, results...)
 
-		var buf bytes.Buffer
-		if err := graph.WriteDot(g, &buf); err != nil {
-			return nil, err
+		// Provenance annotations
+		if config.Spec.Mode == pluginModeAnnotate {
+			if err := annotateResources(p, resources); err != nil {
+				return nil, err
+			}
+			return items, nil
 		}
 
-		// Return the transformed resources as a ConfigMap
-		out, err := parser.NewResourceFactory().FromMapWithName(
-			"kustomize-dot",
-			map[string]any{
-				"apiVersion": "v1",
-				"kind":       "ConfigMap",
-				"metadata": map[string]string{
-					"name":      "kustomize-dot",
-					"namespace": "default",
-				},
-				"data": map[string]string{
-					"dot": buf.String(),
-				},
-			},
-		)
+		// Split graph
+		groups := map[string][]*resource.Resource{"": resources}
+		if config.Spec.SplitBy != "" {
+			splitBy, err := getSplitBy(config.Spec.SplitBy)
+			if err != nil {
+				return nil, err
+			}
+			groups, err = parser.Split(resources, splitBy)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if config.Spec.OutputFile == "-" {
+			return nil, errPluginOutputStdout
+		}
+
+		configMaps := make([]*yaml.RNode, 0, len(groups))
+		for _, group := range sortedKeys(groups) {
+			g, err := parser.New(opts...).ParseGraph(groups[group])
+			if err != nil {
+				return nil, fmt.Errorf("cannot generate graph: %w", splitGroupError(group, err))
+			}
+
+			// Output file
+			if config.Spec.OutputFile != "" {
+				path := splitOutputFile(config.Spec.OutputFile, group)
+				if err := writeOutput(path, func(w io.Writer) error { return render(g, w) }); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := render(g, &buf); err != nil {
+				return nil, err
+			}
+			out, err := graphConfigMap(&config.Spec, group, buf.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			configMaps = append(configMaps, out)
+		}
+
+		switch config.Spec.Mode {
+		case pluginModeTransformer:
+			return append(items, configMaps...), nil
+		default:
+			return configMaps, nil
+		}
+	}
+
+	// The function config is validated before loading it, so that the
+	// problems are reported as results, which refer to the invalid fields.
+	processor := framework.ResourceListProcessorFunc(func(rl *framework.ResourceList) error {
+		if rl.FunctionConfig == nil {
+			if err := config.Default(); err != nil {
+				return err
+			}
+			if err := rl.Filter(kio.FilterFunc(fn)); err != nil {
+				return err
+			}
+			rl.Results = append(rl.Results, results...)
+			return nil
+		}
+
+		// ConfigMap function config
+		functionConfig := rl.FunctionConfig
+		isConfigMap := functionConfig.GetApiVersion() == "v1" && functionConfig.GetKind() == "ConfigMap"
+		if isConfigMap {
+			converted, err := configMapFunctionConfig(functionConfig)
+			if err != nil {
+				return err
+			}
+			functionConfig = converted
+		}
+
+		problems, err := validatePluginConfig(functionConfig)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if len(problems) > 0 {
+			// Refer to the data keys of the ConfigMap
+			if isConfigMap {
+				for _, problem := range problems {
+					problem.Field.Path = "data" + strings.TrimPrefix(problem.Field.Path, "spec")
+				}
+			}
+			rl.Results = append(rl.Results, problems...)
+			return problems
+		}
+
+		if err := framework.LoadFunctionConfig(functionConfig, &config); err != nil {
+			return fmt.Errorf("loading function config: %w", err)
 		}
 
-		return []*yaml.RNode{&out.RNode}, nil
+		if err := rl.Filter(kio.FilterFunc(fn)); err != nil {
+			return err
+		}
+		rl.Results = append(rl.Results, results...)
+
+		return nil
+	})
+
+	return processor
+}
+
+// execLegacyPlugin runs the given processor as a legacy exec plugin, which
+// is invoked by kustomize with the path to the plugin config as the first
+// argument, and the resources as a YAML stream on stdin, i.e. the given
+// reader. The resources are written as a YAML stream to stdout, i.e. the given
+// writer, and the results are printed as messages.
+//
+// See [1] for more details about exec plugins.
+//
+// [1]: https://kubectl.docs.kubernetes.io/guides/extending_kustomize/exec_plugins/
+func execLegacyPlugin(path string, processor framework.ResourceListProcessor, in io.Reader, out io.Writer) error {
+	if path == "" {
+		return errMissingLegacyConfig
 	}
 
-	processor := framework.SimpleProcessor{Config: &config, Filter: kio.FilterFunc(fn)}
-	cmd := command.Build(processor, command.StandaloneDisabled, false)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	functionConfig, err := yaml.Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("parsing plugin config: %w", err)
+	}
 
-	return cmd.Execute()
+	reader := &kio.ByteReader{
+		Reader:                in,
+		OmitReaderAnnotations: true,
+	}
+	items, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	rl := &framework.ResourceList{
+		Items:          items,
+		FunctionConfig: functionConfig,
+	}
+	if err := processor.Process(rl); err != nil {
+		return err
+	}
+	for _, result := range rl.Results {
+		printMessage("%s", result)
+	}
+
+	writer := &kio.ByteWriter{Writer: out}
+
+	return writer.Write(rl.Items)
 }
@@ -0,0 +1,948 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// otherNamespace is a resource, which is added to the hello world resources,
+// so that they span more than one namespace.
+const otherNamespace = `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: the-other-map
+  namespace: other
+`
+
+func newTestItems(t *testing.T, data string) []*yaml.RNode {
+	t.Helper()
+
+	reader := &kio.ByteReader{
+		Reader:                strings.NewReader(data),
+		OmitReaderAnnotations: true,
+	}
+	items, err := reader.Read()
+	if err != nil {
+		t.Fatalf("reading items failed: %s", err)
+	}
+
+	return items
+}
+
+func newTestContext(t *testing.T, cmd *cli.Command, args ...string) *cli.Context {
+	t.Helper()
+
+	set := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	for _, f := range cmd.Flags {
+		if err := f.Apply(set); err != nil {
+			t.Fatalf("applying flag failed: %s", err)
+		}
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatalf("parsing flags failed: %s", err)
+	}
+	ctx := cli.NewContext(cli.NewApp(), set, nil)
+	ctx.Command = cmd
+
+	return ctx
+}
+
+func itemNames(items []*yaml.RNode) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.GetKind()+"/"+item.GetName())
+	}
+
+	return names
+}
+
+func TestSpecSelectItems(t *testing.T) {
+	type testCase struct {
+		desc string
+		spec pluginSpec
+		want []string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "no selector",
+			spec: pluginSpec{},
+			want: []string{"ConfigMap/the-map", "Service/the-service", "Deployment/the-deployment"},
+		},
+		{
+			desc: "select kinds",
+			spec: pluginSpec{
+				Selector: &pluginSelector{Kinds: []string{"Deployment"}},
+			},
+			want: []string{"Deployment/the-deployment"},
+		},
+		{
+			desc: "exclude kinds",
+			spec: pluginSpec{
+				Exclude: &pluginSelector{Kinds: []string{"ConfigMap"}},
+			},
+			want: []string{"Service/the-service", "Deployment/the-deployment"},
+		},
+		{
+			desc: "select labels and exclude names",
+			spec: pluginSpec{
+				Selector: &pluginSelector{Labels: map[string]string{"app": "hello"}},
+				Exclude:  &pluginSelector{Names: []string{"the-service"}},
+			},
+			want: []string{"ConfigMap/the-map", "Deployment/the-deployment"},
+		},
+		{
+			desc: "select nothing",
+			spec: pluginSpec{
+				Selector: &pluginSelector{Namespaces: []string{"monitoring"}},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			items := newTestItems(t, fixtures.HelloWorld)
+			selected, err := tc.spec.selectItems(items)
+			if err != nil {
+				t.Fatalf("selecting items failed: %s", err)
+			}
+			if got := itemNames(selected); !slices.Equal(got, tc.want) {
+				t.Fatalf("want items %v, got %v", tc.want, got)
+			}
+			if len(items) != 3 {
+				t.Fatalf("want the items to be left unchanged, got %d items", len(items))
+			}
+		})
+	}
+}
+
+func TestConfigMapFunctionConfig(t *testing.T) {
+	type testCase struct {
+		desc    string
+		data    map[string]string
+		want    pluginSpec
+		wantErr error
+	}
+
+	maxDepth := 2
+	testCases := []testCase{
+		{
+			desc: "strings",
+			data: map[string]string{"layout": "TB", "theme": "dark"},
+			want: pluginSpec{Layout: "TB", Theme: "dark"},
+		},
+		{
+			desc: "comma-separated lists",
+			data: map[string]string{"dropKinds": "Secret, ConfigMap,"},
+			want: pluginSpec{DropKinds: []string{"Secret", "ConfigMap"}},
+		},
+		{
+			desc: "YAML lists",
+			data: map[string]string{"keepNamespaces": "[default, monitoring]"},
+			want: pluginSpec{KeepNamespaces: []string{"default", "monitoring"}},
+		},
+		{
+			desc: "comma-separated key/value pairs",
+			data: map[string]string{"highlightKinds": "service=yellow,deployment=red"},
+			want: pluginSpec{HighlightKinds: map[string]string{"service": "yellow", "deployment": "red"}},
+		},
+		{
+			desc: "booleans and numbers",
+			data: map[string]string{"detail": "true", "maxDepth": "2", "fontSize": "12.5"},
+			want: pluginSpec{Detail: true, MaxDepth: &maxDepth, FontSize: 12.5},
+		},
+		{
+			desc: "empty values",
+			data: map[string]string{"dropKinds": " "},
+			want: pluginSpec{},
+		},
+		{
+			desc:    "invalid key/value pair",
+			data:    map[string]string{"highlightKinds": "service"},
+			wantErr: errInvalidConfigValue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cm := yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: kustomize-dot\n")
+			cm.SetDataMap(tc.data)
+
+			functionConfig, err := configMapFunctionConfig(cm)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if functionConfig.Field("data") != nil {
+				t.Fatal("want the data to be replaced by the spec")
+			}
+
+			var config pluginConfig
+			if err := functionConfig.Field("spec").Value.YNode().Decode(&config.Spec); err != nil {
+				t.Fatalf("decoding spec failed: %s", err)
+			}
+			if !reflect.DeepEqual(config.Spec, tc.want) {
+				t.Fatalf("want spec %+v, got %+v", tc.want, config.Spec)
+			}
+		})
+	}
+}
+
+func TestValidatePluginConfig(t *testing.T) {
+	type testCase struct {
+		desc      string
+		config    string
+		wantPaths []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "valid config",
+			config:    "spec:\n  layout: TB\n  dropKinds: [Secret]\n",
+			wantPaths: []string{},
+		},
+		{
+			desc:      "unsupported value",
+			config:    "spec:\n  layout: XY\n",
+			wantPaths: []string{"spec.layout"},
+		},
+		{
+			desc:      "invalid types",
+			config:    "spec:\n  maxDepth: deep\n  dropKinds: Secret\n",
+			wantPaths: []string{"spec.dropKinds", "spec.maxDepth"},
+		},
+		{
+			desc:      "invalid list item",
+			config:    "spec:\n  highlightKinds:\n    Service: not-a-color\n  rules:\n    - action: keep\n      kinds: Secret\n",
+			wantPaths: []string{"spec.highlightKinds.Service", "spec.rules[0].kinds"},
+		},
+		{
+			desc:      "unknown field",
+			config:    "spec:\n  layouts: TB\n",
+			wantPaths: []string{"spec.layouts"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			functionConfig := yaml.MustParse("apiVersion: dnaeon.github.io/v1\nkind: KustomizeDot\nmetadata:\n  name: kustomize-dot\n" + tc.config)
+			results, err := validatePluginConfig(functionConfig)
+			if err != nil {
+				t.Fatalf("validating config failed: %s", err)
+			}
+
+			paths := make([]string, 0, len(results))
+			for _, result := range results {
+				if result.Severity != framework.Error {
+					t.Fatalf("want severity %s, got %s", framework.Error, result.Severity)
+				}
+				if result.ResourceRef.Kind != "KustomizeDot" || result.ResourceRef.Name != "kustomize-dot" {
+					t.Fatalf("want result referring to the function config, got %v", result.ResourceRef)
+				}
+				paths = append(paths, result.Field.Path)
+			}
+			if !slices.Equal(paths, tc.wantPaths) {
+				t.Fatalf("want paths %v, got %v", tc.wantPaths, paths)
+			}
+		})
+	}
+}
+
+func TestParseItems(t *testing.T) {
+	data := fixtures.HelloWorld + `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: the-local-config
+  annotations:
+    config.kubernetes.io/local-config: "true"
+`
+	items := newTestItems(t, data)
+	items = append(items, nil)
+
+	resources, results := parseItems(items)
+	if len(resources) != 3 {
+		t.Fatalf("want 3 resources, got %d", len(resources))
+	}
+	if len(results) != 1 {
+		t.Fatalf("want 1 result, got %d: %v", len(results), results)
+	}
+
+	result := results[0]
+	if result.Message != "local config skipped" || result.Severity != framework.Info {
+		t.Fatalf("want local config skipped info, got %v", result)
+	}
+	if result.ResourceRef.Name != "the-local-config" {
+		t.Fatalf("want result referring to the-local-config, got %v", result.ResourceRef)
+	}
+}
+
+func TestPluginResults(t *testing.T) {
+	type testCase struct {
+		desc         string
+		data         string
+		opts         []parser.Option
+		origins      bool
+		wantMessages []string
+	}
+
+	noOrigin := `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: the-secret
+  namespace: default
+`
+
+	testCases := []testCase{
+		{
+			desc:         "all kept",
+			data:         fixtures.HelloWorld,
+			origins:      true,
+			wantMessages: []string{"3 resources, 3 kept and 0 dropped"},
+		},
+		{
+			desc:         "dropped by filter",
+			data:         fixtures.HelloWorld,
+			opts:         []parser.Option{parser.WithDropKind("ConfigMap")},
+			origins:      true,
+			wantMessages: []string{"3 resources, 2 kept and 1 dropped by drop-kind (1)"},
+		},
+		{
+			desc:    "resource without origin",
+			data:    fixtures.HelloWorld + noOrigin,
+			origins: true,
+			wantMessages: []string{
+				"4 resources, 4 kept and 0 dropped",
+				"resource has no origin",
+			},
+		},
+		{
+			desc:    "no resource has origin",
+			data:    noOrigin,
+			origins: true,
+			wantMessages: []string{
+				"1 resources, 1 kept and 0 dropped",
+				"resources have no origin, enable the originAnnotations build option of the kustomization",
+			},
+		},
+		{
+			desc:         "origins not graphed",
+			data:         noOrigin,
+			origins:      false,
+			wantMessages: []string{"1 resources, 1 kept and 0 dropped"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			resources, err := parser.ResourcesFromBytes([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("parsing resources failed: %s", err)
+			}
+
+			results, err := pluginResults(parser.New(tc.opts...), resources, tc.origins)
+			if err != nil {
+				t.Fatalf("generating results failed: %s", err)
+			}
+
+			messages := make([]string, 0, len(results))
+			for _, result := range results {
+				messages = append(messages, result.Message)
+			}
+			if !slices.Equal(messages, tc.wantMessages) {
+				t.Fatalf("want messages %q, got %q", tc.wantMessages, messages)
+			}
+		})
+	}
+}
+
+func TestGraphConfigMap(t *testing.T) {
+	type testCase struct {
+		desc      string
+		spec      pluginSpec
+		group     string
+		graph     []byte
+		wantName  string
+		wantField string
+		wantKey   string
+		wantValue string
+	}
+
+	binary := []byte{0x89, 'P', 'N', 'G', 0xff}
+	testCases := []testCase{
+		{
+			desc:      "defaults",
+			spec:      pluginSpec{Format: "dot"},
+			graph:     []byte("digraph {}"),
+			wantName:  "kustomize-dot",
+			wantField: "data",
+			wantKey:   "dot",
+			wantValue: "digraph {}",
+		},
+		{
+			desc: "configured name and key",
+			spec: pluginSpec{
+				Format:    "dot",
+				ConfigMap: pluginConfigMap{Name: "graph", Namespace: "monitoring", Key: "graph.dot"},
+			},
+			graph:     []byte("digraph {}"),
+			wantName:  "graph",
+			wantField: "data",
+			wantKey:   "graph.dot",
+			wantValue: "digraph {}",
+		},
+		{
+			desc:      "split group",
+			spec:      pluginSpec{Format: "dot"},
+			group:     "https://github.com/Org/Repo_Name",
+			graph:     []byte("digraph {}"),
+			wantName:  "kustomize-dot-github.com-org-repo-name",
+			wantField: "data",
+			wantKey:   "dot",
+			wantValue: "digraph {}",
+		},
+		{
+			desc:      "binary data",
+			spec:      pluginSpec{Format: "png"},
+			graph:     binary,
+			wantName:  "kustomize-dot",
+			wantField: "binaryData",
+			wantKey:   "png",
+			wantValue: base64.StdEncoding.EncodeToString(binary),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cm, err := graphConfigMap(&tc.spec, tc.group, tc.graph)
+			if err != nil {
+				t.Fatalf("creating ConfigMap failed: %s", err)
+			}
+			if cm.GetName() != tc.wantName {
+				t.Fatalf("want name %q, got %q", tc.wantName, cm.GetName())
+			}
+			wantNamespace := tc.spec.ConfigMap.Namespace
+			if wantNamespace == "" {
+				wantNamespace = defaultConfigMapNamespace
+			}
+			if cm.GetNamespace() != wantNamespace {
+				t.Fatalf("want namespace %q, got %q", wantNamespace, cm.GetNamespace())
+			}
+
+			data := cm.GetDataMap()
+			if tc.wantField == "binaryData" {
+				data = cm.GetBinaryDataMap()
+			}
+			if value := data[tc.wantKey]; value != tc.wantValue {
+				t.Fatalf("want %s value %q, got %q", tc.wantField, tc.wantValue, value)
+			}
+
+			if got := cm.GetAnnotations()[splitGroupAnnotation]; got != tc.group {
+				t.Fatalf("want split group annotation %q, got %q", tc.group, got)
+			}
+		})
+	}
+}
+
+func TestPluginProcessor(t *testing.T) {
+	type testCase struct {
+		desc           string
+		functionConfig string
+		data           string
+		wantItems      []string
+		wantErr        bool
+		wantPaths      []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "without function config",
+			data:      fixtures.HelloWorld,
+			wantItems: []string{"ConfigMap/kustomize-dot"},
+		},
+		{
+			desc:           "generator mode",
+			functionConfig: "spec:\n  mode: generator\n",
+			data:           fixtures.HelloWorld,
+			wantItems:      []string{"ConfigMap/kustomize-dot"},
+		},
+		{
+			desc:           "transformer mode",
+			functionConfig: "spec:\n  mode: transformer\n",
+			data:           fixtures.HelloWorld,
+			wantItems: []string{
+				"ConfigMap/the-map",
+				"Service/the-service",
+				"Deployment/the-deployment",
+				"ConfigMap/kustomize-dot",
+			},
+		},
+		{
+			desc:           "passthrough",
+			functionConfig: "spec:\n  passthrough: true\n",
+			data:           fixtures.HelloWorld,
+			wantItems: []string{
+				"ConfigMap/the-map",
+				"Service/the-service",
+				"Deployment/the-deployment",
+				"ConfigMap/kustomize-dot",
+			},
+		},
+		{
+			desc:           "split by namespace",
+			functionConfig: "spec:\n  splitBy: namespace\n",
+			data:           fixtures.HelloWorld + otherNamespace,
+			wantItems: []string{
+				"ConfigMap/kustomize-dot-default",
+				"ConfigMap/kustomize-dot-other",
+			},
+		},
+		{
+			desc:           "invalid config",
+			functionConfig: "spec:\n  layout: XY\n  maxDepth: deep\n",
+			data:           fixtures.HelloWorld,
+			wantErr:        true,
+			wantPaths:      []string{"spec.layout", "spec.maxDepth"},
+		},
+		{
+			desc:           "conflicting filters",
+			functionConfig: "spec:\n  keepKinds: [Service]\n  dropKinds: [service]\n",
+			data:           fixtures.HelloWorld,
+			wantErr:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rl := &framework.ResourceList{Items: newTestItems(t, tc.data)}
+			if tc.functionConfig != "" {
+				rl.FunctionConfig = yaml.MustParse("apiVersion: dnaeon.github.io/v1\nkind: KustomizeDot\nmetadata:\n  name: kustomize-dot\n" + tc.functionConfig)
+			}
+
+			err := newPluginProcessor(pluginConfig{}).Process(rl)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+
+			paths := make([]string, 0)
+			for _, result := range rl.Results {
+				if result.Field != nil {
+					paths = append(paths, result.Field.Path)
+				}
+			}
+			if !slices.Equal(paths, tc.wantPaths) && len(tc.wantPaths) > 0 {
+				t.Fatalf("want paths %v, got %v", tc.wantPaths, paths)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if got := itemNames(rl.Items); !slices.Equal(got, tc.wantItems) {
+				t.Fatalf("want items %v, got %v", tc.wantItems, got)
+			}
+		})
+	}
+}
+
+func TestPluginProcessorConfigMap(t *testing.T) {
+	functionConfig := yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: kustomize-dot\n")
+
+	// Invalid data keys are reported as results referring to the data
+	functionConfig.SetDataMap(map[string]string{"maxDepth": "deep"})
+	rl := &framework.ResourceList{
+		Items:          newTestItems(t, fixtures.HelloWorld),
+		FunctionConfig: functionConfig,
+	}
+	if err := newPluginProcessor(pluginConfig{}).Process(rl); err == nil {
+		t.Fatal("want error for invalid data key")
+	}
+	if len(rl.Results) != 1 || rl.Results[0].Field.Path != "data.maxDepth" {
+		t.Fatalf("want result for data.maxDepth, got %v", rl.Results)
+	}
+
+	functionConfig.SetDataMap(map[string]string{"format": "json", "keepKinds": "Deployment,Service"})
+	rl = &framework.ResourceList{
+		Items:          newTestItems(t, fixtures.HelloWorld),
+		FunctionConfig: functionConfig,
+	}
+	if err := newPluginProcessor(pluginConfig{}).Process(rl); err != nil {
+		t.Fatalf("processing resources failed: %s", err)
+	}
+	if len(rl.Items) != 1 {
+		t.Fatalf("want 1 item, got %d", len(rl.Items))
+	}
+	graph, err := rl.Items[0].GetString("data.json")
+	if err != nil {
+		t.Fatalf("getting graph failed: %s", err)
+	}
+	if strings.Contains(graph, "the-map") || !strings.Contains(graph, "the-deployment") {
+		t.Fatalf("want graph of the kept resources, got %s", graph)
+	}
+	if rl.Results[0].Message != "3 resources, 2 kept and 1 dropped by keep-kind (1)" {
+		t.Fatalf("want summary of the kept resources, got %q", rl.Results[0].Message)
+	}
+}
+
+func TestPluginProcessorAnnotate(t *testing.T) {
+	functionConfig := yaml.MustParse(`apiVersion: dnaeon.github.io/v1
+kind: KustomizeDot
+metadata:
+  name: kustomize-dot
+spec:
+  mode: annotate
+  dropKinds: [ConfigMap]
+`)
+	rl := &framework.ResourceList{
+		Items:          newTestItems(t, fixtures.HelloWorld),
+		FunctionConfig: functionConfig,
+	}
+	if err := newPluginProcessor(pluginConfig{}).Process(rl); err != nil {
+		t.Fatalf("processing resources failed: %s", err)
+	}
+
+	want := []string{"ConfigMap/the-map", "Service/the-service", "Deployment/the-deployment"}
+	if got := itemNames(rl.Items); !slices.Equal(got, want) {
+		t.Fatalf("want items %v, got %v", want, got)
+	}
+	for _, item := range rl.Items {
+		path, annotated := item.GetAnnotations()[parser.AnnotationOriginPath]
+		if annotated != (item.GetKind() != "ConfigMap") {
+			t.Fatalf("want only the kept resources to be annotated, got %s annotated %t", item.GetName(), annotated)
+		}
+		if annotated && !strings.HasPrefix(path, "examples/helloWorld/") {
+			t.Fatalf("want origin path annotation, got %q", path)
+		}
+	}
+}
+
+func TestPluginProcessorOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	functionConfig := yaml.MustParse(`apiVersion: dnaeon.github.io/v1
+kind: KustomizeDot
+metadata:
+  name: kustomize-dot
+spec:
+  splitBy: namespace
+  outputFile: ` + filepath.Join(dir, "graph.dot") + `
+`)
+	rl := &framework.ResourceList{
+		Items:          newTestItems(t, fixtures.HelloWorld+otherNamespace),
+		FunctionConfig: functionConfig,
+	}
+	if err := newPluginProcessor(pluginConfig{}).Process(rl); err != nil {
+		t.Fatalf("processing resources failed: %s", err)
+	}
+	if len(rl.Items) != 0 {
+		t.Fatalf("want no ConfigMap, when writing the output file, got %v", itemNames(rl.Items))
+	}
+
+	for _, name := range []string{"graph-default.dot", "graph-other.dot"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("reading %s failed: %s", name, err)
+		}
+		if !strings.Contains(string(data), "digraph") {
+			t.Fatalf("want dot graph in %s, got %q", name, data)
+		}
+	}
+}
+
+func TestExecLegacyPlugin(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	config := `apiVersion: dnaeon.github.io/v1
+kind: KustomizeDot
+metadata:
+  name: kustomize-dot
+spec:
+  mode: transformer
+  keepKinds: [Deployment]
+`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("writing config failed: %s", err)
+	}
+
+	var out bytes.Buffer
+	processor := newPluginProcessor(pluginConfig{})
+	if err := execLegacyPlugin(path, processor, strings.NewReader(fixtures.HelloWorld), &out); err != nil {
+		t.Fatalf("running legacy plugin failed: %s", err)
+	}
+
+	items := newTestItems(t, out.String())
+	want := []string{
+		"ConfigMap/the-map",
+		"Service/the-service",
+		"Deployment/the-deployment",
+		"ConfigMap/kustomize-dot",
+	}
+	if got := itemNames(items); !slices.Equal(got, want) {
+		t.Fatalf("want items %v, got %v", want, got)
+	}
+
+	if err := execLegacyPlugin("", processor, strings.NewReader(""), &out); !errors.Is(err, errMissingLegacyConfig) {
+		t.Fatalf("want error %v, got %v", errMissingLegacyConfig, err)
+	}
+	if err := execLegacyPlugin(filepath.Join(dir, "missing.yaml"), processor, strings.NewReader(""), &out); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want error %v, got %v", os.ErrNotExist, err)
+	}
+}
+
+func TestSpecOptions(t *testing.T) {
+	maxDepth := 0
+	spec := &pluginSpec{
+		Layout:         "TB",
+		Detail:         true,
+		MaxDepth:       &maxDepth,
+		FontSize:       12.5,
+		DropKinds:      []string{"Secret"},
+		HighlightKinds: map[string]string{"Service": "yellow", "Deployment": "red"},
+		NodeAttributes: map[string]map[string]string{
+			"Deployment": {"shape": "component", "style": "filled, rounded"},
+		},
+	}
+	so := newSpecOptions(spec)
+
+	if got := so.String("layout"); got != "TB" {
+		t.Fatalf("want layout TB, got %q", got)
+	}
+	if got := so.String("theme"); got != parser.ThemeDefault.String() {
+		t.Fatalf("want default theme, got %q", got)
+	}
+	if got := so.Path("style-file"); got != "" {
+		t.Fatalf("want no style file, got %q", got)
+	}
+	if !so.Bool("detail") || so.Bool("tooltips") {
+		t.Fatal("want detail without tooltips")
+	}
+	if got := so.Int("max-depth"); got != 0 {
+		t.Fatalf("want max depth 0, got %d", got)
+	}
+	if got := so.Int("depth"); got != 1 {
+		t.Fatalf("want default focus depth 1, got %d", got)
+	}
+	if got := so.Float64("font-size"); got != 12.5 {
+		t.Fatalf("want font size 12.5, got %f", got)
+	}
+
+	want := []string{"Secret"}
+	if got := so.StringSlice("drop-kind"); !slices.Equal(got, want) {
+		t.Fatalf("want drop kinds %v, got %v", want, got)
+	}
+	want = []string{"Deployment=red", "Service=yellow"}
+	if got := so.StringSlice("highlight-kind"); !slices.Equal(got, want) {
+		t.Fatalf("want highlight kinds %v, got %v", want, got)
+	}
+	want = []string{"Deployment:shape=component,style=filled, rounded"}
+	if got := so.StringSlice("node-attr"); !slices.Equal(got, want) {
+		t.Fatalf("want node attributes %v, got %v", want, got)
+	}
+
+	if got := so.optionName("keep-kind"); got != "keepKinds" {
+		t.Fatalf("want option name keepKinds, got %q", got)
+	}
+
+	spec.MaxDepth = nil
+	if got := so.Int("max-depth"); got != -1 {
+		t.Fatalf("want default max depth -1, got %d", got)
+	}
+}
+
+func TestGraphOptions(t *testing.T) {
+	type testCase struct {
+		desc    string
+		spec    pluginSpec
+		wantErr error
+		wantMsg string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "valid spec",
+			spec: pluginSpec{
+				Layout:             "TB",
+				HighlightNameRegex: map[string]string{"the-.*": "red"},
+				NodeAttributes:     map[string]map[string]string{"Service": {"shape": "box"}},
+				GraphAttributes:    map[string]string{"size": "7.5,10"},
+			},
+		},
+		{
+			desc:    "unsupported layout",
+			spec:    pluginSpec{Layout: "XY"},
+			wantErr: errUnsupportedLayout,
+		},
+		{
+			desc:    "conflicting graph modes",
+			spec:    pluginSpec{OriginsOnly: true, NoOrigins: true},
+			wantErr: errConflictingGraphModes,
+			wantMsg: "originsOnly and noOrigins",
+		},
+		{
+			desc:    "conflicting kinds",
+			spec:    pluginSpec{KeepKinds: []string{"Secret"}, DropKinds: []string{"secret"}},
+			wantErr: errConflictingFilters,
+			wantMsg: "keepKinds and dropKinds",
+		},
+		{
+			desc:    "conflicting cluster scope",
+			spec:    pluginSpec{DropClusterScoped: true, KeepClusterScopedOnly: true},
+			wantErr: errConflictingFilters,
+			wantMsg: "dropClusterScoped and keepClusterScopedOnly",
+		},
+		{
+			desc:    "invalid regular expression",
+			spec:    pluginSpec{DropNameRegex: []string{"("}},
+			wantErr: errInvalidRegexp,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := graphOptions(newSpecOptions(&tc.spec))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantMsg != "" && !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Fatalf("want error naming %q, got %q", tc.wantMsg, err)
+			}
+		})
+	}
+}
+
+func TestContextOptions(t *testing.T) {
+	ctx := newTestContext(t, newGenerateCommand(), "--keep-kind", "Secret", "--drop-kind", "secret")
+	_, err := graphOptions(contextOptions{ctx})
+	if !errors.Is(err, errConflictingFilters) {
+		t.Fatalf("want error %v, got %v", errConflictingFilters, err)
+	}
+	if !strings.Contains(err.Error(), "--keep-kind and --drop-kind") {
+		t.Fatalf("want error naming the flags, got %q", err)
+	}
+
+	ctx = newTestContext(t, newGenerateCommand(), "--origins-only", "--no-origins")
+	if _, err := graphOptions(contextOptions{ctx}); !errors.Is(err, errConflictingGraphModes) || !strings.Contains(err.Error(), "--origins-only and --no-origins") {
+		t.Fatalf("want error naming the flags, got %v", err)
+	}
+
+	ctx = newTestContext(t, newGenerateCommand(), "--highlight-name-regex", "the-.*=red", "--node-attr", "Service:shape=box,style=filled,rounded")
+	if _, err := graphOptions(contextOptions{ctx}); err != nil {
+		t.Fatalf("building options failed: %s", err)
+	}
+}
+
+func TestOrphanOptions(t *testing.T) {
+	type testCase struct {
+		desc      string
+		spec      pluginSpec
+		wantColor string
+	}
+
+	pdb := `
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: orphan
+  namespace: default
+spec:
+  selector:
+    matchLabels:
+      app: nothing
+`
+
+	testCases := []testCase{
+		{
+			desc:      "orphans not marked",
+			spec:      pluginSpec{},
+			wantColor: "",
+		},
+		{
+			desc:      "orphans marked",
+			spec:      pluginSpec{MarkOrphans: true},
+			wantColor: "red",
+		},
+		{
+			desc:      "orphan attributes imply marking",
+			spec:      pluginSpec{OrphanAttributes: map[string]string{"color": "orange"}},
+			wantColor: "orange",
+		},
+		{
+			desc: "highlight overrides the orphan style",
+			spec: pluginSpec{
+				MarkOrphans:    true,
+				HighlightKinds: map[string]string{"PodDisruptionBudget": "yellow"},
+			},
+			wantColor: "yellow",
+		},
+	}
+
+	resources, err := parser.ResourcesFromBytes([]byte(fixtures.HelloWorld + pdb))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts, err := graphOptions(newSpecOptions(&tc.spec))
+			if err != nil {
+				t.Fatalf("building options failed: %s", err)
+			}
+			g, err := parser.New(opts...).ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("parsing graph failed: %s", err)
+			}
+
+			v := g.GetVertex("default/poddisruptionbudget/orphan")
+			if got := v.DotAttributes["color"]; got != tc.wantColor {
+				t.Fatalf("want color %q, got %q", tc.wantColor, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// errUnknownPreset is returned when the requested preset is not defined in
+// the config file.
+var errUnknownPreset = errors.New("unknown preset")
+
+// errInvalidPreset is returned when a preset from the config file is not a
+// mapping of filters to values.
+var errInvalidPreset = errors.New("invalid preset")
+
+// presetsConfigKey is the config key, which contains the named filter
+// presets.
+const presetsConfigKey = "presets"
+
+// presetFlagName returns the name of the filter flag, which corresponds to
+// the given preset key. The key is either the name of the flag, e.g.
+// keep-namespace, or the name of the plugin spec field, e.g. keepNamespaces.
+func presetFlagName(key string) (string, bool) {
+	if slices.Contains(filterFlags, key) {
+		return key, true
+	}
+
+	for _, name := range filterFlags {
+		if field, ok := configSpecField(name); ok && strings.EqualFold(field.Name, key) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// loadPresets returns the filter presets from the given config, which map the
+// names of the filter flags to their values.
+func loadPresets(config map[string]any) (map[string]map[string]any, error) {
+	presets := make(map[string]map[string]any)
+	if config[presetsConfigKey] == nil {
+		return presets, nil
+	}
+
+	items, ok := config[presetsConfigKey].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a mapping of preset names", errInvalidPreset, presetsConfigKey)
+	}
+	for name, item := range items {
+		values, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a mapping of filters", errInvalidPreset, name)
+		}
+
+		preset := make(map[string]any, len(values))
+		for key, value := range values {
+			flagName, ok := presetFlagName(key)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: %s is not a filter", errInvalidPreset, name, key)
+			}
+			preset[flagName] = value
+		}
+		presets[name] = preset
+	}
+
+	return presets, nil
+}
+
+// mergePresets returns the values of the presets with the given names. The
+// lists of values of the same filter are concatenated, so that the presets
+// can be combined, and any other value is taken from the last preset.
+func mergePresets(presets map[string]map[string]any, names []string) (map[string]any, error) {
+	merged := make(map[string]any)
+	for _, name := range names {
+		preset, ok := presets[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownPreset, name)
+		}
+
+		for key, value := range preset {
+			current, isList := merged[key].([]any)
+			items, ok := value.([]any)
+			if isList && ok {
+				merged[key] = append(slices.Clip(current), items...)
+				continue
+			}
+			merged[key] = value
+		}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestPresetFlagName(t *testing.T) {
+	type testCase struct {
+		key    string
+		want   string
+		wantOK bool
+	}
+
+	testCases := []testCase{
+		{key: "keep-namespace", want: "keep-namespace", wantOK: true},
+		{key: "keepNamespaces", want: "keep-namespace", wantOK: true},
+		{key: "dropKinds", want: "drop-kind", wantOK: true},
+		{key: "dropkinds", want: "drop-kind", wantOK: true},
+		{key: "localOnly", want: "local-only", wantOK: true},
+		{key: "keepNamespace", wantOK: false},
+		{key: "layout", wantOK: false},
+		{key: "highlightKinds", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			got, ok := presetFlagName(tc.key)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("want %q, %t, got %q, %t", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestLoadPresets(t *testing.T) {
+	type testCase struct {
+		desc    string
+		config  map[string]any
+		want    map[string]map[string]any
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "no presets",
+			config: map[string]any{},
+			want:   map[string]map[string]any{},
+		},
+		{
+			desc: "flag and spec field names",
+			config: map[string]any{
+				presetsConfigKey: map[string]any{
+					"monitoring": map[string]any{
+						"keepNamespaces": []any{"monitoring"},
+						"drop-kind":      []any{"Secret"},
+					},
+				},
+			},
+			want: map[string]map[string]any{
+				"monitoring": {
+					"keep-namespace": []any{"monitoring"},
+					"drop-kind":      []any{"Secret"},
+				},
+			},
+		},
+		{
+			desc:    "presets not a mapping",
+			config:  map[string]any{presetsConfigKey: []any{"monitoring"}},
+			wantErr: errInvalidPreset,
+		},
+		{
+			desc: "preset not a mapping",
+			config: map[string]any{
+				presetsConfigKey: map[string]any{"monitoring": []any{"monitoring"}},
+			},
+			wantErr: errInvalidPreset,
+		},
+		{
+			desc: "not a filter",
+			config: map[string]any{
+				presetsConfigKey: map[string]any{
+					"monitoring": map[string]any{"layout": "TB"},
+				},
+			},
+			wantErr: errInvalidPreset,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := loadPresets(tc.config)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr == nil && !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want presets %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMergePresets(t *testing.T) {
+	presets := map[string]map[string]any{
+		"monitoring": {
+			"keep-namespace": []any{"monitoring"},
+			"drop-kind":      []any{"Secret"},
+			"case-sensitive": false,
+		},
+		"logging": {
+			"keep-namespace": []any{"logging"},
+			"case-sensitive": true,
+		},
+	}
+
+	got, err := mergePresets(presets, []string{"monitoring", "logging"})
+	if err != nil {
+		t.Fatalf("merging presets failed: %s", err)
+	}
+	want := map[string]any{
+		"keep-namespace": []any{"monitoring", "logging"},
+		"drop-kind":      []any{"Secret"},
+		"case-sensitive": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want merged preset %v, got %v", want, got)
+	}
+
+	// The presets are not modified by merging
+	if values := presets["monitoring"]["keep-namespace"]; !reflect.DeepEqual(values, []any{"monitoring"}) {
+		t.Fatalf("want preset unchanged, got %v", values)
+	}
+
+	if _, err := mergePresets(presets, []string{"monitoring", "bogus"}); !errors.Is(err, errUnknownPreset) {
+		t.Fatalf("want error %v, got %v", errUnknownPreset, err)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+	config := `presets:
+  no-maps:
+    dropKinds: [ConfigMap]
+  no-services:
+    drop-kind: [Service]
+profiles:
+  no-deployments:
+    drop-kind: [Deployment]
+`
+
+	type testCase struct {
+		desc    string
+		config  string
+		args    []string
+		env     map[string]string
+		want    []string
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			desc: "preset",
+			args: []string{"--preset", "no-maps"},
+			want: []string{testDeployment, testService},
+		},
+		{
+			desc: "combined presets",
+			args: []string{"--preset", "no-maps", "--preset", "no-services"},
+			want: []string{testDeployment},
+		},
+		{
+			desc: "environment variable",
+			env:  map[string]string{"KUSTOMIZE_DOT_PRESET": "no-maps,no-services"},
+			want: []string{testDeployment},
+		},
+		{
+			desc:   "selected in config",
+			config: "preset: [no-services]\n",
+			want:   []string{testConfigMap, testDeployment},
+		},
+		{
+			desc:   "preset overrides config",
+			config: "drop-kind: [Deployment]\n",
+			args:   []string{"--preset", "no-maps"},
+			want:   []string{testDeployment, testService},
+		},
+		{
+			desc: "preset overrides profile",
+			args: []string{"--preset", "no-maps", "--profile", "no-deployments"},
+			want: []string{testDeployment, testService},
+		},
+		{
+			desc: "flags override preset",
+			args: []string{"--preset", "no-maps", "--drop-kind", "Service"},
+			want: []string{testConfigMap, testDeployment},
+		},
+		{
+			desc:    "unknown preset",
+			args:    []string{"--preset", "bogus"},
+			wantErr: errUnknownPreset,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			chdir(t, dir)
+			writeTestFile(t, filepath.Join(dir, configFileName), config+tc.config)
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			out, _, err := runApp(t, append([]string{"generate", "-f", resources}, tc.args...)...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if got := graphResources(out); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want resources %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// Presets of the prune command
+	dir := t.TempDir()
+	chdir(t, dir)
+	writeTestFile(t, filepath.Join(dir, configFileName), config)
+	out, _, err := runApp(t, "prune", "-f", resources, "--preset", "no-maps")
+	if err != nil {
+		t.Fatalf("pruning failed: %s", err)
+	}
+	if strings.Contains(out, "kind: ConfigMap") || !strings.Contains(out, "kind: Service") {
+		t.Fatalf("want resources without the ConfigMap, got %q", out)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errUnknownProfile is returned when the requested profile is neither a
+// built-in profile, nor a profile from the config file.
+var errUnknownProfile = errors.New("unknown profile")
+
+// errInvalidProfile is returned when a profile from the config file is not a
+// mapping of flag names to values.
+var errInvalidProfile = errors.New("invalid profile")
+
+// profilesConfigKey is the config key, which contains the user-defined
+// profiles.
+const profilesConfigKey = "profiles"
+
+// builtinProfiles contains the built-in profiles, which map the names of the
+// generate and render command flags to their values.
+var builtinProfiles = map[string]map[string]any{
+	// minimal graphs the relationships between the resources only
+	"minimal": {
+		"no-origins":     true,
+		"drop-generated": true,
+		"drop-orphans":   true,
+	},
+	// full graphs everything known about the resources
+	"full": {
+		"detail":        true,
+		"legend":        "bottom",
+		"tooltips":      true,
+		"show-images":   true,
+		"show-patches":  true,
+		"group-remotes": true,
+		"auto-color":    "kinds",
+	},
+	// topology graphs how the origins are laid out
+	"topology": {
+		"layout":        "TB",
+		"origins-only":  true,
+		"group-remotes": true,
+	},
+}
+
+// loadProfiles returns the built-in profiles along with the profiles from
+// the given config. Profiles from the config override the built-in profiles
+// with the same name.
+func loadProfiles(config map[string]any) (map[string]map[string]any, error) {
+	profiles := make(map[string]map[string]any, len(builtinProfiles))
+	for name, values := range builtinProfiles {
+		profiles[name] = values
+	}
+
+	if config[profilesConfigKey] == nil {
+		return profiles, nil
+	}
+	items, ok := config[profilesConfigKey].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a mapping of profile names", errInvalidProfile, profilesConfigKey)
+	}
+	for name, item := range items {
+		values, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a mapping of flag names", errInvalidProfile, name)
+		}
+		profiles[name] = values
+	}
+
+	return profiles, nil
+}
+
+// getProfile returns the values of the profile with the given name.
+func getProfile(profiles map[string]map[string]any, name string) (map[string]any, error) {
+	values, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, expected one of %s", errUnknownProfile, name, strings.Join(sortedKeys(profiles), ", "))
+	}
+
+	return values, nil
+}
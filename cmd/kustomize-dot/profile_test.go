@@ -0,0 +1,265 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"github.com/urfave/cli/v2"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	for name, profile := range builtinProfiles {
+		t.Run(name, func(t *testing.T) {
+			// The values of the profiles are valid flag values
+			for _, cmd := range []*cli.Command{newGenerateCommand(), newRenderCommand()} {
+				ctx := newTestContext(t, cmd)
+				for _, key := range sortedKeys(profile) {
+					for _, value := range configValues(profile[key]) {
+						if err := ctx.Set(key, value); err != nil {
+							t.Fatalf("%s: want valid value of %s, got %s", cmd.Name, key, err)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	type testCase struct {
+		desc    string
+		config  map[string]any
+		want    []string
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "built-in profiles",
+			config: map[string]any{},
+			want:   []string{"full", "minimal", "topology"},
+		},
+		{
+			desc: "user-defined profiles",
+			config: map[string]any{
+				profilesConfigKey: map[string]any{
+					"review": map[string]any{"legend": "right"},
+				},
+			},
+			want: []string{"full", "minimal", "review", "topology"},
+		},
+		{
+			desc:    "profiles not a mapping",
+			config:  map[string]any{profilesConfigKey: []any{"review"}},
+			wantErr: errInvalidProfile,
+		},
+		{
+			desc: "profile not a mapping",
+			config: map[string]any{
+				profilesConfigKey: map[string]any{"review": "legend"},
+			},
+			wantErr: errInvalidProfile,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			profiles, err := loadProfiles(tc.config)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if got := sortedKeys(profiles); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want profiles %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// User-defined profiles override the built-in profiles
+	config := map[string]any{
+		profilesConfigKey: map[string]any{
+			"minimal": map[string]any{"drop-kind": []any{"Secret"}},
+		},
+	}
+	profiles, err := loadProfiles(config)
+	if err != nil {
+		t.Fatalf("loading profiles failed: %s", err)
+	}
+	if want := config[profilesConfigKey].(map[string]any)["minimal"]; !reflect.DeepEqual(profiles["minimal"], want) {
+		t.Fatalf("want profile %v, got %v", want, profiles["minimal"])
+	}
+	if len(builtinProfiles["minimal"]) != 3 {
+		t.Fatalf("want built-in profile unchanged, got %v", builtinProfiles["minimal"])
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	profiles, err := loadProfiles(map[string]any{})
+	if err != nil {
+		t.Fatalf("loading profiles failed: %s", err)
+	}
+
+	values, err := getProfile(profiles, "topology")
+	if err != nil {
+		t.Fatalf("getting profile failed: %s", err)
+	}
+	if !reflect.DeepEqual(values, builtinProfiles["topology"]) {
+		t.Fatalf("want profile %v, got %v", builtinProfiles["topology"], values)
+	}
+
+	_, err = getProfile(profiles, "bogus")
+	if !errors.Is(err, errUnknownProfile) {
+		t.Fatalf("want error %v, got %v", errUnknownProfile, err)
+	}
+	if !strings.Contains(err.Error(), "full, minimal, topology") {
+		t.Fatalf("want error listing the profiles, got %s", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+	origin := "examples/helloWorld/service.yaml"
+	config := `profiles:
+  no-maps:
+    drop-kind: [ConfigMap]
+`
+
+	type testCase struct {
+		desc        string
+		config      string
+		args        []string
+		env         map[string]string
+		contains    []string
+		notContains []string
+		wantErr     error
+	}
+
+	testCases := []testCase{
+		{
+			desc:        "minimal",
+			args:        []string{"--profile", "minimal"},
+			notContains: []string{origin},
+		},
+		{
+			desc:        "topology",
+			args:        []string{"--profile", "topology"},
+			contains:    []string{origin, `rankdir="TB"`, "cluster_0"},
+			notContains: []string{testService},
+		},
+		{
+			desc:     "full",
+			args:     []string{"--profile", "full"},
+			contains: []string{testService, "legend"},
+		},
+		{
+			desc:        "user-defined",
+			config:      config,
+			args:        []string{"--profile", "no-maps"},
+			contains:    []string{testDeployment, testService},
+			notContains: []string{testConfigMap},
+		},
+		{
+			desc:        "environment variable",
+			config:      config,
+			env:         map[string]string{"KUSTOMIZE_DOT_PROFILE": "no-maps"},
+			contains:    []string{testDeployment, testService},
+			notContains: []string{testConfigMap},
+		},
+		{
+			desc:        "selected in config",
+			config:      config + "profile: no-maps\n",
+			contains:    []string{testDeployment, testService},
+			notContains: []string{testConfigMap},
+		},
+		{
+			desc:        "profile overrides config",
+			config:      config + "drop-kind: [Service]\n",
+			args:        []string{"--profile", "no-maps"},
+			contains:    []string{testDeployment, testService},
+			notContains: []string{testConfigMap},
+		},
+		{
+			desc:        "flags override profile",
+			config:      config,
+			args:        []string{"--profile", "no-maps", "--drop-kind", "Service"},
+			contains:    []string{testConfigMap, testDeployment},
+			notContains: []string{testService},
+		},
+		{
+			desc:    "unknown profile",
+			args:    []string{"--profile", "bogus"},
+			wantErr: errUnknownProfile,
+		},
+		{
+			desc:    "invalid profile",
+			config:  "profiles:\n  no-maps: [ConfigMap]\n",
+			args:    []string{"--profile", "no-maps"},
+			wantErr: errInvalidProfile,
+		},
+		{
+			desc:    "unknown key",
+			config:  "profiles:\n  no-maps:\n    drop-kinds: [ConfigMap]\n",
+			args:    []string{"--profile", "no-maps"},
+			wantErr: errUnknownConfigKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			chdir(t, dir)
+			if tc.config != "" {
+				writeTestFile(t, filepath.Join(dir, configFileName), tc.config)
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			out, _, err := runApp(t, append([]string{"generate", "-f", resources}, tc.args...)...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(out, want) {
+					t.Fatalf("want graph containing %q, got %q", want, out)
+				}
+			}
+			for _, want := range tc.notContains {
+				if strings.Contains(out, want) {
+					t.Fatalf("want graph without %q, got %q", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"io"
+	"slices"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+)
+
+// filterFlags contains the names of the generate command flags, which select
+// the resources to keep and drop.
+var filterFlags = []string{
+	"case-sensitive",
+	"exact-match",
+	"drop-kind",
+	"drop-namespace",
+	"keep-kind",
+	"keep-namespace",
+	"drop-cluster-scoped",
+	"keep-cluster-scoped-only",
+	"local-only",
+	"remote-only",
+	"drop-group",
+	"keep-group",
+	"drop-origin-path",
+	"keep-origin-path",
+	"drop-owner-kind",
+	"keep-owner-kind",
+	"drop-has-label",
+	"keep-has-label",
+	"keep-resource",
+	"drop-name-regex",
+	"keep-name-regex",
+	"drop-name-prefix",
+	"drop-name-suffix",
+	"keep-name-prefix",
+	"keep-name-suffix",
+	"filter-expr",
+	"filter-file",
+	"filter-precedence",
+	"query",
+	"drop-generated",
+}
+
+// pruneFlags contains the names of the generate command flags, which are
+// accepted by the prune command.
+var pruneFlags = append([]string{"file", "debug", "profile", "preset", "explain"}, filterFlags...)
+
+// newPruneCommand returns the command for filtering the Kubernetes resources.
+// The command accepts the filter options of the generate command.
+func newPruneCommand() *cli.Command {
+	flags := make([]cli.Flag, 0)
+	for _, flag := range newGenerateCommand().Flags {
+		if slices.Contains(pruneFlags, flag.Names()[0]) {
+			flags = append(flags, flag)
+		}
+	}
+	flags = append(flags, &cli.PathFlag{
+		Name:    "output",
+		Usage:   "file to write the kept resources to, or - for stdout",
+		Value:   "-",
+		Aliases: []string{"o"},
+		EnvVars: []string{"KUSTOMIZE_DOT_PRUNE_OUTPUT"},
+	})
+
+	cmd := &cli.Command{
+		Name:   "prune",
+		Usage:  "output the Kubernetes resources kept by the filters",
+		Action: execPruneCommand,
+		Flags:  flags,
+	}
+
+	cmd.BashComplete = completeFilterValues(cmd)
+
+	return cmd
+}
+
+// execPruneCommand runs the command for filtering the Kubernetes resources,
+// and writes the kept resources as YAML documents.
+func execPruneCommand(ctx *cli.Context) error {
+	// config file
+	if err := applyConfig(ctx); err != nil {
+		return err
+	}
+
+	// filter options
+	opts, err := filterOptions(contextOptions{ctx})
+	if err != nil {
+		return err
+	}
+
+	// debug option
+	if ctx.Bool("debug") {
+		opts = append(opts, parser.WithLogger(newDebugLogger()))
+	}
+
+	// Read the resources and filter them
+	resources, err := readResources(ctx.Path("file"))
+	if err != nil {
+		return err
+	}
+
+	p := parser.New(opts...)
+
+	// explain option
+	if ctx.Bool("explain") {
+		return writeExplanations(ctx.Path("output"), p, resources)
+	}
+
+	kept, err := p.Filter(resources)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(ctx.Path("output"), func(w io.Writer) error {
+		for i, r := range kept {
+			data, err := r.AsYAML()
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				if _, err := io.WriteString(w, "---\n"); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
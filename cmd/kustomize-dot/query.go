@@ -0,0 +1,168 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// errInvalidProvenanceQuery is returned when the query command was called
+// without exactly one question.
+var errInvalidProvenanceQuery = errors.New("exactly one of --from-origin and --of must be specified")
+
+// errUnsupportedQueryFormat is returned when the query command was called with
+// an unknown output format.
+var errUnsupportedQueryFormat = errors.New("unsupported query format")
+
+// newQueryCommand returns the command for answering questions about the
+// provenance of resources.
+func newQueryCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:   "query",
+		Usage:  "query the provenance of resources",
+		Action: execQueryCommand,
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:     "file",
+				Usage:    "file containing the Kubernetes resources",
+				Required: true,
+				Aliases:  []string{"f"},
+			},
+			&cli.StringFlag{
+				Name:  "from-origin",
+				Usage: "list the resources, which came from an origin path, repository or generator config matching the given glob pattern, e.g. 'base/monitoring/**'",
+			},
+			&cli.StringFlag{
+				Name:  "of",
+				Usage: "show where the given resource came from, e.g. default/deployment/web",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Usage:   "output format, either text or json",
+				Value:   "text",
+				EnvVars: []string{"KUSTOMIZE_DOT_QUERY_FORMAT"},
+			},
+		},
+	}
+
+	return cmd
+}
+
+// describeOrigin returns a human-readable description of the given origin.
+func describeOrigin(origin *resource.Origin) string {
+	location := origin.Path
+	if location == "" {
+		location = origin.ConfiguredIn
+	}
+	if origin.Repo != "" {
+		location = fmt.Sprintf("%s in %s", location, origin.Repo)
+		if origin.Ref != "" {
+			location = fmt.Sprintf("%s@%s", location, origin.Ref)
+		}
+	}
+	if origin.ConfiguredBy.Kind != "" {
+		location = fmt.Sprintf("%s (%s)", location, origin.ConfiguredBy.Kind)
+	}
+
+	return location
+}
+
+// writeProvenanceText writes the provenance of resources as text.
+func writeProvenanceText(w io.Writer, items ...*parser.Provenance) error {
+	for _, pv := range items {
+		if _, err := fmt.Fprintln(w, pv.Resource); err != nil {
+			return err
+		}
+		origin := "unknown"
+		if pv.Origin != nil {
+			origin = describeOrigin(pv.Origin)
+		}
+		if _, err := fmt.Fprintf(w, "  origin: %s\n", origin); err != nil {
+			return err
+		}
+		for _, t := range pv.Transformations {
+			if _, err := fmt.Fprintf(w, "  transformed by: %s\n", describeOrigin(t)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// execQueryCommand runs the command for answering questions about the
+// provenance of resources.
+func execQueryCommand(ctx *cli.Context) error {
+	fromOrigin := ctx.String("from-origin")
+	of := ctx.String("of")
+	if (fromOrigin == "") == (of == "") {
+		return errInvalidProvenanceQuery
+	}
+
+	format := ctx.String("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("%w: %s", errUnsupportedQueryFormat, format)
+	}
+
+	resources, err := readResources(ctx.Path("file"))
+	if err != nil {
+		return err
+	}
+
+	p := parser.New()
+	var result any
+	var items []*parser.Provenance
+	if fromOrigin != "" {
+		items, err = p.ResourcesFromOrigin(resources, fromOrigin)
+		if err != nil {
+			return err
+		}
+		result = items
+	} else {
+		pv, err := p.ProvenanceOf(resources, of)
+		if err != nil {
+			return err
+		}
+		items = []*parser.Provenance{pv}
+		result = pv
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	return writeProvenanceText(os.Stdout, items...)
+}
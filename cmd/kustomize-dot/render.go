@@ -0,0 +1,164 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+)
+
+// errGraphvizNotFound is returned when the Graphviz dot command cannot be
+// found.
+var errGraphvizNotFound = errors.New("graphviz not found, see https://graphviz.org/download/")
+
+// errUnsupportedEngine is returned when the app was called with an unknown
+// Graphviz layout engine.
+var errUnsupportedEngine = errors.New("unsupported layout engine")
+
+// defaultOutputType is the output type used, when it can neither be derived
+// from the output file, nor was it specified.
+const defaultOutputType = "svg"
+
+// newRenderCommand returns the command for rendering the graph using Graphviz.
+// The command accepts the same options as the generate command.
+func newRenderCommand() *cli.Command {
+	flags := make([]cli.Flag, 0)
+	for _, flag := range newGenerateCommand().Flags {
+		if slices.Contains(flag.Names(), "format") {
+			continue
+		}
+		flags = append(flags, flag)
+	}
+
+	flags = append(flags,
+		&cli.StringFlag{
+			Name:    "engine",
+			Usage:   "graphviz layout engine, e.g. dot, neato, fdp, sfdp, circo or twopi",
+			Value:   parser.EngineDot.String(),
+			Aliases: []string{"K"},
+			EnvVars: []string{"KUSTOMIZE_DOT_ENGINE"},
+		},
+		&cli.StringFlag{
+			Name:    "type",
+			Usage:   "graphviz output type, e.g. svg, png or pdf, which defaults to the output file extension",
+			Aliases: []string{"T"},
+			EnvVars: []string{"KUSTOMIZE_DOT_OUTPUT_TYPE"},
+		},
+		&cli.StringFlag{
+			Name:    "graphviz",
+			Usage:   "graphviz dot command used to render the graph",
+			Value:   parser.GraphvizCommand,
+			EnvVars: []string{"KUSTOMIZE_DOT_GRAPHVIZ"},
+		},
+	)
+
+	cmd := &cli.Command{
+		Name:    "render",
+		Usage:   "render the graph using graphviz",
+		Aliases: []string{"r"},
+		Action:  execRenderCommand,
+		Flags:   flags,
+	}
+
+	cmd.BashComplete = completeFilterValues(cmd)
+
+	return cmd
+}
+
+// getEngine returns the Graphviz layout engine from the given value
+func getEngine(value string) (parser.Engine, error) {
+	engine := parser.Engine(value)
+	if !slices.Contains(parser.Engines(), engine) {
+		return parser.Engine(""), fmt.Errorf("%w: %s", errUnsupportedEngine, value)
+	}
+
+	return engine, nil
+}
+
+// getOutputType returns the Graphviz output type of the output at the given
+// path. Unless specified, the output type is derived from the extension of the
+// output file.
+func getOutputType(ctx *cli.Context, path string) string {
+	if outputType := ctx.String("type"); outputType != "" {
+		return outputType
+	}
+
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext != "" {
+		return strings.ToLower(ext)
+	}
+
+	return defaultOutputType
+}
+
+// execRenderCommand runs the command for rendering the graph of the
+// Kubernetes resources using Graphviz.
+func execRenderCommand(ctx *cli.Context) error {
+	// config file
+	if err := applyConfig(ctx); err != nil {
+		return err
+	}
+
+	// engine option
+	engine, err := getEngine(ctx.String("engine"))
+	if err != nil {
+		return err
+	}
+
+	// graphviz option
+	command, err := exec.LookPath(ctx.String("graphviz"))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errGraphvizNotFound, err)
+	}
+	parser.GraphvizCommand = command
+
+	paths, err := getOutputPaths(ctx)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]graphOutput, 0, len(paths))
+	for _, path := range paths {
+		outputType := getOutputType(ctx, path)
+		output := graphOutput{
+			path: path,
+			ext:  "." + outputType,
+			newRenderer: func(c context.Context) (parser.Renderer, error) {
+				return parser.NewGraphvizRendererContext(c, engine, outputType), nil
+			},
+		}
+		outputs = append(outputs, output)
+	}
+
+	return generateGraph(ctx, outputs)
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// jsonSchemaDialect is the JSON Schema dialect of the plugin config schema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// pluginExample is an example config of the KRM Function plugin.
+const pluginExample = `apiVersion: dnaeon.github.io/v1
+kind: KustomizeDot
+metadata:
+  name: kustomize-dot
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: dnaeon/kustomize-dot:latest
+spec:
+  layout: LR
+  legend: bottom
+  keepNamespaces:
+    - monitoring
+  dropKinds:
+    - Secret
+  highlightKinds:
+    service: yellow
+    deployment: lightgreen
+`
+
+// jsonSchema is the subset of JSON Schema, which describes the config of the
+// KRM Function plugin.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Examples             []any                  `json:"examples,omitempty"`
+}
+
+// colorFormat is the format of the JSON Schema string values, which are
+// Graphviz colors.
+const colorFormat = "color"
+
+// enumValues returns the string values of the given enum values.
+func enumValues[T fmt.Stringer](values []T) []any {
+	items := make([]any, 0, len(values))
+	for _, v := range values {
+		items = append(items, v.String())
+	}
+
+	return items
+}
+
+// schemaEnums contains the allowed values of the plugin spec fields, keyed by
+// their names in the config. The legend accepts the boolean values as well,
+// since it used to be a boolean field.
+var schemaEnums = map[string][]any{
+	"layout": enumValues([]parser.LayoutDirection{
+		parser.LayoutDirectionBT,
+		parser.LayoutDirectionTB,
+		parser.LayoutDirectionLR,
+		parser.LayoutDirectionRL,
+	}),
+	"format":   enumValues(parser.Formats()),
+	"legend":   append(enumValues(parser.LegendPositions()), legendOff, true, false),
+	"theme":    enumValues(parser.Themes()),
+	"onExceed": enumValues(parser.ThresholdActions()),
+	"mode":     enumValues(pluginModes()),
+	"splitBy":  enumValues(parser.SplitBys()),
+	"filterPrecedence": enumValues([]parser.FilterPrecedence{
+		parser.FilterPrecedenceOptions,
+		parser.FilterPrecedenceRules,
+	}),
+	"autoColor": enumValues([]parser.AutoColor{
+		parser.AutoColorNone,
+		parser.AutoColorNamespaces,
+		parser.AutoColorKinds,
+	}),
+}
+
+// colorFields contains the names of the fields, whose values are colors, or
+// maps to colors, e.g. highlightKinds.
+var colorFields = map[string]bool{
+	"background":          true,
+	"color":               true,
+	"borderColor":         true,
+	"fillColor":           true,
+	"fontColor":           true,
+	"highlightKinds":      true,
+	"highlightNamespaces": true,
+	"highlightNameRegex":  true,
+}
+
+// newSchemaCommand returns the command for printing the JSON Schema of the
+// KRM Function plugin config.
+func newSchemaCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:   "schema",
+		Usage:  "print the JSON schema of the KRM function config",
+		Action: execSchemaCommand,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "example",
+				Usage:   "print an example KRM function config instead of the schema",
+				Value:   false,
+				EnvVars: []string{"KUSTOMIZE_DOT_EXAMPLE"},
+			},
+		},
+	}
+
+	return cmd
+}
+
+// execSchemaCommand prints the JSON Schema of the KRM Function plugin config,
+// or an example config.
+func execSchemaCommand(ctx *cli.Context) error {
+	if ctx.Bool("example") {
+		_, err := fmt.Fprint(os.Stdout, pluginExample)
+		return err
+	}
+
+	schema, err := pluginSchema()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(schema)
+}
+
+// pluginSchema returns the JSON Schema of the KRM Function plugin config. The
+// descriptions of the spec fields are the usages of the corresponding flags of
+// the generate command.
+func pluginSchema() (*jsonSchema, error) {
+	var example map[string]any
+	if err := yaml.Unmarshal([]byte(pluginExample), &example); err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]string)
+	for _, flag := range newGenerateCommand().Flags {
+		df, ok := flag.(cli.DocGenerationFlag)
+		if !ok {
+			continue
+		}
+		if field, ok := configSpecField(flag.Names()[0]); ok {
+			descriptions[field.Name] = df.GetUsage()
+		}
+	}
+
+	specType := reflect.TypeOf(pluginSpec{})
+	spec := typeSchema(specType)
+	for i := 0; i < specType.NumField(); i++ {
+		field := specType.Field(i)
+		name := yamlName(field)
+		if prop, ok := spec.Properties[name]; ok {
+			prop.Description = descriptions[field.Name]
+			prop.Enum = schemaEnums[name]
+		}
+	}
+
+	schema := &jsonSchema{
+		Schema:      jsonSchemaDialect,
+		Title:       "kustomize-dot",
+		Description: "config of the kustomize-dot KRM function",
+		Type:        "object",
+		Properties: map[string]*jsonSchema{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			"metadata":   {Type: "object"},
+			"spec":       spec,
+		},
+		Required: []string{"apiVersion", "kind"},
+		Examples: []any{example},
+	}
+
+	return schema, nil
+}
+
+// yamlName returns the name of the struct field in YAML documents.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// typeSchema returns the JSON Schema of the given type. Structs do not allow
+// properties, which are not part of them, so that typos are reported.
+func typeSchema(t reflect.Type) *jsonSchema {
+	if t == reflect.TypeOf(pluginLegend("")) {
+		return &jsonSchema{Type: []string{"string", "boolean"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Struct:
+		schema := &jsonSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*jsonSchema),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := yamlName(field)
+			prop := typeSchema(field.Type)
+			if colorFields[name] {
+				if items, ok := prop.AdditionalProperties.(*jsonSchema); ok {
+					items.Format = colorFormat
+				} else {
+					prop.Format = colorFormat
+				}
+			}
+			schema.Properties[name] = prop
+		}
+		return schema
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// yamlTypes maps the tags of YAML nodes to the JSON Schema types.
+var yamlTypes = map[string]string{
+	yaml.NodeTagString: "string",
+	yaml.NodeTagBool:   "boolean",
+	yaml.NodeTagInt:    "integer",
+	yaml.NodeTagFloat:  "number",
+	yaml.NodeTagMap:    "object",
+	yaml.NodeTagSeq:    "array",
+}
+
+// types returns the JSON Schema types allowed by the schema. Integers are
+// valid numbers as well.
+func (s *jsonSchema) types() []string {
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	}
+	if slices.Contains(types, "number") {
+		types = append(types, "integer")
+	}
+
+	return types
+}
+
+// validate validates the YAML node at the given path against the schema, and
+// returns the messages describing the problems found, keyed by the path of
+// the invalid fields. Null values are valid for any type.
+func (s *jsonSchema) validate(node *yaml.RNode, path string) map[string]string {
+	problems := make(map[string]string)
+	tag := node.YNode().ShortTag()
+	if tag == yaml.NodeTagNull {
+		return problems
+	}
+
+	nodeType := yamlTypes[tag]
+	if types := s.types(); len(types) > 0 && !slices.Contains(types, nodeType) {
+		problems[path] = fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), cmp.Or(nodeType, tag))
+		return problems
+	}
+
+	value := node.YNode().Value
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(v any) bool { return fmt.Sprint(v) == value }) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			allowed = append(allowed, fmt.Sprint(v))
+		}
+		problems[path] = fmt.Sprintf("unsupported value %q, expected one of %s", value, strings.Join(allowed, ", "))
+		return problems
+	}
+	if s.Format == colorFormat && !parser.ValidColor(value) {
+		problems[path] = fmt.Sprintf("invalid color %q", value)
+		return problems
+	}
+
+	switch nodeType {
+	case "array":
+		for i, item := range node.Content() {
+			for p, problem := range s.Items.validate(yaml.NewRNode(item), fmt.Sprintf("%s[%d]", path, i)) {
+				problems[p] = problem
+			}
+		}
+	case "object":
+		fields, err := node.Fields()
+		if err != nil {
+			problems[path] = err.Error()
+			return problems
+		}
+		for _, field := range fields {
+			fieldPath := strings.TrimPrefix(path+"."+field, ".")
+			prop, ok := s.Properties[field]
+			if !ok {
+				prop, ok = s.AdditionalProperties.(*jsonSchema)
+			}
+			if !ok {
+				if s.AdditionalProperties == false {
+					problems[fieldPath] = "unknown field"
+				}
+				continue
+			}
+			for p, problem := range prop.validate(node.Field(field).Value, fieldPath) {
+				problems[p] = problem
+			}
+		}
+	}
+
+	return problems
+}
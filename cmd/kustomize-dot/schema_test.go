@@ -0,0 +1,220 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestPluginSchema(t *testing.T) {
+	schema, err := pluginSchema()
+	if err != nil {
+		t.Fatalf("creating schema failed: %s", err)
+	}
+
+	spec := schema.Properties["spec"]
+	if spec.AdditionalProperties != false {
+		t.Fatalf("want unknown spec fields disallowed, got %v", spec.AdditionalProperties)
+	}
+
+	// Each spec field is described by the schema, and the fields of the
+	// generate command flags are described by their usage.
+	specType := reflect.TypeOf(pluginSpec{})
+	for i := 0; i < specType.NumField(); i++ {
+		field := specType.Field(i)
+		if _, ok := spec.Properties[yamlName(field)]; !ok {
+			t.Fatalf("want property for spec field %s", field.Name)
+		}
+	}
+	for _, name := range []string{"layout", "keepNamespaces", "dropKinds", "highlightKinds", "focusDepth"} {
+		if spec.Properties[name].Description == "" {
+			t.Fatalf("want description of %s", name)
+		}
+	}
+
+	type testCase struct {
+		name   string
+		want   *jsonSchema
+		enumOK []any
+	}
+
+	testCases := []testCase{
+		{name: "keepNamespaces", want: &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}},
+		{name: "focusDepth", want: &jsonSchema{Type: "integer"}},
+		{name: "fontSize", want: &jsonSchema{Type: "number"}},
+		{name: "legend", want: &jsonSchema{Type: []string{"string", "boolean"}}, enumOK: []any{"bottom", true}},
+		{name: "layout", want: &jsonSchema{Type: "string"}, enumOK: []any{"LR", "TB"}},
+		{
+			name: "highlightKinds",
+			want: &jsonSchema{Type: "object", AdditionalProperties: &jsonSchema{Type: "string", Format: colorFormat}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := *spec.Properties[tc.name]
+			for _, value := range tc.enumOK {
+				if !slices.Contains(got.Enum, value) {
+					t.Fatalf("want %v allowed, got %v", value, got.Enum)
+				}
+			}
+			got.Description = ""
+			got.Enum = nil
+			if !reflect.DeepEqual(&got, tc.want) {
+				t.Fatalf("want schema %+v, got %+v", tc.want, &got)
+			}
+		})
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := pluginSchema()
+	if err != nil {
+		t.Fatalf("creating schema failed: %s", err)
+	}
+
+	type testCase struct {
+		desc string
+		spec string
+		want map[string]string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "valid",
+			spec: "layout: TB\nlegend: true\nfocusDepth: 2\nfontSize: 12\nkeepNamespaces: [monitoring]\nhighlightKinds: {service: yellow}\ndropKinds:\n",
+			want: map[string]string{},
+		},
+		{
+			desc: "typo",
+			spec: "keepNamespace: [monitoring]\n",
+			want: map[string]string{"spec.keepNamespace": "unknown field"},
+		},
+		{
+			desc: "wrong type",
+			spec: "keepNamespaces: monitoring\nfocusDepth: deep\n",
+			want: map[string]string{
+				"spec.keepNamespaces": "expected array, got string",
+				"spec.focusDepth":     "expected integer, got string",
+			},
+		},
+		{
+			desc: "wrong item type",
+			spec: "keepNamespaces: [[monitoring]]\n",
+			want: map[string]string{"spec.keepNamespaces[0]": "expected string, got array"},
+		},
+		{
+			desc: "unsupported value",
+			spec: "layout: XY\n",
+			want: map[string]string{"spec.layout": `unsupported value "XY", expected one of BT, TB, LR, RL`},
+		},
+		{
+			desc: "invalid color",
+			spec: "highlightKinds: {service: notacolor}\n",
+			want: map[string]string{"spec.highlightKinds.service": `invalid color "notacolor"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			node, err := yaml.Parse("apiVersion: dnaeon.github.io/v1\nkind: KustomizeDot\nspec:\n" + indent(tc.spec))
+			if err != nil {
+				t.Fatalf("parsing config failed: %s", err)
+			}
+			if got := schema.validate(node, ""); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want problems %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// indent indents the lines of the given YAML document by two spaces.
+func indent(doc string) string {
+	lines := strings.SplitAfter(doc, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+func TestSchemaExample(t *testing.T) {
+	schema, err := pluginSchema()
+	if err != nil {
+		t.Fatalf("creating schema failed: %s", err)
+	}
+
+	node, err := yaml.Parse(pluginExample)
+	if err != nil {
+		t.Fatalf("parsing example failed: %s", err)
+	}
+	if problems := schema.validate(node, ""); len(problems) > 0 {
+		t.Fatalf("want valid example, got %v", problems)
+	}
+	if len(schema.Examples) != 1 {
+		t.Fatalf("want example in schema, got %v", schema.Examples)
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	out, _, err := runApp(t, "schema")
+	if err != nil {
+		t.Fatalf("printing schema failed: %s", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(out), &schema); err != nil {
+		t.Fatalf("want JSON schema, got %s", err)
+	}
+	if schema["$schema"] != jsonSchemaDialect {
+		t.Fatalf("want schema dialect %s, got %v", jsonSchemaDialect, schema["$schema"])
+	}
+	spec := schema["properties"].(map[string]any)["spec"].(map[string]any)
+	if spec["additionalProperties"] != false {
+		t.Fatalf("want unknown spec fields disallowed, got %v", spec["additionalProperties"])
+	}
+
+	for _, args := range [][]string{{"schema", "--example"}, {"schema"}} {
+		if len(args) == 1 {
+			t.Setenv("KUSTOMIZE_DOT_EXAMPLE", "true")
+		}
+		out, _, err := runApp(t, args...)
+		if err != nil {
+			t.Fatalf("printing example failed: %s", err)
+		}
+		if out != pluginExample {
+			t.Fatalf("want example %q, got %q", pluginExample, out)
+		}
+	}
+}
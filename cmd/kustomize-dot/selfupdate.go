@@ -0,0 +1,318 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// errNoReleaseAsset is returned when the latest release contains no binary
+// for the current platform.
+var errNoReleaseAsset = errors.New("no release binary for platform")
+
+// errChecksumMismatch is returned when the checksum of the downloaded binary
+// does not match the published one.
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// latestReleaseURL is the GitHub API endpoint of the latest release.
+var latestReleaseURL = "https://api.github.com/repos/dnaeon/kustomize-dot/releases/latest"
+
+// checksumsAsset is the name of the release asset containing the SHA-256
+// checksums of the binaries.
+const checksumsAsset = "checksums.txt"
+
+// releaseAsset is a downloadable file of a GitHub release.
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// release is a GitHub release.
+type release struct {
+	TagName string          `json:"tag_name"`
+	Assets  []*releaseAsset `json:"assets"`
+}
+
+// asset returns the release asset with the given name.
+func (r *release) asset(name string) (*releaseAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+
+	return nil, false
+}
+
+// newSelfUpdateCommand returns the command for updating the app to the latest
+// release.
+func newSelfUpdateCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:   "self-update",
+		Usage:  "update kustomize-dot to the latest release",
+		Action: execSelfUpdateCommand,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "only check whether a newer release is available",
+				Value: false,
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "timeout for checking and downloading the release",
+				Value: 5 * time.Minute,
+			},
+		},
+	}
+
+	return cmd
+}
+
+// releaseBinaryName returns the name of the release binary for the current
+// platform.
+func releaseBinaryName() string {
+	name := fmt.Sprintf("kustomize-dot_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+// isNewerVersion returns true, if the version is newer than the current one.
+// Versions are compared by their dot-separated numeric components, and the
+// "v" prefix is ignored.
+func isNewerVersion(version, current string) bool {
+	a := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	b := strings.Split(strings.TrimPrefix(current, "v"), ".")
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x, _ = strconv.Atoi(a[i])
+		}
+		if i < len(b) {
+			y, _ = strconv.Atoi(b[i])
+		}
+		if x != y {
+			return x > y
+		}
+	}
+
+	return false
+}
+
+// httpGet performs a GET request, and returns the response body on success.
+// The caller is responsible for closing the body.
+func httpGet(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// latestRelease returns the latest GitHub release.
+func latestRelease(ctx context.Context) (*release, error) {
+	body, err := httpGet(ctx, latestReleaseURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var r release
+	if err := json.NewDecoder(body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// releaseChecksum returns the published SHA-256 checksum of the release asset
+// with the given name.
+func releaseChecksum(ctx context.Context, r *release, name string) (string, error) {
+	asset, ok := r.asset(checksumsAsset)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", errNoReleaseAsset, checksumsAsset)
+	}
+
+	body, err := httpGet(ctx, asset.URL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	// Each line is in the form of "<checksum>  <name>"
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("%w: %s", errNoReleaseAsset, name)
+}
+
+// replaceExecutable downloads the release asset, verifies its checksum, and
+// replaces the executable at the given path with it.
+func replaceExecutable(ctx context.Context, asset *releaseAsset, checksum string, path string) error {
+	body, err := httpGet(ctx, asset.URL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o755); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, checksum) {
+		return fmt.Errorf("%w: %s: want %s, got %s", errChecksumMismatch, asset.Name, checksum, got)
+	}
+
+	return moveExecutable(tmp.Name(), path, runtime.GOOS == "windows")
+}
+
+// moveExecutable moves the executable at src in place of the executable at
+// the given path. When the executable at the path is locked, e.g. while it is
+// running on Windows, it is renamed out of the way instead of replaced.
+func moveExecutable(src string, path string, locked bool) error {
+	if !locked {
+		return os.Rename(src, path)
+	}
+
+	// Running executables cannot be replaced on Windows, but they can
+	// be renamed. The executable left by a previous update is removed
+	// first, and the current one is restored, if the release binary
+	// cannot be moved in its place.
+	old := path + ".old"
+	if err := os.Remove(old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(path, old); err != nil {
+		return err
+	}
+	if err := os.Rename(src, path); err != nil {
+		if restoreErr := os.Rename(old, path); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// execSelfUpdateCommand updates the app to the latest release, by replacing
+// the executable with the release binary for the current platform.
+func execSelfUpdateCommand(ctx *cli.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx.Context, ctx.Duration("timeout"))
+	defer cancel()
+
+	r, err := latestRelease(timeoutCtx)
+	if err != nil {
+		return err
+	}
+
+	current := ctx.App.Version
+	if !isNewerVersion(r.TagName, current) {
+		printMessage("kustomize-dot %s is up to date", current)
+		return nil
+	}
+	if ctx.Bool("check") {
+		fmt.Fprintf(os.Stdout, "kustomize-dot %s is available, current version is %s\n", r.TagName, current)
+		return nil
+	}
+
+	name := releaseBinaryName()
+	asset, ok := r.asset(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", errNoReleaseAsset, name)
+	}
+	checksum, err := releaseChecksum(timeoutCtx, r, name)
+	if err != nil {
+		return err
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceExecutable(timeoutCtx, asset, checksum, path); err != nil {
+		return err
+	}
+	printMessage("updated kustomize-dot %s to %s", current, r.TagName)
+
+	return nil
+}
@@ -0,0 +1,321 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newTestReleaseServer returns a server of the latest release with the given
+// tag, which contains the given binary for the current platform.
+func newTestReleaseServer(t *testing.T, tag string, binary string) *httptest.Server {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(binary))
+	checksums := fmt.Sprintf("%s  %s\n%s *%s\n", strings.Repeat("0", 64), "kustomize-dot_plan9_mips", hex.EncodeToString(sum[:]), releaseBinaryName())
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release{
+			TagName: tag,
+			Assets: []*releaseAsset{
+				{Name: releaseBinaryName(), URL: server.URL + "/binary"},
+				{Name: checksumsAsset, URL: server.URL + "/checksums"},
+			},
+		})
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, binary)
+	})
+	mux.HandleFunc("/checksums", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, checksums)
+	})
+
+	return server
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	type testCase struct {
+		version string
+		current string
+		want    bool
+	}
+
+	testCases := []testCase{
+		{version: "v0.2.0", current: "0.1.0", want: true},
+		{version: "v0.1.1", current: "0.1.0", want: true},
+		{version: "v1.0.0", current: "0.9.9", want: true},
+		{version: "v0.10.0", current: "0.9.0", want: true},
+		{version: "v0.1.0.1", current: "0.1.0", want: true},
+		{version: "v0.1.0", current: "0.1.0", want: false},
+		{version: "0.1.0", current: "v0.1.0", want: false},
+		{version: "v0.0.9", current: "0.1.0", want: false},
+		{version: "v0.1", current: "0.1.0", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version+" "+tc.current, func(t *testing.T) {
+			if got := isNewerVersion(tc.version, tc.current); got != tc.want {
+				t.Fatalf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseBinaryName(t *testing.T) {
+	name := releaseBinaryName()
+	if !strings.HasPrefix(name, "kustomize-dot_"+runtime.GOOS+"_"+runtime.GOARCH) {
+		t.Fatalf("want binary name for %s/%s, got %s", runtime.GOOS, runtime.GOARCH, name)
+	}
+	if got := strings.HasSuffix(name, ".exe"); got != (runtime.GOOS == "windows") {
+		t.Fatalf("want .exe suffix on windows only, got %s", name)
+	}
+}
+
+func TestReleaseChecksum(t *testing.T) {
+	server := newTestReleaseServer(t, "v9.0.0", "binary")
+	sum := sha256.Sum256([]byte("binary"))
+
+	type testCase struct {
+		desc    string
+		assets  []*releaseAsset
+		name    string
+		want    string
+		wantErr error
+	}
+
+	checksums := []*releaseAsset{{Name: checksumsAsset, URL: server.URL + "/checksums"}}
+	testCases := []testCase{
+		{
+			desc:   "binary",
+			assets: checksums,
+			name:   releaseBinaryName(),
+			want:   hex.EncodeToString(sum[:]),
+		},
+		{
+			desc:    "missing binary",
+			assets:  checksums,
+			name:    "kustomize-dot_bogus",
+			wantErr: errNoReleaseAsset,
+		},
+		{
+			desc:    "missing checksums",
+			name:    releaseBinaryName(),
+			wantErr: errNoReleaseAsset,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := releaseChecksum(context.Background(), &release{Assets: tc.assets}, tc.name)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Fatalf("want checksum %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// Failed requests are reported
+	r := &release{Assets: []*releaseAsset{{Name: checksumsAsset, URL: server.URL + "/missing"}}}
+	if _, err := releaseChecksum(context.Background(), r, releaseBinaryName()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("want not found error, got %v", err)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	server := newTestReleaseServer(t, "v9.0.0", "new binary")
+	sum := sha256.Sum256([]byte("new binary"))
+	asset := &releaseAsset{Name: releaseBinaryName(), URL: server.URL + "/binary"}
+
+	type testCase struct {
+		desc     string
+		checksum string
+		want     string
+		wantErr  error
+	}
+
+	testCases := []testCase{
+		{desc: "valid checksum", checksum: hex.EncodeToString(sum[:]), want: "new binary"},
+		{desc: "upper case checksum", checksum: strings.ToUpper(hex.EncodeToString(sum[:])), want: "new binary"},
+		{desc: "checksum mismatch", checksum: strings.Repeat("0", 64), want: "old binary", wantErr: errChecksumMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "kustomize-dot")
+			writeTestFile(t, path, "old binary")
+
+			err := replaceExecutable(context.Background(), asset, tc.checksum, path)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading executable failed: %s", err)
+			}
+			if string(data) != tc.want {
+				t.Fatalf("want executable %q, got %q", tc.want, data)
+			}
+			if tc.wantErr == nil && runtime.GOOS != "windows" {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("stat failed: %s", err)
+				}
+				if info.Mode().Perm() != 0o755 {
+					t.Fatalf("want executable mode 0755, got %s", info.Mode().Perm())
+				}
+			}
+
+			// The downloaded binary is not left behind
+			for _, name := range dirEntries(t, dir) {
+				if strings.HasSuffix(name, ".tmp") {
+					t.Fatalf("want no temporary files, got %s", name)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveExecutable(t *testing.T) {
+	type testCase struct {
+		desc      string
+		locked    bool
+		previous  bool
+		missing   bool
+		want      string
+		wantOld   string
+		wantError bool
+	}
+
+	testCases := []testCase{
+		{desc: "replaced", want: "new"},
+		{desc: "locked", locked: true, want: "new", wantOld: "current"},
+		{desc: "locked with previous update", locked: true, previous: true, want: "new", wantOld: "current"},
+		{desc: "missing release binary", missing: true, want: "current", wantError: true},
+		{desc: "locked and missing release binary restores the executable", locked: true, missing: true, want: "current", wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "kustomize-dot")
+			src := filepath.Join(dir, "release")
+			writeTestFile(t, path, "current")
+			if !tc.missing {
+				writeTestFile(t, src, "new")
+			}
+			if tc.previous {
+				writeTestFile(t, path+".old", "previous")
+			}
+
+			err := moveExecutable(src, path, tc.locked)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("want error %t, got %v", tc.wantError, err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading executable failed: %s", err)
+			}
+			if string(data) != tc.want {
+				t.Fatalf("want executable %q, got %q", tc.want, data)
+			}
+
+			old, err := os.ReadFile(path + ".old")
+			switch {
+			case tc.wantOld == "" && !errors.Is(err, os.ErrNotExist):
+				t.Fatalf("want no previous executable, got %q, %v", old, err)
+			case tc.wantOld != "" && string(old) != tc.wantOld:
+				t.Fatalf("want previous executable %q, got %q, %v", tc.wantOld, old, err)
+			}
+		})
+	}
+}
+
+func TestSelfUpdateCommand(t *testing.T) {
+	type testCase struct {
+		desc     string
+		tag      string
+		args     []string
+		wantOut  string
+		wantMsgs string
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "up to date",
+			tag:      "v" + version,
+			wantMsgs: "kustomize-dot " + version + " is up to date\n",
+		},
+		{
+			desc:    "check",
+			tag:     "v99.0.0",
+			args:    []string{"--check"},
+			wantOut: "kustomize-dot v99.0.0 is available, current version is " + version + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			server := newTestReleaseServer(t, tc.tag, "binary")
+			url := latestReleaseURL
+			latestReleaseURL = server.URL + "/latest"
+			t.Cleanup(func() {
+				latestReleaseURL = url
+			})
+
+			out, msgs, err := runApp(t, append([]string{"self-update"}, tc.args...)...)
+			if err != nil {
+				t.Fatalf("self-update failed: %s", err)
+			}
+			if out != tc.wantOut {
+				t.Fatalf("want output %q, got %q", tc.wantOut, out)
+			}
+			if msgs != tc.wantMsgs {
+				t.Fatalf("want messages %q, got %q", tc.wantMsgs, msgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,679 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// errNotTerminal is returned when the terminal UI is not run in a terminal,
+// which can be put in raw mode.
+var errNotTerminal = errors.New("not a terminal")
+
+const (
+	// tuiEnter switches to the alternate screen and hides the cursor
+	tuiEnter = "\x1b[?1049h\x1b[?25l"
+
+	// tuiLeave shows the cursor and switches back to the main screen
+	tuiLeave = "\x1b[?25h\x1b[?1049l"
+
+	// tuiClear moves the cursor home and clears the screen
+	tuiClear = "\x1b[H\x1b[2J"
+
+	// tuiReverse and tuiReset switch the reverse video on and off
+	tuiReverse = "\x1b[7m"
+	tuiReset   = "\x1b[0m"
+)
+
+// tuiHelp describes the keys of the terminal UI.
+const tuiHelp = `Keys:
+  up, k / down, j      move the selection
+  pgup / pgdn          move the selection by a page
+  home, g / end, G     select the first or the last row
+  right, l, enter      expand the origin and dependencies of the selection
+  left, h              collapse the selection, or select its parent
+  f                    focus the view on the selection
+  u                    remove the focus
+  r                    remove all filters and the focus
+  :                    run a command, e.g. :filter drop-kind Secret or
+                       :export graph.svg, see :help for the commands
+  ?                    show this help
+  q, ctrl+c            quit
+
+Press any key to return.`
+
+// tuiRow is a row of the tree shown by the terminal UI.
+type tuiRow struct {
+	// path identifies the row by the rows leading to it
+	path string
+
+	// depth is the nesting depth of the row
+	depth int
+
+	// text is the text of the row
+	text string
+
+	// vertex is the vertex of the row, which can be expanded. It is empty
+	// for the rows describing the provenance of a resource.
+	vertex string
+}
+
+// tui is the terminal UI for exploring resources. It shows the resources in
+// the view as a tree, whose rows expand into the origin, transformations and
+// dependencies of each resource, where the dependencies expand in turn. The
+// filters, focus and export of the view are the ones of the [explorer].
+type tui struct {
+	// explorer manages the filters and the focus of the view
+	explorer *explorer
+
+	// output captures the output of the explorer commands
+	output *bytes.Buffer
+
+	// graph is the graph of the view
+	graph *parser.Graph
+
+	// provenances contains the provenance of the resources by name
+	provenances map[string]*parser.Provenance
+
+	// rows contains the visible rows of the tree
+	rows []tuiRow
+
+	// expanded contains the paths of the expanded rows
+	expanded map[string]bool
+
+	// cursor is the index of the selected row, and offset is the index
+	// of the first row on screen
+	cursor int
+	offset int
+
+	// width and height are the size of the terminal
+	width  int
+	height int
+
+	// status is the message shown at the bottom of the screen
+	status string
+
+	// message is the text shown instead of the tree until the next key
+	// press, e.g. the help
+	message string
+
+	// command is the command being typed, when in command mode
+	command     []rune
+	commandMode bool
+}
+
+// newTUICommand returns the command for exploring resources using an
+// interactive terminal UI.
+func newTUICommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:   "tui",
+		Usage:  "explore the resources using an interactive terminal UI",
+		Action: execTUICommand,
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:     "file",
+				Usage:    "file containing the Kubernetes resources",
+				Required: true,
+				Aliases:  []string{"f"},
+			},
+		},
+	}
+
+	return cmd
+}
+
+// execTUICommand runs the terminal UI for exploring resources, which reads
+// the keys from stdin in raw mode.
+func execTUICommand(ctx *cli.Context) (err error) {
+	resources, err := readResources(ctx.Path("file"))
+	if err != nil {
+		return err
+	}
+	t, err := newTUI(resources)
+	if err != nil {
+		return err
+	}
+
+	restore, err := makeRaw()
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stdout, tuiEnter)
+	defer func() {
+		fmt.Fprint(os.Stdout, tuiLeave)
+		if restoreErr := restore(); err == nil {
+			err = restoreErr
+		}
+	}()
+
+	return t.run(os.Stdin, os.Stdout, terminalSize)
+}
+
+// stty runs stty with the given arguments on the terminal of stdin.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errNotTerminal, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// makeRaw puts the terminal of stdin in raw mode, and returns the function,
+// which restores its previous state.
+func makeRaw() (func() error, error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+
+	restore := func() error {
+		_, err := stty(state)
+		return err
+	}
+
+	return restore, nil
+}
+
+// terminalSize returns the number of rows and columns of the terminal of
+// stdin, or the size of a classic terminal, when it is unknown.
+func terminalSize() (int, int) {
+	out, err := stty("size")
+	if err != nil {
+		return 24, 80
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 24, 80
+	}
+	rows, err1 := strconv.Atoi(fields[0])
+	cols, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || rows <= 0 || cols <= 0 {
+		return 24, 80
+	}
+
+	return rows, cols
+}
+
+// readKey reads the next key from the given reader, i.e. a character, or the
+// name of a special key, e.g. up or enter, which are sent as escape
+// sequences or control characters.
+func readKey(r *bufio.Reader) (string, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return "enter", nil
+	case 0x7f, '\b':
+		return "backspace", nil
+	case 0x03:
+		return "ctrl+c", nil
+	case '\t':
+		return "tab", nil
+	case 0x1b:
+	default:
+		return string(c), nil
+	}
+
+	// A lone escape is not followed by the rest of a sequence
+	if r.Buffered() == 0 {
+		return "esc", nil
+	}
+	if next, _ := r.Peek(1); next[0] != '[' && next[0] != 'O' {
+		return "esc", nil
+	}
+	if _, err := r.ReadByte(); err != nil {
+		return "", err
+	}
+
+	// The sequence ends with a letter or a tilde, e.g. [A or [5~
+	var seq strings.Builder
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		seq.WriteByte(b)
+		if b == '~' || unicode.IsLetter(rune(b)) {
+			break
+		}
+	}
+
+	keys := map[string]string{
+		"A":  "up",
+		"B":  "down",
+		"C":  "right",
+		"D":  "left",
+		"H":  "home",
+		"F":  "end",
+		"1~": "home",
+		"4~": "end",
+		"5~": "pgup",
+		"6~": "pgdn",
+	}
+	if key, ok := keys[seq.String()]; ok {
+		return key, nil
+	}
+
+	return "esc", nil
+}
+
+// newTUI returns the terminal UI for exploring the given resources.
+func newTUI(resources []*resource.Resource) (*tui, error) {
+	provenances, err := parser.New().ProvenanceOfResources(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	output := new(bytes.Buffer)
+	t := &tui{
+		explorer: &explorer{
+			resources:  resources,
+			filters:    make([]explorerFilter, 0),
+			focusDepth: 1,
+			out:        output,
+		},
+		output:      output,
+		provenances: make(map[string]*parser.Provenance, len(provenances)),
+		rows:        make([]tuiRow, 0),
+		expanded:    make(map[string]bool),
+		height:      24,
+		width:       80,
+	}
+	for _, pv := range provenances {
+		t.provenances[pv.Resource] = pv
+	}
+	t.refresh()
+
+	return t, nil
+}
+
+// run draws the terminal UI to the given writer, and handles the keys read
+// from the given reader until quit, or the end of input. The size function
+// returns the number of rows and columns of the terminal.
+func (t *tui) run(in io.Reader, out io.Writer, size func() (int, int)) error {
+	r := bufio.NewReader(in)
+	for {
+		t.height, t.width = size()
+		if _, err := io.WriteString(out, t.draw()); err != nil {
+			return err
+		}
+
+		key, err := readKey(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t.handle(key) {
+			return nil
+		}
+	}
+}
+
+// refresh parses the graph of the view, and rebuilds the rows of the tree,
+// keeping the selected row, if it is still part of the view.
+func (t *tui) refresh() {
+	g, err := t.explorer.parser().ParseGraph(t.explorer.resources)
+	if err != nil {
+		t.status = fmt.Sprintf("error: %s", err)
+		return
+	}
+	t.graph = g
+	t.rebuild()
+}
+
+// rebuild rebuilds the rows of the tree from the graph of the view.
+func (t *tui) rebuild() {
+	selected := ""
+	if t.cursor < len(t.rows) {
+		selected = t.rows[t.cursor].path
+	}
+
+	t.rows = t.rows[:0]
+	for _, name := range t.resources() {
+		t.addRow(name, name, name, 0)
+	}
+
+	t.cursor = min(t.cursor, max(len(t.rows)-1, 0))
+	for i, row := range t.rows {
+		if row.path == selected {
+			t.cursor = i
+			break
+		}
+	}
+}
+
+// resources returns the names of the resources in the view in sorted order.
+func (t *tui) resources() []string {
+	names := make([]string, 0)
+	for _, name := range t.graph.GetVertexValues() {
+		if _, ok := t.provenances[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// neighbours returns the vertices, which the given vertex depends on, and
+// the vertices, which depend on it, in sorted order.
+func (t *tui) neighbours(vertex string) ([]string, []string) {
+	outgoing := make([]string, 0)
+	incoming := make([]string, 0)
+	for _, edge := range t.graph.GetEdges() {
+		switch vertex {
+		case edge.From:
+			outgoing = append(outgoing, edge.To)
+		case edge.To:
+			incoming = append(incoming, edge.From)
+		}
+	}
+	sort.Strings(outgoing)
+	sort.Strings(incoming)
+
+	return outgoing, incoming
+}
+
+// addRow adds the row with the given path, text and vertex, followed by the
+// rows of its provenance and dependencies, when it is expanded.
+func (t *tui) addRow(path string, vertex string, text string, depth int) {
+	t.rows = append(t.rows, tuiRow{path: path, depth: depth, text: text, vertex: vertex})
+	if vertex == "" || !t.expanded[path] {
+		return
+	}
+
+	if pv, ok := t.provenances[vertex]; ok {
+		origin := "unknown"
+		if pv.Origin != nil {
+			origin = describeOrigin(pv.Origin)
+		}
+		t.addRow(path+"\norigin", "", "origin: "+origin, depth+1)
+		for i, tr := range pv.Transformations {
+			t.addRow(fmt.Sprintf("%s\ntransformation/%d", path, i), "", "transformed by: "+describeOrigin(tr), depth+1)
+		}
+	}
+
+	outgoing, incoming := t.neighbours(vertex)
+	for _, v := range outgoing {
+		t.addRow(path+"\n-> "+v, v, "-> "+v, depth+1)
+	}
+	for _, v := range incoming {
+		t.addRow(path+"\n<- "+v, v, "<- "+v, depth+1)
+	}
+}
+
+// selected returns the selected row, if any.
+func (t *tui) selected() (tuiRow, bool) {
+	if t.cursor < 0 || t.cursor >= len(t.rows) {
+		return tuiRow{}, false
+	}
+
+	return t.rows[t.cursor], true
+}
+
+// pageSize returns the number of rows of the tree on screen.
+func (t *tui) pageSize() int {
+	// The header and the status line are not part of the tree
+	return max(t.height-2, 1)
+}
+
+// move moves the selection by the given number of rows.
+func (t *tui) move(n int) {
+	t.cursor = max(min(t.cursor+n, len(t.rows)-1), 0)
+}
+
+// handle handles the given key, and returns true, when the terminal UI is
+// to quit.
+func (t *tui) handle(key string) bool {
+	if t.commandMode {
+		return t.handleCommand(key)
+	}
+	if t.message != "" {
+		t.message = ""
+		return false
+	}
+
+	t.status = ""
+	switch key {
+	case "up", "k":
+		t.move(-1)
+	case "down", "j":
+		t.move(1)
+	case "pgup":
+		t.move(-t.pageSize())
+	case "pgdn":
+		t.move(t.pageSize())
+	case "home", "g":
+		t.cursor = 0
+	case "end", "G":
+		t.move(len(t.rows))
+	case "right", "l", "enter":
+		if row, ok := t.selected(); ok && row.vertex != "" {
+			t.expanded[row.path] = true
+			t.rebuild()
+		}
+	case "left", "h":
+		row, ok := t.selected()
+		if !ok {
+			break
+		}
+		if t.expanded[row.path] {
+			delete(t.expanded, row.path)
+			t.rebuild()
+			break
+		}
+		// Select the parent row
+		for i := t.cursor - 1; i >= 0; i-- {
+			if t.rows[i].depth < row.depth {
+				t.cursor = i
+				break
+			}
+		}
+	case "f":
+		if row, ok := t.selected(); ok && row.vertex != "" {
+			t.explorer.focus = row.vertex
+			t.explorer.focusDepth = 1
+			t.status = "focused on " + row.vertex
+			t.refresh()
+		}
+	case "u":
+		return t.exec("unfocus")
+	case "r":
+		t.status = "removed all filters and the focus"
+		return t.exec("reset")
+	case ":":
+		t.commandMode = true
+		t.command = t.command[:0]
+	case "?":
+		t.message = tuiHelp
+	case "q", "ctrl+c":
+		return true
+	}
+
+	return false
+}
+
+// handleCommand handles the given key in command mode, and returns true,
+// when the terminal UI is to quit.
+func (t *tui) handleCommand(key string) bool {
+	switch key {
+	case "esc", "ctrl+c":
+		t.commandMode = false
+	case "backspace":
+		if len(t.command) > 0 {
+			t.command = t.command[:len(t.command)-1]
+		}
+	case "enter":
+		t.commandMode = false
+		return t.exec(string(t.command))
+	case "tab":
+	default:
+		if r, _ := utf8.DecodeRuneInString(key); utf8.RuneCountInString(key) == 1 && unicode.IsPrint(r) {
+			t.command = append(t.command, r)
+		}
+	}
+
+	return false
+}
+
+// exec executes the given explorer command, and returns true, when the
+// terminal UI is to quit. The output of the command is shown in the status
+// line, or instead of the tree, when it spans multiple lines.
+func (t *tui) exec(line string) bool {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "quit" || args[0] == "exit" || args[0] == "q" {
+		return true
+	}
+
+	t.output.Reset()
+	if err := t.explorer.exec(args[0], args[1:]); err != nil {
+		t.status = fmt.Sprintf("error: %s", err)
+		return false
+	}
+
+	output := strings.TrimRight(t.output.String(), "\n")
+	switch {
+	case strings.Contains(output, "\n"):
+		t.message = output + "\n\nPress any key to return."
+	case output != "":
+		t.status = output
+	}
+	t.refresh()
+
+	return false
+}
+
+// header returns the header of the screen, which describes the view.
+func (t *tui) header() string {
+	items := []string{
+		fmt.Sprintf("kustomize-dot: %d of %d resources in view", len(t.resources()), len(t.provenances)),
+	}
+	for _, f := range t.explorer.filters {
+		items = append(items, f.name+" "+f.value)
+	}
+	if t.explorer.focus != "" {
+		items = append(items, fmt.Sprintf("focus %s %d", t.explorer.focus, t.explorer.focusDepth))
+	}
+
+	return strings.Join(items, " | ")
+}
+
+// fit returns the given line truncated or padded to the width of the
+// terminal.
+func (t *tui) fit(line string) string {
+	runes := []rune(line)
+	if len(runes) > t.width {
+		return string(runes[:t.width])
+	}
+
+	return line + strings.Repeat(" ", t.width-len(runes))
+}
+
+// draw returns the screen of the terminal UI, i.e. the header, the rows of
+// the tree around the selection, or the message, and the status line.
+func (t *tui) draw() string {
+	var b strings.Builder
+	b.WriteString(tuiClear)
+	b.WriteString(tuiReverse + t.fit(t.header()) + tuiReset + "\r\n")
+
+	page := t.pageSize()
+	lines := make([]string, 0, page)
+	switch {
+	case t.message != "":
+		lines = append(lines, strings.Split(t.message, "\n")...)
+	case len(t.rows) == 0:
+		lines = append(lines, "no resources in view, press r to remove all filters")
+	default:
+		// Scroll the selection into view
+		t.offset = min(t.offset, t.cursor)
+		t.offset = max(t.offset, t.cursor-page+1)
+		for i := t.offset; i < min(t.offset+page, len(t.rows)); i++ {
+			row := t.rows[i]
+			marker := "  "
+			if row.vertex != "" {
+				marker = "+ "
+				if t.expanded[row.path] {
+					marker = "- "
+				}
+			}
+			line := t.fit(strings.Repeat("  ", row.depth) + marker + row.text)
+			if i == t.cursor {
+				line = tuiReverse + line + tuiReset
+			}
+			lines = append(lines, line)
+		}
+	}
+	for i := 0; i < page; i++ {
+		line := ""
+		if i < len(lines) {
+			line = lines[i]
+		}
+		if !strings.HasPrefix(line, tuiReverse) {
+			line = t.fit(line)
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	status := t.status
+	switch {
+	case t.commandMode:
+		status = ":" + string(t.command)
+	case status == "":
+		status = "? help, : command, q quit"
+	}
+	b.WriteString(t.fit(status))
+
+	return b.String()
+}
@@ -0,0 +1,362 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+)
+
+const (
+	testConfigMap  = "default/configmap/the-map"
+	testDeployment = "default/deployment/the-deployment"
+	testService    = "default/service/the-service"
+)
+
+func newTestTUI(t *testing.T) *tui {
+	t.Helper()
+
+	resources, err := parser.ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	ui, err := newTUI(resources)
+	if err != nil {
+		t.Fatalf("creating terminal UI failed: %s", err)
+	}
+
+	return ui
+}
+
+func rowTexts(ui *tui) []string {
+	texts := make([]string, 0, len(ui.rows))
+	for _, row := range ui.rows {
+		texts = append(texts, strings.Repeat("  ", row.depth)+row.text)
+	}
+
+	return texts
+}
+
+func TestReadKey(t *testing.T) {
+	type testCase struct {
+		desc  string
+		input string
+		want  []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:  "characters",
+			input: "jk:?",
+			want:  []string{"j", "k", ":", "?"},
+		},
+		{
+			desc:  "control characters",
+			input: "\r\n\x7f\b\x03\t",
+			want:  []string{"enter", "enter", "backspace", "backspace", "ctrl+c", "tab"},
+		},
+		{
+			desc:  "arrows",
+			input: "\x1b[A\x1b[B\x1b[C\x1b[D",
+			want:  []string{"up", "down", "right", "left"},
+		},
+		{
+			desc:  "home, end and pages",
+			input: "\x1b[H\x1b[F\x1b[1~\x1b[4~\x1b[5~\x1b[6~\x1bOA",
+			want:  []string{"home", "end", "home", "end", "pgup", "pgdn", "up"},
+		},
+		{
+			desc:  "escape",
+			input: "\x1bq\x1b[2~\x1b",
+			want:  []string{"esc", "q", "esc", "esc"},
+		},
+		{
+			desc:  "unicode",
+			input: "ä",
+			want:  []string{"ä"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.input))
+			got := make([]string, 0)
+			for {
+				key, err := readKey(r)
+				if err != nil {
+					break
+				}
+				got = append(got, key)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want keys %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTUIExpand(t *testing.T) {
+	ui := newTestTUI(t)
+
+	want := []string{testConfigMap, testDeployment, testService}
+	if got := rowTexts(ui); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want rows %q, got %q", want, got)
+	}
+
+	// Expand the deployment, and the file it originates from
+	ui.handle("down")
+	ui.handle("right")
+	ui.handle("down")
+	ui.handle("down")
+	ui.handle("enter")
+	want = []string{
+		testConfigMap,
+		testDeployment,
+		"  origin: examples/helloWorld/deployment.yaml in https://github.com/kubernetes-sigs/kustomize@v1.0.6",
+		"  -> examples/helloWorld/deployment.yaml",
+		"    <- " + testDeployment,
+		testService,
+	}
+	if got := rowTexts(ui); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want rows %q, got %q", want, got)
+	}
+
+	// Leaves cannot be expanded, and collapsing a leaf selects its parent
+	ui.handle("up")
+	ui.handle("l")
+	if got := len(ui.rows); got != len(want) {
+		t.Fatalf("want %d rows after expanding a leaf, got %d", len(want), got)
+	}
+	ui.handle("h")
+	if row, _ := ui.selected(); row.text != testDeployment {
+		t.Fatalf("want %s selected, got %s", testDeployment, row.text)
+	}
+
+	// Collapsing the deployment hides all of its rows
+	ui.handle("left")
+	want = []string{testConfigMap, testDeployment, testService}
+	if got := rowTexts(ui); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want rows %q, got %q", want, got)
+	}
+	if row, _ := ui.selected(); row.text != testDeployment {
+		t.Fatalf("want %s selected, got %s", testDeployment, row.text)
+	}
+}
+
+func TestTUIMove(t *testing.T) {
+	type testCase struct {
+		desc string
+		keys []string
+		want int
+	}
+
+	testCases := []testCase{
+		{desc: "down", keys: []string{"down", "j"}, want: 2},
+		{desc: "past the last row", keys: []string{"j", "j", "j", "j"}, want: 2},
+		{desc: "past the first row", keys: []string{"up", "k"}, want: 0},
+		{desc: "end and home", keys: []string{"G", "g"}, want: 0},
+		{desc: "end", keys: []string{"end"}, want: 2},
+		{desc: "pages", keys: []string{"pgdn", "pgup", "pgdn"}, want: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ui := newTestTUI(t)
+			for _, key := range tc.keys {
+				ui.handle(key)
+			}
+			if ui.cursor != tc.want {
+				t.Fatalf("want cursor %d, got %d", tc.want, ui.cursor)
+			}
+		})
+	}
+}
+
+func TestTUICommand(t *testing.T) {
+	type testCase struct {
+		desc       string
+		keys       string
+		wantRows   []string
+		wantStatus string
+		wantQuit   bool
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "filter",
+			keys:     ":filter drop-kind ConfigMap\r",
+			wantRows: []string{testDeployment, testService},
+		},
+		{
+			desc:     "backspace",
+			keys:     ":filter drop-kind ConfigMapx\x7f\r",
+			wantRows: []string{testDeployment, testService},
+		},
+		{
+			desc:     "cancelled",
+			keys:     ":filter drop-kind ConfigMap\x1b",
+			wantRows: []string{testConfigMap, testDeployment, testService},
+		},
+		{
+			desc:     "reset",
+			keys:     ":filter drop-kind ConfigMap\rr",
+			wantRows: []string{testConfigMap, testDeployment, testService},
+		},
+		{
+			desc:       "invalid",
+			keys:       ":bogus\r",
+			wantRows:   []string{testConfigMap, testDeployment, testService},
+			wantStatus: "error: unknown command: bogus",
+		},
+		{
+			desc:     "quit",
+			keys:     ":quit\r",
+			wantRows: []string{testConfigMap, testDeployment, testService},
+			wantQuit: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ui := newTestTUI(t)
+			r := bufio.NewReader(strings.NewReader(tc.keys))
+			quit := false
+			for !quit {
+				key, err := readKey(r)
+				if err != nil {
+					break
+				}
+				quit = ui.handle(key)
+			}
+
+			if quit != tc.wantQuit {
+				t.Fatalf("want quit %t, got %t", tc.wantQuit, quit)
+			}
+			if got := rowTexts(ui); !reflect.DeepEqual(got, tc.wantRows) {
+				t.Fatalf("want rows %q, got %q", tc.wantRows, got)
+			}
+			if tc.wantStatus != "" && ui.status != tc.wantStatus {
+				t.Fatalf("want status %q, got %q", tc.wantStatus, ui.status)
+			}
+		})
+	}
+}
+
+func TestTUIFocus(t *testing.T) {
+	ui := newTestTUI(t)
+
+	ui.handle("G")
+	ui.handle("f")
+	if ui.explorer.focus != testService {
+		t.Fatalf("want focus on %s, got %q", testService, ui.explorer.focus)
+	}
+	want := []string{testService}
+	if got := rowTexts(ui); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want rows %q, got %q", want, got)
+	}
+	if !strings.Contains(ui.header(), "focus "+testService+" 1") {
+		t.Fatalf("want header describing the focus, got %q", ui.header())
+	}
+
+	ui.handle("u")
+	want = []string{testConfigMap, testDeployment, testService}
+	if got := rowTexts(ui); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want rows %q, got %q", want, got)
+	}
+	if row, _ := ui.selected(); row.text != testService {
+		t.Fatalf("want %s selected after unfocus, got %s", testService, row.text)
+	}
+}
+
+func TestTUIExport(t *testing.T) {
+	ui := newTestTUI(t)
+	path := filepath.Join(t.TempDir(), "graph.dot")
+
+	for _, key := range []string{":", "f", "i", "l", "t", "e", "r", " ", "d", "r", "o", "p", "-", "k", "i", "n", "d", " ", "S", "e", "r", "v", "i", "c", "e", "enter"} {
+		ui.handle(key)
+	}
+	ui.exec("export " + path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading exported view failed: %s", err)
+	}
+	if !strings.Contains(string(data), testDeployment) || strings.Contains(string(data), testService) {
+		t.Fatalf("want exported view without the service, got %q", data)
+	}
+	if !strings.Contains(ui.status, path) {
+		t.Fatalf("want status describing the export, got %q", ui.status)
+	}
+}
+
+func TestTUIRun(t *testing.T) {
+	ui := newTestTUI(t)
+
+	var out bytes.Buffer
+	size := func() (int, int) { return 4, 40 }
+	if err := ui.run(strings.NewReader("?x:list\rxjjq"), &out, size); err != nil {
+		t.Fatalf("running terminal UI failed: %s", err)
+	}
+
+	screens := strings.Split(out.String(), tuiClear)[1:]
+	if len(screens) != 12 {
+		t.Fatalf("want 12 screens, got %d", len(screens))
+	}
+	for i, screen := range screens {
+		if lines := strings.Split(screen, "\r\n"); len(lines) != 4 {
+			t.Fatalf("want screen %d with 4 lines, got %q", i, lines)
+		}
+	}
+
+	for _, tc := range []struct {
+		screen int
+		want   string
+	}{
+		{screen: 0, want: "3 of 3 resources in view"},
+		{screen: 0, want: tuiReverse + "+ " + testConfigMap},
+		{screen: 1, want: "Keys:"},
+		{screen: 2, want: "? help, : command, q quit"},
+		{screen: 7, want: ":list"},
+		{screen: 8, want: "   1  " + testConfigMap},
+	} {
+		if !strings.Contains(screens[tc.screen], tc.want) {
+			t.Fatalf("want screen %d containing %q, got %q", tc.screen, tc.want, screens[tc.screen])
+		}
+	}
+
+	// The selection scrolls into view
+	if ui.cursor != 2 || ui.offset != 1 {
+		t.Fatalf("want cursor 2 at offset 1, got cursor %d at offset %d", ui.cursor, ui.offset)
+	}
+}
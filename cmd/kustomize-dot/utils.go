@@ -26,29 +26,149 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/dnaeon/kustomize-dot/pkg/parser"
 	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/kustomize/api/resource"
 )
 
 // errUnsupportedLayout is returned when the app was called with invalid layout
 // direction.
 var errUnsupportedLayout = errors.New("unsupported graph layout")
 
+// errUnsupportedFilterPrecedence is returned when the app was called with
+// invalid filter precedence.
+var errUnsupportedFilterPrecedence = errors.New("unsupported filter precedence")
+
+// errUnsupportedTheme is returned when the app was called with an unknown
+// theme.
+var errUnsupportedTheme = errors.New("unsupported theme")
+
+// errUnsupportedLegendPosition is returned when the app was called with an
+// unknown legend position.
+var errUnsupportedLegendPosition = errors.New("unsupported legend position")
+
+// errInvalidRatio is returned when the app was called with an invalid aspect
+// ratio.
+var errInvalidRatio = errors.New("invalid ratio")
+
+// errUnsupportedThresholdAction is returned when the app was called with an
+// unsupported on-exceed action.
+var errUnsupportedThresholdAction = errors.New("unsupported on-exceed action")
+
+// errUnsupportedAutoColor is returned when the app was called with an
+// unknown automatic color assignment.
+var errUnsupportedAutoColor = errors.New("unsupported auto color")
+
+// errConflictingGraphModes is returned when the app was called with more than
+// one graph mode.
+var errConflictingGraphModes = errors.New("conflicting graph modes")
+
+// errConflictingFilters is returned when the app was called with filters,
+// which contradict each other.
+var errConflictingFilters = errors.New("conflicting filters")
+
 // errInvalidKV is an error which is returned when attempting to parse an
 // invalid key/value pair.
 var errInvalidKV = errors.New("invalid key/value pair")
 
+// errInvalidRegexp is an error which is returned when attempting to compile an
+// invalid regular expression.
+var errInvalidRegexp = errors.New("invalid regular expression")
+
+// errInvalidNodeAttributes is an error which is returned when attempting to
+// parse invalid node attributes.
+var errInvalidNodeAttributes = errors.New("invalid node attributes")
+
+// errWatchStdin is returned when the app was asked to watch resources, which
+// are read from stdin.
+var errWatchStdin = errors.New("cannot watch resources read from stdin")
+
+// errInvalidWatchInterval is returned when the app was called with a
+// non-positive watch interval.
+var errInvalidWatchInterval = errors.New("invalid watch interval")
+
+// errOpenStdout is returned when the app was asked to open the output, which
+// is written to stdout.
+var errOpenStdout = errors.New("cannot open output written to stdout")
+
+// errInvalidTimeout is returned when the app was called with a negative
+// timeout.
+var errInvalidTimeout = errors.New("invalid timeout")
+
+// errCancelled is returned when generating the graph was cancelled.
+var errCancelled = errors.New("cancelled")
+
+// errTimeout is the cause of cancellation, when generating the graph took
+// longer than the timeout.
+var errTimeout = errors.New("exceeded timeout")
+
+// errInterrupted is the cause of cancellation, when the app was interrupted.
+var errInterrupted = errors.New("interrupted")
+
+// errMultipleStdout is returned when more than one output of the graph is
+// written to stdout.
+var errMultipleStdout = errors.New("only one output may be written to stdout")
+
+// errExplainOutputs is returned when the app was asked to explain the filter
+// decisions, and write them to multiple outputs.
+var errExplainOutputs = errors.New("cannot write explanations to multiple outputs")
+
+// errOpenSplit is returned when the app was asked to open the output, which
+// is split into separate graphs.
+var errOpenSplit = errors.New("cannot open output split into separate graphs")
+
+// errUnsupportedSplitBy is returned when the app was asked to split the graph
+// by an unsupported property.
+var errUnsupportedSplitBy = errors.New("unsupported split by")
+
+// errEmptyGraph is returned when the app was asked to fail on an empty graph,
+// and the generated graph has no vertices.
+var errEmptyGraph = errors.New("graph is empty")
+
+// errPluginOutputStdout is returned when the app runs as plugin, and was asked
+// to write the graph file to stdout, which contains the resources.
+var errPluginOutputStdout = errors.New("cannot write the graph file of the plugin to stdout")
+
+// errMissingLegacyConfig is returned when the app runs as legacy exec plugin,
+// and the path to the plugin config was not specified.
+var errMissingLegacyConfig = errors.New("missing config file of the legacy plugin")
+
+// errTempFile is returned when no temporary file could be created for writing
+// the output.
+var errTempFile = errors.New("cannot create temporary file")
+
+// messages is the writer for warnings and progress messages, which are not
+// part of the output. Messages are discarded in quiet mode.
+var messages io.Writer = os.Stderr
+
 // kvSeparator is the separator used to parse key/value pairs from a string,
 // e.g. foo=bar, bar=baz, etc.
 const kvSeparator = "="
 
-// getLayoutDirection returns the graph layout direction from the CLI context
-func getLayoutDirection(ctx *cli.Context) (parser.LayoutDirection, error) {
+// kindSeparator is the separator between the kind and the attributes of the
+// node attributes, e.g. Deployment:shape=component.
+const kindSeparator = ":"
+
+// getLayoutDirection returns the graph layout direction from the given option
+// source
+func getLayoutDirection(src optionSource) (parser.LayoutDirection, error) {
 	supportedLayouts := []parser.LayoutDirection{
 		parser.LayoutDirectionBT,
 		parser.LayoutDirectionTB,
@@ -56,7 +176,7 @@ func getLayoutDirection(ctx *cli.Context) (parser.LayoutDirection, error) {
 		parser.LayoutDirectionRL,
 	}
 
-	layout := parser.LayoutDirection(ctx.String("layout"))
+	layout := parser.LayoutDirection(src.String("layout"))
 	if !slices.Contains(supportedLayouts, layout) {
 		return parser.LayoutDirection(""), fmt.Errorf("%w: %s", errUnsupportedLayout, layout)
 	}
@@ -64,6 +184,193 @@ func getLayoutDirection(ctx *cli.Context) (parser.LayoutDirection, error) {
 	return layout, nil
 }
 
+// getFilterPrecedence returns the filter precedence from the given value
+func getFilterPrecedence(value string) (parser.FilterPrecedence, error) {
+	supported := []parser.FilterPrecedence{
+		parser.FilterPrecedenceOptions,
+		parser.FilterPrecedenceRules,
+	}
+
+	precedence := parser.FilterPrecedence(value)
+	if !slices.Contains(supported, precedence) {
+		return parser.FilterPrecedence(""), fmt.Errorf("%w: %s", errUnsupportedFilterPrecedence, value)
+	}
+
+	return precedence, nil
+}
+
+// getTheme returns the theme from the given value
+func getTheme(value string) (parser.Theme, error) {
+	theme := parser.Theme(value)
+	if !slices.Contains(parser.Themes(), theme) {
+		return parser.Theme(""), fmt.Errorf("%w: %s", errUnsupportedTheme, value)
+	}
+
+	return theme, nil
+}
+
+// getAutoColor returns the automatic color assignment from the given value
+func getAutoColor(value string) (parser.AutoColor, error) {
+	ac := parser.AutoColor(value)
+	if !slices.Contains(parser.AutoColors(), ac) {
+		return parser.AutoColor(""), fmt.Errorf("%w: %s", errUnsupportedAutoColor, value)
+	}
+
+	return ac, nil
+}
+
+// getRatio returns the aspect ratio from the given value, which is either one
+// of the predefined ratios, or a positive number.
+func getRatio(value string) (parser.Ratio, error) {
+	ratio := parser.Ratio(value)
+	if slices.Contains(parser.Ratios(), ratio) {
+		return ratio, nil
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil && n > 0 {
+		return ratio, nil
+	}
+
+	return parser.Ratio(""), fmt.Errorf("%w: %s", errInvalidRatio, value)
+}
+
+// legendOff is the value, which disables the legend.
+const legendOff = "off"
+
+// getLegendPosition returns the position of the legend from the given value,
+// or an empty position, when the legend is disabled. The true and false
+// values are accepted as well, since the legend used to be a boolean option.
+func getLegendPosition(value string) (parser.LegendPosition, error) {
+	switch value {
+	case "", legendOff, "false":
+		return parser.LegendPosition(""), nil
+	case "true":
+		return parser.LegendPositionBottom, nil
+	}
+
+	position := parser.LegendPosition(value)
+	if !slices.Contains(parser.LegendPositions(), position) {
+		return parser.LegendPosition(""), fmt.Errorf("%w: %s", errUnsupportedLegendPosition, value)
+	}
+
+	return position, nil
+}
+
+// getThresholdAction returns the action taken, when the graph is too large,
+// from the given value
+func getThresholdAction(value string) (parser.ThresholdAction, error) {
+	action := parser.ThresholdAction(value)
+	if !slices.Contains(parser.ThresholdActions(), action) {
+		return parser.ThresholdAction(""), fmt.Errorf("%w: %s", errUnsupportedThresholdAction, value)
+	}
+
+	return action, nil
+}
+
+// getSplitBy returns the property, by which the graph is split, from the
+// given value
+func getSplitBy(value string) (parser.SplitBy, error) {
+	sb := parser.SplitBy(value)
+	if !slices.Contains(parser.SplitBys(), sb) {
+		return parser.SplitBy(""), fmt.Errorf("%w: %s", errUnsupportedSplitBy, value)
+	}
+
+	return sb, nil
+}
+
+// splitFileName returns the file name without extension of the graph of the
+// given group, which is safe to use on any platform, e.g. the repo
+// https://github.com/org/repo becomes github.com_org_repo.
+func splitFileName(group string) string {
+	if _, rest, ok := strings.Cut(group, "://"); ok {
+		group = rest
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, group)
+}
+
+// formatExtension returns the file extension of the given output format
+func formatExtension(format parser.Format) string {
+	if format == parser.FormatMermaid {
+		return ".mmd"
+	}
+
+	return "." + format.String()
+}
+
+// extensionFormat returns the output format, which corresponds to the file
+// extension of the given path.
+func extensionFormat(path string) (parser.Format, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, format := range parser.Formats() {
+		if formatExtension(format) == ext {
+			return format, true
+		}
+	}
+
+	return parser.Format(""), false
+}
+
+// getOutputPaths returns the paths of the outputs from the CLI context.
+// Only one of the outputs may be written to stdout.
+func getOutputPaths(ctx *cli.Context) ([]string, error) {
+	paths := ctx.StringSlice("output")
+	if len(paths) == 0 {
+		return []string{"-"}, nil
+	}
+
+	stdout := 0
+	for _, path := range paths {
+		if path == "" || path == "-" {
+			stdout++
+		}
+	}
+	if stdout > 1 {
+		return nil, errMultipleStdout
+	}
+
+	return paths, nil
+}
+
+// newSourceLinkTemplate returns the source link template for the given value,
+// which is either the name of a supported forge, i.e. github or gitlab, or a
+// custom template.
+func newSourceLinkTemplate(value string) (*parser.SourceLinkTemplate, error) {
+	forges := map[string]string{
+		"github": parser.SourceLinkGitHub,
+		"gitlab": parser.SourceLinkGitLab,
+	}
+
+	if text, ok := forges[value]; ok {
+		value = text
+	}
+
+	return parser.NewSourceLinkTemplate(value)
+}
+
+// getGraphMode returns the graph mode from the given option source
+func getGraphMode(src optionSource) (parser.GraphMode, error) {
+	originsOnly := src.Bool("origins-only")
+	noOrigins := src.Bool("no-origins")
+
+	switch {
+	case originsOnly && noOrigins:
+		return parser.GraphMode(""), fmt.Errorf("%w: %s and %s", errConflictingGraphModes, src.optionName("origins-only"), src.optionName("no-origins"))
+	case originsOnly:
+		return parser.GraphModeOrigins, nil
+	case noOrigins:
+		return parser.GraphModeResources, nil
+	default:
+		return parser.GraphModeFull, nil
+	}
+}
+
 // kv represents a key/value pair.
 type kv struct {
 	key string
@@ -85,3 +392,405 @@ func parseKV(values ...string) ([]*kv, error) {
 
 	return pairs, nil
 }
+
+// parseRegexpColors parses the given regular expression/color pairs, e.g.
+// .*-prod.*=red. Since regular expressions may contain the separator, the
+// pairs are split at the last separator.
+func parseRegexpColors(values ...string) ([]*kv, error) {
+	pairs := make([]*kv, 0)
+	for _, val := range values {
+		i := strings.LastIndex(val, kvSeparator)
+		if i <= 0 || i == len(val)-1 {
+			return nil, fmt.Errorf("%w: %s", errInvalidKV, val)
+		}
+		pair := &kv{key: val[:i], val: val[i+1:]}
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
+
+// parseGraphAttributes parses the Dot attributes from the given values, e.g.
+// splines=ortho. Since the values of slice flags are split at commas, items
+// without a key/value separator are appended to the value of the preceding
+// attribute, so that attributes with comma-separated values, e.g.
+// size=7.5,10, are supported.
+func parseGraphAttributes(values ...string) ([]*kv, error) {
+	pairs := make([]*kv, 0)
+	for _, val := range values {
+		k, v, ok := strings.Cut(val, kvSeparator)
+		if !ok {
+			if len(pairs) == 0 {
+				return nil, fmt.Errorf("%w: %s", errInvalidKV, val)
+			}
+			pairs[len(pairs)-1].val += "," + val
+			continue
+		}
+		if k == "" {
+			return nil, fmt.Errorf("%w: %s", errInvalidKV, val)
+		}
+		pairs = append(pairs, &kv{key: k, val: v})
+	}
+
+	return pairs, nil
+}
+
+// nodeAttributes represents the Dot attributes of the resources of a kind.
+type nodeAttributes struct {
+	kind  string
+	attrs map[string]string
+}
+
+// parseNodeAttributes parses the kinds and their Dot attributes from the given
+// values, e.g. Deployment:style=filled,shape=component. Since the values of
+// slice flags are split at commas, items without a kind are joined with the
+// preceding value, and items without a key/value separator are appended to
+// the value of the preceding attribute, so that attributes with
+// comma-separated values, e.g. style=filled,rounded, are supported.
+func parseNodeAttributes(values ...string) ([]*nodeAttributes, error) {
+	result := make([]*nodeAttributes, 0)
+	var current *nodeAttributes
+	last := ""
+	for _, value := range values {
+		kind, rest, ok := strings.Cut(value, kindSeparator)
+		if ok && !strings.Contains(kind, kvSeparator) {
+			if kind == "" || rest == "" {
+				return nil, fmt.Errorf("%w: %s", errInvalidNodeAttributes, value)
+			}
+			current = &nodeAttributes{kind: kind, attrs: make(map[string]string)}
+			result = append(result, current)
+			last = ""
+		} else {
+			rest = value
+		}
+		if current == nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidNodeAttributes, value)
+		}
+
+		for _, item := range strings.Split(rest, ",") {
+			k, v, ok := strings.Cut(item, kvSeparator)
+			if !ok {
+				if last == "" {
+					return nil, fmt.Errorf("%w: %s", errInvalidNodeAttributes, value)
+				}
+				current.attrs[last] += "," + item
+				continue
+			}
+			k = strings.TrimSpace(k)
+			if k == "" {
+				return nil, fmt.Errorf("%w: %s", errInvalidNodeAttributes, value)
+			}
+			current.attrs[k] = v
+			last = k
+		}
+	}
+
+	return result, nil
+}
+
+// compileRegexps compiles the given regular expressions.
+func compileRegexps(values ...string) ([]*regexp.Regexp, error) {
+	result := make([]*regexp.Regexp, 0, len(values))
+	for _, val := range values {
+		re, err := regexp.Compile(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", errInvalidRegexp, val, err)
+		}
+		result = append(result, re)
+	}
+
+	return result, nil
+}
+
+// filterPair represents a pair of keep and drop filters of the same kind.
+type filterPair struct {
+	// name is the name of the filters used in error messages
+	name string
+
+	// keep contains the values of the keep filter
+	keep []string
+
+	// drop contains the values of the drop filter
+	drop []string
+
+	// foldCase specifies whether values are compared case-insensitively
+	foldCase bool
+}
+
+// checkConflictingFilters returns an error, if any value is both kept and
+// dropped by the same pair of filters.
+func checkConflictingFilters(pairs ...filterPair) error {
+	for _, pair := range pairs {
+		for _, val := range pair.keep {
+			conflicts := func(other string) bool {
+				return other == val || (pair.foldCase && strings.EqualFold(other, val))
+			}
+			if slices.ContainsFunc(pair.drop, conflicts) {
+				return fmt.Errorf("%w: %s: %s", errConflictingFilters, pair.name, val)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeOutput calls the write function with the output identified by the given
+// path. When the path is empty or "-", the output is written to stdout.
+// Otherwise the output is written to a temporary file in the same directory,
+// which atomically replaces the file at the given path once the write
+// function succeeds, so that readers never observe a partially written file.
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(os.Stdout)
+	}
+
+	// New files are created subject to the umask, while existing files
+	// keep their mode once replaced.
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	tmp, err := createTempFile(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if info != nil {
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// createTempFile creates a new temporary file in the directory of the given
+// path. Unlike [os.CreateTemp] the file is created with mode 0666 before the
+// umask, which is the mode of files created by [os.Create].
+func createTempFile(path string) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for i := 0; i < 10000; i++ {
+		name := fmt.Sprintf(".%s.%d.%d.tmp", base, os.Getpid(), time.Now().UnixNano()+int64(i))
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+
+	return nil, fmt.Errorf("%w: %s", errTempFile, path)
+}
+
+// fingerprint returns a value, which changes whenever the file at the given
+// path, or any file within the directory at the given path is modified.
+func fingerprint(path string) (string, error) {
+	var sb strings.Builder
+	walkFunc := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	}
+
+	if err := filepath.WalkDir(path, walkFunc); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// watchPath calls fn once, and then again whenever the file or directory at
+// the given path changes, until the context is cancelled. The path is polled
+// at the given interval. Errors returned by fn are reported on stderr, so that
+// watching continues while the resources are being edited.
+func watchPath(ctx context.Context, path string, interval time.Duration, fn func() error) error {
+	if path == "-" {
+		return errWatchStdin
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%w: %s", errInvalidWatchInterval, interval)
+	}
+
+	last, err := fingerprint(path)
+	if err != nil {
+		return err
+	}
+
+	run := func() {
+		if err := fn(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		}
+	}
+	run()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current, err := fingerprint(path)
+			if err != nil {
+				// The file may be missing for a moment, while an
+				// editor is replacing it.
+				continue
+			}
+			if current == last {
+				continue
+			}
+			last = current
+			run()
+		}
+	}
+}
+
+// readResources reads the Kubernetes resources from the file at the given
+// path, or from stdin when the path is "-".
+func readResources(path string) ([]*resource.Resource, error) {
+	if path == "-" {
+		// Special case for resources passed on stdin
+		return parser.ResourcesFromReader(os.Stdin)
+	}
+
+	return parser.ResourcesFromPath(path)
+}
+
+// readResourcesContext is like readResources, but returns early with an
+// error, when the given context is cancelled, e.g. while waiting for the
+// resources on stdin.
+func readResourcesContext(ctx context.Context, path string) ([]*resource.Resource, error) {
+	type result struct {
+		resources []*resource.Resource
+		err       error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		resources, err := readResources(path)
+		ch <- result{resources, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		return res.resources, res.err
+	}
+}
+
+// openFile opens the file at the given path with the default application of
+// the desktop, without waiting for the application to exit.
+func openFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
+
+// newDebugLogger returns a [slog.Logger], which logs messages at debug level to
+// stderr.
+func newDebugLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(messages, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// printMessage writes a warning or progress message to stderr, unless the app
+// runs in quiet mode.
+func printMessage(format string, args ...any) {
+	fmt.Fprintf(messages, format+"\n", args...)
+}
+
+// newQuietFlag returns the flag, which enables quiet mode.
+func newQuietFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "quiet",
+		Usage:   "suppress warnings and progress messages, so that stdout contains only the output",
+		Value:   false,
+		EnvVars: []string{"KUSTOMIZE_DOT_QUIET"},
+	}
+}
+
+// addQuietFlag adds the quiet flag to the given commands and their
+// subcommands, so that quiet mode may be enabled after the command name as
+// well, since flags of the app are not accepted by the commands.
+func addQuietFlag(cmds []*cli.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags = append(slices.Clone(cmd.Flags), newQuietFlag())
+		before := cmd.Before
+		cmd.Before = func(ctx *cli.Context) error {
+			if err := setupQuiet(ctx); err != nil {
+				return err
+			}
+			if before != nil {
+				return before(ctx)
+			}
+
+			return nil
+		}
+		addQuietFlag(cmd.Subcommands)
+	}
+}
+
+// setupQuiet discards all messages, which are not part of the output,
+// including debug logs, when the app runs in quiet mode.
+func setupQuiet(ctx *cli.Context) error {
+	if ctx.Bool("quiet") {
+		messages = io.Discard
+	}
+
+	return nil
+}
+
+// writeExplanations writes whether each resource is kept by the filters of the
+// parser, and which of the filters decided so, to the given output.
+func writeExplanations(output string, p *parser.Parser, resources []*resource.Resource) error {
+	explanations, err := p.Explain(resources)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(output, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, e := range explanations {
+			status := "dropped"
+			if e.Kept {
+				status = "kept"
+			}
+			filters := "-"
+			if len(e.Filters) > 0 {
+				filters = strings.Join(e.Filters, ", ")
+			}
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", status, e.Resource, filters); err != nil {
+				return err
+			}
+		}
+		return tw.Flush()
+	})
+}
@@ -0,0 +1,730 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"github.com/dnaeon/kustomize-dot/pkg/parser"
+)
+
+// dirEntries returns the names of the files in the given directory.
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading %s failed: %s", dir, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+func TestWriteOutput(t *testing.T) {
+	errWrite := errors.New("write failed")
+
+	// New files get the mode of files created by os.Create
+	dir := t.TempDir()
+	ref, err := os.Create(filepath.Join(dir, "ref"))
+	if err != nil {
+		t.Fatalf("creating file failed: %s", err)
+	}
+	ref.Close()
+	info, err := os.Stat(ref.Name())
+	if err != nil {
+		t.Fatalf("stat failed: %s", err)
+	}
+	createMode := info.Mode().Perm()
+
+	type testCase struct {
+		desc     string
+		existing string
+		mode     fs.FileMode
+		write    string
+		writeErr error
+		want     string
+		wantMode fs.FileMode
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "new file",
+			write:    "graph",
+			want:     "graph",
+			wantMode: createMode,
+		},
+		{
+			desc:     "existing file keeps its mode",
+			existing: "old graph",
+			mode:     0o600,
+			write:    "graph",
+			want:     "graph",
+			wantMode: 0o600,
+		},
+		{
+			desc:     "failed write keeps the existing file",
+			existing: "old graph",
+			mode:     0o640,
+			write:    "partial",
+			writeErr: errWrite,
+			want:     "old graph",
+			wantMode: 0o640,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "graph.dot")
+			if tc.existing != "" {
+				if err := os.WriteFile(path, []byte(tc.existing), tc.mode); err != nil {
+					t.Fatalf("writing existing file failed: %s", err)
+				}
+				if err := os.Chmod(path, tc.mode); err != nil {
+					t.Fatalf("chmod failed: %s", err)
+				}
+			}
+
+			err := writeOutput(path, func(w io.Writer) error {
+				if _, err := io.WriteString(w, tc.write); err != nil {
+					return err
+				}
+				// Readers see the previous file until the write
+				// completes.
+				if data, err := os.ReadFile(path); err == nil && string(data) != tc.existing {
+					t.Fatalf("want %q while writing, got %q", tc.existing, data)
+				}
+				return tc.writeErr
+			})
+			if !errors.Is(err, tc.writeErr) {
+				t.Fatalf("want error %v, got %v", tc.writeErr, err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading output failed: %s", err)
+			}
+			if string(data) != tc.want {
+				t.Fatalf("want output %q, got %q", tc.want, data)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("stat failed: %s", err)
+			}
+			if got := info.Mode().Perm(); got != tc.wantMode {
+				t.Fatalf("want mode %s, got %s", tc.wantMode, got)
+			}
+
+			// No temporary files are left behind
+			if names := dirEntries(t, dir); len(names) != 1 || names[0] != "graph.dot" {
+				t.Fatalf("want only graph.dot in the output directory, got %q", names)
+			}
+		})
+	}
+}
+
+func TestWriteOutputMissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "graph.dot")
+	err := writeOutput(path, func(w io.Writer) error {
+		t.Fatal("want write function not called")
+		return nil
+	})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want error %v, got %v", os.ErrNotExist, err)
+	}
+}
+
+func TestCreateTempFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "graph.dot")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		f, err := createTempFile(path)
+		if err != nil {
+			t.Fatalf("creating temporary file failed: %s", err)
+		}
+		f.Close()
+
+		if filepath.Dir(f.Name()) != dir {
+			t.Fatalf("want temporary file in %s, got %s", dir, f.Name())
+		}
+		base := filepath.Base(f.Name())
+		if !strings.HasPrefix(base, ".graph.dot.") || !strings.HasSuffix(base, ".tmp") {
+			t.Fatalf("want hidden temporary file named after graph.dot, got %s", base)
+		}
+		if seen[base] {
+			t.Fatalf("want unique temporary files, got %s twice", base)
+		}
+		seen[base] = true
+	}
+}
+
+func TestGenerateOutput(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc string
+		args []string
+		env  string
+	}
+
+	testCases := []testCase{
+		{desc: "long flag", args: []string{"--output"}},
+		{desc: "short flag", args: []string{"-o"}},
+		{desc: "environment variable", env: "KUSTOMIZE_DOT_OUTPUT"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "graph.dot")
+			args := []string{"generate", "-f", resources}
+			if tc.env != "" {
+				t.Setenv(tc.env, path)
+			} else {
+				args = append(args, tc.args[0], path)
+			}
+
+			out, _, err := runApp(t, args...)
+			if err != nil {
+				t.Fatalf("generating graph failed: %s", err)
+			}
+			if out != "" {
+				t.Fatalf("want nothing written to stdout, got %q", out)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading output failed: %s", err)
+			}
+			if !strings.HasPrefix(string(data), "strict digraph") {
+				t.Fatalf("want graph written to %s, got %q", path, data)
+			}
+		})
+	}
+}
+
+// waitFor polls the given condition until it holds, or fails the test after
+// a few seconds.
+func waitFor(t *testing.T, desc string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "base", "resources.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("creating directory failed: %s", err)
+	}
+	if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+		t.Fatalf("writing file failed: %s", err)
+	}
+
+	before, err := fingerprint(dir)
+	if err != nil {
+		t.Fatalf("fingerprint failed: %s", err)
+	}
+	again, err := fingerprint(dir)
+	if err != nil {
+		t.Fatalf("fingerprint failed: %s", err)
+	}
+	if before != again {
+		t.Fatalf("want unchanged fingerprint, got %q and %q", before, again)
+	}
+
+	// A nested file is modified
+	if err := os.WriteFile(path, []byte("ab"), 0o644); err != nil {
+		t.Fatalf("writing file failed: %s", err)
+	}
+	after, err := fingerprint(dir)
+	if err != nil {
+		t.Fatalf("fingerprint failed: %s", err)
+	}
+	if before == after {
+		t.Fatalf("want changed fingerprint, got %q", after)
+	}
+
+	if _, err := fingerprint(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want error %v, got %v", os.ErrNotExist, err)
+	}
+}
+
+func TestWatchPathErrors(t *testing.T) {
+	path := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc     string
+		path     string
+		interval time.Duration
+		wantErr  error
+	}
+
+	testCases := []testCase{
+		{desc: "stdin", path: "-", interval: time.Second, wantErr: errWatchStdin},
+		{desc: "zero interval", path: path, interval: 0, wantErr: errInvalidWatchInterval},
+		{desc: "negative interval", path: path, interval: -time.Second, wantErr: errInvalidWatchInterval},
+		{desc: "missing file", path: path + ".missing", interval: time.Second, wantErr: os.ErrNotExist},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := watchPath(context.Background(), tc.path, tc.interval, func() error {
+				t.Fatal("want function not called")
+				return nil
+			})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWatchPath(t *testing.T) {
+	path := writeTestResources(t, "a")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		done <- watchPath(ctx, path, 10*time.Millisecond, func() error {
+			// Errors are reported, and watching continues
+			if calls.Add(1) == 2 {
+				return errors.New("invalid resources")
+			}
+			return nil
+		})
+	}()
+
+	waitFor(t, "the first run", func() bool { return calls.Load() == 1 })
+
+	// Unchanged files are not regenerated
+	time.Sleep(50 * time.Millisecond)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("want 1 run of the unchanged file, got %d", got)
+	}
+
+	for i, data := range []string{"ab", "abc"} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("writing file failed: %s", err)
+		}
+		want := int32(i + 2)
+		waitFor(t, fmt.Sprintf("run %d", want), func() bool { return calls.Load() == want })
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("want no error once cancelled, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watching to stop")
+	}
+}
+
+func TestGenerateWatch(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+	output := filepath.Join(t.TempDir(), "graph.dot")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	readOutput := func() string {
+		data, _ := os.ReadFile(output)
+		return string(data)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := runAppContext(ctx, t, "generate", "-f", resources, "-o", output, "--watch", "--watch-interval", "10ms")
+		done <- err
+	}()
+
+	waitFor(t, "the graph", func() bool {
+		return strings.Contains(readOutput(), "default/service/the-service")
+	})
+
+	// The service is removed from the resources
+	data := strings.Split(fixtures.HelloWorld, "\n---\n")
+	kept := make([]string, 0, len(data))
+	for _, doc := range data {
+		if !strings.Contains(doc, "kind: Service") {
+			kept = append(kept, doc)
+		}
+	}
+	if err := os.WriteFile(resources, []byte(strings.Join(kept, "\n---\n")), 0o644); err != nil {
+		t.Fatalf("writing resources failed: %s", err)
+	}
+
+	waitFor(t, "the regenerated graph", func() bool {
+		out := readOutput()
+		return strings.Contains(out, "default/deployment/the-deployment") && !strings.Contains(out, "default/service/the-service")
+	})
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("want no error once cancelled, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watching to stop")
+	}
+}
+
+// fakeOpener replaces the default applications of the desktop with a script,
+// which records the opened files, and returns the function, which waits for
+// the given number of files to be opened, and returns them.
+func fakeOpener(t *testing.T) func(n int) []string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("opening files is not faked on windows")
+	}
+
+	dir := t.TempDir()
+	opened := filepath.Join(dir, "opened")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$1\" >> %q\n", opened)
+	for _, name := range []string{"xdg-open", "open"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatalf("writing %s failed: %s", name, err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	return func(n int) []string {
+		var paths []string
+		waitFor(t, fmt.Sprintf("%d opened files", n), func() bool {
+			data, _ := os.ReadFile(opened)
+			paths = strings.Fields(string(data))
+			return len(paths) >= n
+		})
+		slices.Sort(paths)
+		return paths
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	opened := fakeOpener(t)
+
+	path := filepath.Join(t.TempDir(), "graph.svg")
+	if err := openFile(path); err != nil {
+		t.Fatalf("opening file failed: %s", err)
+	}
+	if got := opened(1); !slices.Equal(got, []string{path}) {
+		t.Fatalf("want %s opened, got %q", path, got)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err := openFile(path); !errors.Is(err, exec.ErrNotFound) {
+		t.Fatalf("want error %v, got %v", exec.ErrNotFound, err)
+	}
+}
+
+func TestGenerateOpen(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc    string
+		outputs []string
+		args    []string
+		env     map[string]string
+		want    []string
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			desc:    "flag",
+			outputs: []string{"graph.dot"},
+			args:    []string{"--open"},
+			want:    []string{"graph.dot"},
+		},
+		{
+			desc:    "environment variable",
+			outputs: []string{"graph.dot"},
+			env:     map[string]string{"KUSTOMIZE_DOT_OPEN": "true"},
+			want:    []string{"graph.dot"},
+		},
+		{
+			desc:    "multiple outputs",
+			outputs: []string{"graph.dot", "graph.json"},
+			args:    []string{"--open"},
+			want:    []string{"graph.dot", "graph.json"},
+		},
+		{
+			desc:    "stdout",
+			outputs: []string{"-"},
+			args:    []string{"--open"},
+			wantErr: errOpenStdout,
+		},
+		{
+			desc:    "split",
+			outputs: []string{"graph.dot"},
+			args:    []string{"--open", "--split-by", "namespace"},
+			wantErr: errOpenSplit,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			opened := fakeOpener(t)
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			dir := t.TempDir()
+			args := append([]string{"generate", "-f", resources}, tc.args...)
+			for _, output := range tc.outputs {
+				if output != "-" {
+					output = filepath.Join(dir, output)
+				}
+				args = append(args, "-o", output)
+			}
+
+			_, _, err := runApp(t, args...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+
+			want := make([]string, 0, len(tc.want))
+			for _, name := range tc.want {
+				path := filepath.Join(dir, name)
+				if _, err := os.Stat(path); err != nil {
+					t.Fatalf("want %s written before opened, got %s", name, err)
+				}
+				want = append(want, path)
+			}
+			if got := opened(len(want)); !slices.Equal(got, want) {
+				t.Fatalf("want %q opened, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestExtensionFormat(t *testing.T) {
+	type testCase struct {
+		path   string
+		want   parser.Format
+		wantOK bool
+	}
+
+	testCases := []testCase{
+		{path: "graph.dot", want: parser.FormatDot, wantOK: true},
+		{path: "graph.SVG", want: parser.FormatSVG, wantOK: true},
+		{path: "out/graph.png", want: parser.FormatPNG, wantOK: true},
+		{path: "graph.mmd", want: parser.FormatMermaid, wantOK: true},
+		{path: "graph.json", want: parser.FormatJSON, wantOK: true},
+		{path: "graph.mermaid", wantOK: false},
+		{path: "graph", wantOK: false},
+		{path: "-", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, ok := extensionFormat(tc.path)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("want %q, %t, got %q, %t", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestGetOutputPaths(t *testing.T) {
+	type testCase struct {
+		desc    string
+		args    []string
+		want    []string
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{desc: "default", want: []string{"-"}},
+		{desc: "single", args: []string{"--output", "graph.dot"}, want: []string{"graph.dot"}},
+		{
+			desc: "repeated",
+			args: []string{"--output", "graph.dot", "--output", "graph.svg", "--output", "-"},
+			want: []string{"graph.dot", "graph.svg", "-"},
+		},
+		{
+			desc:    "multiple stdout",
+			args:    []string{"--output", "-", "--output", "graph.dot", "--output", ""},
+			wantErr: errMultipleStdout,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := getOutputPaths(newTestContext(t, newGenerateCommand(), tc.args...))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr == nil && !slices.Equal(got, tc.want) {
+				t.Fatalf("want paths %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateOutputs(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+
+	type testCase struct {
+		desc    string
+		outputs []string
+		args    []string
+		want    map[string]string
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			desc:    "formats from extensions",
+			outputs: []string{"graph.dot", "graph.json", "graph.mmd"},
+			want: map[string]string{
+				"graph.dot":  "strict digraph",
+				"graph.json": "{",
+				"graph.mmd":  "flowchart",
+			},
+		},
+		{
+			desc:    "unknown extension",
+			outputs: []string{"graph.gv", "graph.json"},
+			want: map[string]string{
+				"graph.gv":   "strict digraph",
+				"graph.json": "{",
+			},
+		},
+		{
+			desc:    "format flag",
+			outputs: []string{"graph.dot", "graph.json"},
+			args:    []string{"--format", "mermaid"},
+			want: map[string]string{
+				"graph.dot":  "flowchart",
+				"graph.json": "flowchart",
+			},
+		},
+		{
+			desc:    "split",
+			outputs: []string{"graph.dot", "graph.json"},
+			args:    []string{"--split-by", "namespace"},
+			want: map[string]string{
+				"default.dot":  "strict digraph",
+				"default.json": "{",
+			},
+		},
+		{
+			desc:    "multiple stdout",
+			outputs: []string{"-", "-"},
+			wantErr: errMultipleStdout,
+		},
+		{
+			desc:    "explain",
+			outputs: []string{"graph.dot", "graph.json"},
+			args:    []string{"--explain"},
+			wantErr: errExplainOutputs,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			args := []string{"generate", "-f", resources, "--output-dir", dir}
+			for _, output := range tc.outputs {
+				if output != "-" {
+					output = filepath.Join(dir, output)
+				}
+				args = append(args, "-o", output)
+			}
+
+			_, _, err := runApp(t, append(args, tc.args...)...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+
+			for name, prefix := range tc.want {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("reading %s failed: %s", name, err)
+				}
+				if !strings.HasPrefix(string(data), prefix) {
+					t.Fatalf("want %s starting with %q, got %q", name, prefix, data)
+				}
+			}
+			if got := len(dirEntries(t, dir)); got != len(tc.want) {
+				t.Fatalf("want %d files written, got %d", len(tc.want), got)
+			}
+		})
+	}
+}
+
+func TestGenerateOutputsParseOnce(t *testing.T) {
+	resources := writeTestResources(t, fixtures.HelloWorld)
+	dir := t.TempDir()
+
+	_, msgs, err := runApp(t, "generate", "-f", resources, "--debug",
+		"-o", filepath.Join(dir, "graph.dot"),
+		"-o", filepath.Join(dir, "graph.json"),
+		"-o", filepath.Join(dir, "graph.mmd"),
+	)
+	if err != nil {
+		t.Fatalf("generating graph failed: %s", err)
+	}
+	if got := strings.Count(msgs, "parsing resources"); got != 1 {
+		t.Fatalf("want resources parsed once, got %d times in %q", got, msgs)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"reflect"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Change represents the kind of change of a resource compared to a previous
+// build.
+type Change string
+
+const (
+	// ChangeNone specifies that the resource is unchanged
+	ChangeNone Change = ""
+
+	// ChangeAdded specifies that the resource is not part of the previous
+	// build
+	ChangeAdded Change = "added"
+
+	// ChangeModified specifies that the resource is part of the previous
+	// build, but its content differs
+	ChangeModified Change = "modified"
+)
+
+// changeColors contains the colors of the outline of changed resources.
+var changeColors = map[Change]string{
+	ChangeAdded:    "darkgreen",
+	ChangeModified: "darkorange",
+}
+
+// changePenWidth is the width of the outline of changed resources.
+const changePenWidth = "3"
+
+// buildAnnotationPrefixes contains the prefixes of the annotations, which are
+// set by kustomize during the build, and are not part of the content of the
+// resources.
+var buildAnnotationPrefixes = []string{
+	"config.kubernetes.io/",
+	"internal.config.kubernetes.io/",
+	"config.k8s.io/",
+}
+
+// resourceContent returns the content of the given resource without the
+// annotations set by kustomize during the build, e.g. the origin annotation,
+// so that moving a resource between files is not considered a change.
+func resourceContent(r *resource.Resource) map[string]any {
+	m, err := r.Map()
+	if err != nil {
+		return nil
+	}
+
+	metadata, _ := m["metadata"].(map[string]any)
+	annotations, _ := metadata["annotations"].(map[string]any)
+	for k := range annotations {
+		for _, prefix := range buildAnnotationPrefixes {
+			if strings.HasPrefix(k, prefix) {
+				delete(annotations, k)
+				break
+			}
+		}
+	}
+	if annotations != nil && len(annotations) == 0 {
+		delete(metadata, "annotations")
+	}
+
+	return m
+}
+
+// changeDetector knows how to tell the changes of resources compared to a
+// previous build.
+type changeDetector struct {
+	// previous contains the content of the resources from the previous
+	// build by vertex name
+	previous map[string]map[string]any
+}
+
+// newChangeDetector creates a new [changeDetector] for the given resources
+// from the previous build.
+func (p *Parser) newChangeDetector(previous []*resource.Resource) *changeDetector {
+	cd := &changeDetector{
+		previous: make(map[string]map[string]any, len(previous)),
+	}
+	for _, r := range previous {
+		cd.previous[p.vertexNameFromResource(r)] = resourceContent(r)
+	}
+
+	return cd
+}
+
+// change returns the change of the resource represented by the given vertex
+// name compared to the previous build.
+func (cd *changeDetector) change(name string, r *resource.Resource) Change {
+	content, ok := cd.previous[name]
+	switch {
+	case !ok:
+		return ChangeAdded
+	case !reflect.DeepEqual(content, resourceContent(r)):
+		return ChangeModified
+	default:
+		return ChangeNone
+	}
+}
+
+// applyChange emphasizes the [graph.Vertex] u with a bold outline, if the
+// resource it represents was added or modified since the previous build.
+func applyChange(u *graph.Vertex[string], change Change) {
+	color, ok := changeColors[change]
+	if !ok {
+		return
+	}
+
+	u.DotAttributes["color"] = color
+	u.DotAttributes["penwidth"] = changePenWidth
+}
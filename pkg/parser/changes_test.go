@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestWithPreviousResources(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	// The previous build has no Deployment, a different Service, and the
+	// same ConfigMap coming from a different origin.
+	previous, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	kept := make([]*resource.Resource, 0)
+	for _, r := range previous {
+		switch r.GetKind() {
+		case "Deployment":
+			continue
+		case "Service":
+			if err := r.SetLabels(map[string]string{"app": "previous"}); err != nil {
+				t.Fatalf("setting labels failed: %s", err)
+			}
+		case "ConfigMap":
+			if err := r.SetOrigin(&resource.Origin{Path: "previous/configMap.yaml"}); err != nil {
+				t.Fatalf("setting origin failed: %s", err)
+			}
+		}
+		kept = append(kept, r)
+	}
+
+	type testCase struct {
+		vertex     string
+		wantChange Change
+	}
+
+	testCases := []testCase{
+		{vertex: "default/deployment/the-deployment", wantChange: ChangeAdded},
+		{vertex: "default/service/the-service", wantChange: ChangeModified},
+		{vertex: "default/configmap/the-map", wantChange: ChangeNone},
+	}
+
+	p := New(WithPreviousResources(kept))
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	for _, tc := range testCases {
+		attrs := g.GetVertex(tc.vertex).DotAttributes
+		if got := attrs["color"]; got != changeColors[tc.wantChange] {
+			t.Fatalf("want %s color %q, got %q", tc.vertex, changeColors[tc.wantChange], got)
+		}
+		wantPenWidth := changePenWidth
+		if tc.wantChange == ChangeNone {
+			wantPenWidth = ""
+		}
+		if got := attrs["penwidth"]; got != wantPenWidth {
+			t.Fatalf("want %s penwidth %q, got %q", tc.vertex, wantPenWidth, got)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// ErrGraphTooLarge is returned when the graph has more vertices or edges than
+// allowed, and the [ThresholdAction] is [ThresholdActionFail].
+var ErrGraphTooLarge = errors.New("graph too large")
+
+// ThresholdAction is a type which represents what is done, when the graph has
+// more vertices or edges than allowed.
+type ThresholdAction string
+
+// String implements the [fmt.Stringer] interface
+func (ta ThresholdAction) String() string {
+	return string(ta)
+}
+
+const (
+	// ThresholdActionFail specifies that parsing fails with
+	// [ErrGraphTooLarge]
+	ThresholdActionFail ThresholdAction = "fail"
+
+	// ThresholdActionCollapse specifies that the resources are collapsed
+	// into one vertex per namespace and kind
+	ThresholdActionCollapse ThresholdAction = "collapse"
+)
+
+// ThresholdActions returns the supported threshold actions.
+func ThresholdActions() []ThresholdAction {
+	return []ThresholdAction{
+		ThresholdActionFail,
+		ThresholdActionCollapse,
+	}
+}
+
+// collapsedAttributes contains the Dot attributes of resource vertices, which
+// describe a single resource, and are not carried over to the collapsed
+// vertices.
+var collapsedAttributes = []string{"tooltip", "href", "URL", "xlabel"}
+
+// collapseKey returns the namespace and kind part of the given resource vertex
+// name, e.g. default/deployment for default/deployment/web, and deployment
+// for the cluster-scoped deployment/web.
+func collapseKey(vertex string) string {
+	key, _, _ := strings.Cut(vertex, "/")
+	if parts := strings.SplitN(vertex, "/", 3); len(parts) == 3 {
+		key = parts[0] + "/" + parts[1]
+	}
+
+	return key
+}
+
+// collapseVertices replaces the vertices of the given mapping with one vertex
+// per key, named after the key and the number of vertices, e.g.
+// "default/deployment (3)". The edges of the vertices are moved to the
+// collapsed vertices, and the attributes of the first vertex with each key
+// are kept, so that highlights still apply.
+func collapseVertices(g *Graph, keys map[string]string) {
+	groups := make(map[string][]string)
+	for _, v := range g.GetVertexValues() {
+		if key, ok := keys[v]; ok {
+			groups[key] = append(groups[key], v)
+		}
+	}
+
+	names := make(map[string]string)
+	for _, key := range sortedKeys(groups) {
+		members := groups[key]
+		slices.Sort(members)
+		name := fmt.Sprintf("%s (%d)", key, len(members))
+		u := g.AddVertex(name)
+		u.DotAttributes = maps.Clone(g.GetVertex(members[0]).DotAttributes)
+		for _, attr := range collapsedAttributes {
+			delete(u.DotAttributes, attr)
+		}
+		u.DotAttributes["label"] = name
+		for _, v := range members {
+			names[v] = name
+		}
+	}
+
+	rename := func(v string) string {
+		if name, ok := names[v]; ok {
+			return name
+		}
+		return v
+	}
+	edges := make([]*graph.Edge[string], 0)
+	for _, e := range g.GetEdges() {
+		if names[e.From] != "" || names[e.To] != "" {
+			edges = append(edges, e)
+		}
+	}
+	for _, e := range edges {
+		from, to := rename(e.From), rename(e.To)
+		if from == to || g.EdgeExists(from, to) {
+			continue
+		}
+		g.AddEdge(from, to).DotAttributes = maps.Clone(e.DotAttributes)
+	}
+
+	for v := range names {
+		g.DeleteVertex(v)
+	}
+}
+
+// collapseResources collapses the given resource vertices into one vertex
+// per namespace and kind, and the origin files of the resources into one
+// vertex per directory, which summarizes large graphs.
+func collapseResources(g *Graph, resourceVertices map[string]bool) {
+	outgoing := make(map[string][]string)
+	isOriginFile := make(map[string]bool)
+	for _, e := range g.GetEdges() {
+		outgoing[e.From] = append(outgoing[e.From], e.To)
+		if resourceVertices[e.From] && !resourceVertices[e.To] {
+			isOriginFile[e.To] = true
+		}
+	}
+
+	keys := make(map[string]string)
+	for v := range resourceVertices {
+		keys[v] = collapseKey(v)
+	}
+
+	// Origin files are grouped by the directory they are in. When the
+	// origins are graphed as a hierarchy, the directory is the vertex the
+	// file is connected to, which is qualified with the repository of
+	// remote files.
+	for v := range isOriginFile {
+		switch out := outgoing[v]; len(out) {
+		case 0:
+			keys[v] = path.Join(path.Dir(v), "*")
+		case 1:
+			keys[v] = strings.TrimSuffix(out[0], "/") + "/*"
+		}
+	}
+
+	collapseVertices(g, keys)
+}
+
+// applyThresholds fails parsing, or collapses the resources, when the graph
+// has more vertices or edges than allowed.
+func (p *Parser) applyThresholds(g *Graph, resourceVertices map[string]bool) error {
+	vertices := len(g.GetVertices())
+	edges := len(g.GetEdges())
+	exceeded := (p.maxVertices > 0 && vertices > p.maxVertices) || (p.maxEdges > 0 && edges > p.maxEdges)
+	if !exceeded && !p.collapse {
+		return nil
+	}
+
+	if exceeded && p.thresholdAction == ThresholdActionFail {
+		limits := make([]string, 0, 2)
+		if p.maxVertices > 0 {
+			limits = append(limits, fmt.Sprintf("%d vertices", p.maxVertices))
+		}
+		if p.maxEdges > 0 {
+			limits = append(limits, fmt.Sprintf("%d edges", p.maxEdges))
+		}
+		return fmt.Errorf(
+			"%w: %d vertices and %d edges, while at most %s are allowed",
+			ErrGraphTooLarge, vertices, edges, strings.Join(limits, " and "),
+		)
+	}
+
+	p.logger.Debug("collapsing resources", "vertices", vertices, "edges", edges)
+	collapseResources(g, resourceVertices)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestCollapseKey(t *testing.T) {
+	testCases := map[string]string{
+		"default/deployment/web":       "default/deployment",
+		"clusterrole/prometheus":       "clusterrole",
+		"monitoring/configmap/a/b.txt": "monitoring/configmap",
+	}
+
+	for vertex, want := range testCases {
+		if got := collapseKey(vertex); got != want {
+			t.Fatalf("%s: want key %q, got %q", vertex, want, got)
+		}
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.KubePrometheus))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	full, err := New().ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+	vertices := len(full.GetVertices())
+
+	// The graph is within the thresholds
+	if _, err := New(WithMaxVertices(vertices)).ParseGraph(resources); err != nil {
+		t.Fatalf("want no error within thresholds, got %s", err)
+	}
+
+	// The graph exceeds the thresholds
+	_, err = New(WithMaxVertices(vertices - 1)).ParseGraph(resources)
+	if !errors.Is(err, ErrGraphTooLarge) {
+		t.Fatalf("want ErrGraphTooLarge, got %v", err)
+	}
+	_, err = New(WithMaxEdges(1)).ParseGraph(resources)
+	if !errors.Is(err, ErrGraphTooLarge) {
+		t.Fatalf("want ErrGraphTooLarge, got %v", err)
+	}
+
+	// The resources are collapsed instead
+	g, err := New(WithMaxVertices(vertices-1), WithThresholdAction(ThresholdActionCollapse)).ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+	if got := len(g.GetVertices()); got >= vertices {
+		t.Fatalf("want less than %d vertices after collapsing, got %d", vertices, got)
+	}
+	if !g.VertexExists("monitoring/deployment (5)") {
+		t.Fatalf("want collapsed vertex monitoring/deployment (5)")
+	}
+	if g.VertexExists("monitoring/deployment/grafana") {
+		t.Fatalf("want resource vertex monitoring/deployment/grafana to be collapsed")
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// AutoColor is a type which represents the property of resources, by which
+// they are automatically assigned a color.
+type AutoColor string
+
+// String implements the [fmt.Stringer] interface
+func (ac AutoColor) String() string {
+	return string(ac)
+}
+
+const (
+	// AutoColorNone specifies that resources are not colored automatically
+	AutoColorNone AutoColor = "none"
+
+	// AutoColorNamespaces specifies that each namespace is assigned a
+	// distinct color
+	AutoColorNamespaces AutoColor = "namespaces"
+
+	// AutoColorKinds specifies that each kind is assigned a distinct color
+	AutoColorKinds AutoColor = "kinds"
+)
+
+// AutoColors returns the supported automatic color assignments.
+func AutoColors() []AutoColor {
+	return []AutoColor{
+		AutoColorNone,
+		AutoColorNamespaces,
+		AutoColorKinds,
+	}
+}
+
+// autoColorPalette contains the colors, which are assigned automatically.
+// The colors are light enough for the labels to remain readable on top of
+// them.
+var autoColorPalette = []string{
+	"lightcoral",
+	"lightgoldenrod",
+	"palegreen",
+	"lightskyblue",
+	"plum",
+	"peachpuff",
+	"aquamarine",
+	"lightpink",
+	"khaki",
+	"powderblue",
+	"thistle",
+	"darkseagreen1",
+}
+
+// paletteColor returns the color of the palette, which is assigned to the
+// given value. The same value is always assigned the same color.
+func paletteColor(value string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(value)))
+
+	return autoColorPalette[h.Sum32()%uint32(len(autoColorPalette))]
+}
+
+// autoColorKey returns the value of the resource, by which it is assigned a
+// color. An empty value is returned for resources, which are not colored,
+// e.g. cluster-scoped resources, when coloring by namespace.
+func autoColorKey(ac AutoColor, r *resource.Resource) string {
+	switch ac {
+	case AutoColorNamespaces:
+		return r.GetNamespace()
+	case AutoColorKinds:
+		return r.GetKind()
+	default:
+		return ""
+	}
+}
+
+// luminanceThreshold is the relative luminance, below which a color is
+// considered dark. Black and white labels have the same contrast ratio
+// against colors with this luminance.
+const luminanceThreshold = 0.179
+
+// darkFontColor and lightFontColor are the colors of labels on top of light
+// and dark fill colors respectively.
+const (
+	darkFontColor  = "black"
+	lightFontColor = "white"
+)
+
+// parseColor returns the RGB value of the given Graphviz color, which is
+// either a name from the X11 color scheme, or in the #rrggbb form, optionally
+// followed by an alpha channel. The first color of color lists, e.g.
+// gradients, is used.
+func parseColor(color string) (uint32, bool) {
+	color, _, _ = strings.Cut(color, ":")
+	color, _, _ = strings.Cut(color, ";")
+	color = strings.ToLower(strings.TrimSpace(color))
+
+	if hex, ok := strings.CutPrefix(color, "#"); ok {
+		if len(hex) != 6 && len(hex) != 8 {
+			return 0, false
+		}
+		rgb, err := strconv.ParseUint(hex[:6], 16, 32)
+		if err != nil {
+			return 0, false
+		}
+		return uint32(rgb), true
+	}
+
+	rgb, ok := x11Colors[color]
+
+	return rgb, ok
+}
+
+// ValidColor reports whether the given value is a valid Graphviz color, or a
+// list of colors, e.g. a gradient. Besides the colors supported by
+// parseColor, the HSV form, colors of other schemes in the /scheme/name form,
+// and transparent are accepted.
+//
+// See https://graphviz.org/docs/attr-types/color/ for more details.
+func ValidColor(value string) bool {
+	for _, item := range strings.Split(value, ":") {
+		color, weight, ok := strings.Cut(item, ";")
+		if ok {
+			if _, err := strconv.ParseFloat(weight, 64); err != nil {
+				return false
+			}
+		}
+
+		color = strings.ToLower(strings.TrimSpace(color))
+		switch {
+		case color == "transparent", strings.HasPrefix(color, "/"):
+			continue
+		case validHSV(color):
+			continue
+		}
+		if _, ok := parseColor(color); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validHSV reports whether the given color is in the HSV form, i.e. three
+// numbers between 0 and 1, separated by commas or spaces.
+func validHSV(color string) bool {
+	fields := strings.FieldsFunc(color, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(fields) != 3 {
+		return false
+	}
+	for _, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil || v < 0 || v > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// relativeLuminance returns the relative luminance of the given RGB value.
+//
+// See https://www.w3.org/TR/WCAG21/#dfn-relative-luminance for more details.
+func relativeLuminance(rgb uint32) float64 {
+	channel := func(v uint32) float64 {
+		c := float64(v&0xff) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*channel(rgb>>16) + 0.7152*channel(rgb>>8) + 0.0722*channel(rgb)
+}
+
+// contrastFontColor returns the font color, which is readable on top of the
+// given fill color, and false if the fill color is not known.
+func contrastFontColor(fill string) (string, bool) {
+	rgb, ok := parseColor(fill)
+	if !ok {
+		return "", false
+	}
+
+	if relativeLuminance(rgb) < luminanceThreshold {
+		return lightFontColor, true
+	}
+
+	return darkFontColor, true
+}
+
+// applyFontContrast sets the font color of the vertices with a fill color of
+// their own, e.g. highlighted vertices, so that their labels remain readable.
+// Vertices with an explicit font color are left as is.
+func applyFontContrast(g *Graph) {
+	for _, v := range g.GetVertices() {
+		fill, ok := v.DotAttributes["fillcolor"]
+		if !ok {
+			continue
+		}
+		if _, ok := v.DotAttributes["fontcolor"]; ok {
+			continue
+		}
+		if color, ok := contrastFontColor(fill); ok {
+			v.DotAttributes["fontcolor"] = color
+		}
+	}
+}
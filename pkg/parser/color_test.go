@@ -0,0 +1,188 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestPaletteColor(t *testing.T) {
+	values := []string{"default", "kube-system", "monitoring", "Deployment"}
+	for _, value := range values {
+		color := paletteColor(value)
+		if !slices.Contains(autoColorPalette, color) {
+			t.Fatalf("color %q of %s is not part of the palette", color, value)
+		}
+		if got := paletteColor(value); got != color {
+			t.Fatalf("want stable color %q for %s, got %q", color, value, got)
+		}
+	}
+}
+
+func TestWithAutoColor(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		vertex    string
+		wantColor string
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "no auto color",
+			opts:      []Option{WithAutoColor(AutoColorNone)},
+			vertex:    "default/service/the-service",
+			wantColor: "",
+		},
+		{
+			desc:      "auto color namespaces",
+			opts:      []Option{WithAutoColor(AutoColorNamespaces)},
+			vertex:    "default/service/the-service",
+			wantColor: paletteColor("default"),
+		},
+		{
+			desc:      "auto color kinds",
+			opts:      []Option{WithAutoColor(AutoColorKinds)},
+			vertex:    "default/service/the-service",
+			wantColor: paletteColor("Service"),
+		},
+		{
+			desc:      "explicit highlight overrides auto color",
+			opts:      []Option{WithAutoColor(AutoColorNamespaces), WithHighlightKind("Service", "yellow")},
+			vertex:    "default/service/the-service",
+			wantColor: "yellow",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			got := g.GetVertex(tc.vertex).DotAttributes["fillcolor"]
+			if got != tc.wantColor {
+				t.Fatalf("want fillcolor %q, got %q", tc.wantColor, got)
+			}
+		})
+	}
+}
+
+func TestContrastFontColor(t *testing.T) {
+	type testCase struct {
+		fill      string
+		wantColor string
+		wantOk    bool
+	}
+
+	testCases := []testCase{
+		{fill: "yellow", wantColor: darkFontColor, wantOk: true},
+		{fill: "Navy", wantColor: lightFontColor, wantOk: true},
+		{fill: "#1e1e1e", wantColor: lightFontColor, wantOk: true},
+		{fill: "#ffffff80", wantColor: darkFontColor, wantOk: true},
+		{fill: "darkgreen:yellow", wantColor: lightFontColor, wantOk: true},
+		{fill: "black;0.5:white", wantColor: lightFontColor, wantOk: true},
+		{fill: "#12345", wantColor: "", wantOk: false},
+		{fill: "no-such-color", wantColor: "", wantOk: false},
+	}
+
+	for _, tc := range testCases {
+		got, ok := contrastFontColor(tc.fill)
+		if ok != tc.wantOk || got != tc.wantColor {
+			t.Fatalf("want font color %q (%t) for %s, got %q (%t)", tc.wantColor, tc.wantOk, tc.fill, got, ok)
+		}
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	type testCase struct {
+		color string
+		want  bool
+	}
+
+	testCases := []testCase{
+		{color: "yellow", want: true},
+		{color: "LightBlue", want: true},
+		{color: "#1e1e1e", want: true},
+		{color: "#ffffff80", want: true},
+		{color: "darkgreen:yellow", want: true},
+		{color: "black;0.5:white", want: true},
+		{color: "0.650 0.700 0.700", want: true},
+		{color: "0.5,0.5,0.5", want: true},
+		{color: "/accent3/1", want: true},
+		{color: "transparent", want: true},
+		{color: "", want: false},
+		{color: "#12345", want: false},
+		{color: "no-such-color", want: false},
+		{color: "red;heavy:blue", want: false},
+		{color: "1.5 0.5 0.5", want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := ValidColor(tc.color); got != tc.want {
+			t.Fatalf("want valid color %t for %q, got %t", tc.want, tc.color, got)
+		}
+	}
+}
+
+func TestWithFontContrast(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithFontContrast(),
+		WithHighlightKind("Deployment", "navy"),
+		WithHighlightKind("Service", "yellow"),
+		WithStyles(&StyleRule{Kind: "Service", FontColor: "red"}),
+	)
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantFontColors := map[string]string{
+		"default/deployment/the-deployment": lightFontColor,
+		"default/service/the-service":       "red",
+		"default/configmap/the-map":         "",
+	}
+	for vertex, want := range wantFontColors {
+		if got := g.GetVertex(vertex).DotAttributes["fontcolor"]; got != want {
+			t.Fatalf("want %s fontcolor %q, got %q", vertex, want, got)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// resourceDetails returns the key details of the given [resource.Resource],
+// such as the container images and replica count of workloads, or the type of
+// services.
+func resourceDetails(r *resource.Resource) []string {
+	details := make([]string, 0)
+
+	switch strings.ToLower(r.GetKind()) {
+	case "service":
+		svcType, err := r.GetString("spec.type")
+		if err != nil || svcType == "" {
+			svcType = "ClusterIP"
+		}
+		details = append(details, fmt.Sprintf("type: %s", svcType))
+	default:
+		if replicas, err := r.GetFieldValue("spec.replicas"); err == nil {
+			details = append(details, fmt.Sprintf("replicas: %v", replicas))
+		}
+		for _, image := range containerImages(r) {
+			details = append(details, fmt.Sprintf("image: %s", image))
+		}
+	}
+
+	return details
+}
+
+// resourceTooltip returns the tooltip of the given [resource.Resource], which
+// contains its API version, kind, labels and origin, one per line.
+func resourceTooltip(r *resource.Resource) string {
+	lines := []string{
+		fmt.Sprintf("apiVersion: %s", r.GetApiVersion()),
+		fmt.Sprintf("kind: %s", r.GetKind()),
+		fmt.Sprintf("name: %s", r.GetName()),
+	}
+	if namespace := r.GetNamespace(); namespace != "" {
+		lines = append(lines, fmt.Sprintf("namespace: %s", namespace))
+	}
+
+	labels := r.GetLabels()
+	if len(labels) > 0 {
+		lines = append(lines, "labels:")
+	}
+	for _, k := range sortedKeys(labels) {
+		lines = append(lines, fmt.Sprintf("  %s: %s", k, labels[k]))
+	}
+
+	origin, err := r.GetOrigin()
+	if err == nil && origin != nil {
+		lines = append(lines, "origin:")
+		if origin.Path != "" {
+			lines = append(lines, fmt.Sprintf("  path: %s", origin.Path))
+		}
+		if origin.Repo != "" {
+			lines = append(lines, fmt.Sprintf("  repo: %s", origin.Repo))
+		}
+		if origin.Ref != "" {
+			lines = append(lines, fmt.Sprintf("  ref: %s", origin.Ref))
+		}
+		if origin.ConfiguredIn != "" {
+			lines = append(lines, fmt.Sprintf("  configuredIn: %s", origin.ConfiguredIn))
+		}
+		if origin.ConfiguredBy.Kind != "" {
+			lines = append(lines, fmt.Sprintf("  configuredBy: %s/%s", origin.ConfiguredBy.Kind, origin.ConfiguredBy.Name))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// containerImages returns the images of the init containers and containers
+// of the pods managed by the given workload.
+func containerImages(r *resource.Resource) []string {
+	podSpec, ok := getPodSpec(r)
+	if !ok {
+		return nil
+	}
+
+	images := make([]string, 0)
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[field].([]any)
+		for _, item := range containers {
+			container, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if image, _ := container["image"].(string); image != "" {
+				images = append(images, image)
+			}
+		}
+	}
+
+	return images
+}
+
+// addImageVertices adds the container images used by the given resources as
+// vertices to the graph, and connects the workloads to the images they use.
+func (p *Parser) addImageVertices(g *Graph, resources []*resource.Resource) {
+	for _, r := range resources {
+		uName := p.vertexNameFromResource(r)
+		for _, image := range containerImages(r) {
+			v := g.AddVertex(image)
+			v.DotAttributes["shape"] = imageShape
+			e := g.AddEdge(uName, image)
+			e.DotAttributes["label"] = "image"
+			p.styleEdge(e, EdgeTypeImage)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestResourceDetails(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc        string
+		index       int
+		wantDetails []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:        "ConfigMap has no details",
+			index:       0,
+			wantDetails: []string{},
+		},
+		{
+			desc:        "Service type",
+			index:       1,
+			wantDetails: []string{"type: LoadBalancer"},
+		},
+		{
+			desc:        "Deployment replicas and images",
+			index:       2,
+			wantDetails: []string{"replicas: 3", "image: monopole/hello:1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotDetails := resourceDetails(resources[tc.index])
+			if !slices.Equal(gotDetails, tc.wantDetails) {
+				t.Fatalf("want details %v, got %v", tc.wantDetails, gotDetails)
+			}
+		})
+	}
+}
+
+func TestWithShowDetails(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(WithShowDetails())
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	u := g.GetVertex("default/service/the-service")
+	wantLabel := "default/service/the-service\ntype: LoadBalancer"
+	if u.DotAttributes["label"] != wantLabel {
+		t.Fatalf("want label %q, got %q", wantLabel, u.DotAttributes["label"])
+	}
+}
+
+func TestWithTooltips(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(WithTooltips())
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	u := g.GetVertex("default/configmap/the-map")
+	wantTooltip := strings.Join([]string{
+		"apiVersion: v1",
+		"kind: ConfigMap",
+		"name: the-map",
+		"namespace: default",
+		"labels:",
+		"  app: hello",
+		"origin:",
+		"  path: examples/helloWorld/configMap.yaml",
+		"  repo: https://github.com/kubernetes-sigs/kustomize",
+		"  ref: v1.0.6",
+	}, "\n")
+	if u.DotAttributes["tooltip"] != wantTooltip {
+		t.Fatalf("want tooltip %q, got %q", wantTooltip, u.DotAttributes["tooltip"])
+	}
+}
+
+func TestWithShowImages(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(WithShowImages(), WithGraphMode(GraphModeResources))
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	if !g.VertexExists("monopole/hello:1") {
+		t.Fatal("image vertex is missing")
+	}
+	if !g.EdgeExists("default/deployment/the-deployment", "monopole/hello:1") {
+		t.Fatal("edge from workload to image is missing")
+	}
+}
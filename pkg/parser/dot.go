@@ -0,0 +1,171 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// formatDotAttributes formats the given attributes in Dot format. The
+// attributes are sorted by name, so that the output is stable, and HTML-like
+// labels are written as is.
+func formatDotAttributes(attrs graph.DotAttributes) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == "label" && isHTMLLabel(attrs[k]) {
+			items = append(items, fmt.Sprintf("%s=%s", k, attrs[k]))
+			continue
+		}
+		items = append(items, fmt.Sprintf("%s=%q", k, attrs[k]))
+	}
+
+	return strings.Join(items, " ")
+}
+
+// writeDotVertex writes the Dot representation of the given vertex.
+func writeDotVertex(w io.Writer, indent string, v *graph.Vertex[string]) error {
+	attrs := v.DotAttributes
+	if _, ok := attrs["label"]; !ok {
+		attrs = graph.DotAttributes{"label": v.Value}
+		for k, val := range v.DotAttributes {
+			attrs[k] = val
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s%q [%s]\n", indent, v.Value, formatDotAttributes(attrs))
+
+	return err
+}
+
+// WriteDot writes the Dot representation of the [Graph] to the given
+// [io.Writer]. Unlike [graph.WriteDot], vertices and edges are written in a
+// stable order, the clusters of the graph are rendered as subgraphs, and the
+// groups of vertices on the same rank are rendered as rank=same subgraphs.
+func WriteDot(g *Graph, w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "strict digraph {"); err != nil {
+		return err
+	}
+
+	// Graph attributes
+	graphAttrs := g.GetDotAttributes()
+	keys := make([]string, 0, len(graphAttrs))
+	for k := range graphAttrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "\t%s=%q\n", k, graphAttrs[k]); err != nil {
+			return err
+		}
+	}
+
+	// Default node and edge attributes
+	if _, err := fmt.Fprintf(w, "\tnode [%s]\n", formatDotAttributes(g.nodeAttributes)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\tedge [%s]\n", formatDotAttributes(g.edgeAttributes)); err != nil {
+		return err
+	}
+
+	// Clusters
+	clustered := make(map[string]bool)
+	for i, c := range g.clusters {
+		members := make([]string, 0, len(c.vertices))
+		for _, v := range c.vertices {
+			if g.VertexExists(v) && !clustered[v] {
+				members = append(members, v)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		slices.Sort(members)
+
+		if _, err := fmt.Fprintf(w, "\tsubgraph \"cluster_%d\" {\n\t\tlabel=%q\n", i, c.label); err != nil {
+			return err
+		}
+		for _, v := range members {
+			clustered[v] = true
+			if err := writeDotVertex(w, "\t\t", g.GetVertex(v)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "\t}"); err != nil {
+			return err
+		}
+	}
+
+	// Vertices, which are not part of any cluster
+	vertices := g.GetVertexValues()
+	slices.Sort(vertices)
+	for _, v := range vertices {
+		if clustered[v] {
+			continue
+		}
+		if err := writeDotVertex(w, "\t", g.GetVertex(v)); err != nil {
+			return err
+		}
+	}
+
+	// Groups of vertices on the same rank
+	for _, rank := range g.ranks {
+		members := make([]string, 0, len(rank))
+		for _, v := range rank {
+			if g.VertexExists(v) {
+				members = append(members, fmt.Sprintf("%q;", v))
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		slices.Sort(members)
+		if _, err := fmt.Fprintf(w, "\t{ rank=\"same\"; %s }\n", strings.Join(members, " ")); err != nil {
+			return err
+		}
+	}
+
+	// Edges
+	for _, e := range sortedEdges(g) {
+		if _, err := fmt.Fprintf(w, "\t%q -> %q [%s]\n", e.From, e.To, formatDotAttributes(e.DotAttributes)); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+
+	return err
+}
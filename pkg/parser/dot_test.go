@@ -0,0 +1,198 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestWriteDot(t *testing.T) {
+	g := newGraph()
+	g.GetDotAttributes()["rankdir"] = "LR"
+	g.AddVertex("b").DotAttributes["shape"] = "box"
+	g.AddVertex("a").DotAttributes["label"] = "vertex a"
+	g.AddEdge("b", "a").DotAttributes["label"] = "b to a"
+
+	var buf bytes.Buffer
+	if err := WriteDot(g, &buf); err != nil {
+		t.Fatalf("failed to write dot: %s", err)
+	}
+
+	want := `strict digraph {
+	rankdir="LR"
+	node [color="lightblue" fillcolor="lightblue" fontcolor="black" shape="record" style="filled, rounded"]
+	edge [color="black"]
+	"a" [label="vertex a"]
+	"b" [label="b" shape="box"]
+	"b" -> "a" [label="b to a"]
+}
+`
+	if buf.String() != want {
+		t.Fatalf("want dot:\n%s\ngot dot:\n%s", want, buf.String())
+	}
+}
+
+func TestWithGroupRemotes(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc         string
+		opts         []Option
+		wantClusters int
+		wantVertices int
+	}
+
+	testCases := []testCase{
+		{
+			desc:         "no grouping",
+			opts:         []Option{},
+			wantClusters: 0,
+			wantVertices: 0,
+		},
+		{
+			desc:         "group remotes",
+			opts:         []Option{WithGroupRemotes()},
+			wantClusters: 1,
+			wantVertices: 3,
+		},
+		{
+			desc:         "group remotes with origins only",
+			opts:         []Option{WithGroupRemotes(), WithGraphMode(GraphModeOrigins)},
+			wantClusters: 1,
+			wantVertices: 5, // 3 files + 1 directory + 1 repo
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			if len(g.clusters) != tc.wantClusters {
+				t.Fatalf("want %d cluster(s), got %d", tc.wantClusters, len(g.clusters))
+			}
+
+			if tc.wantClusters == 0 {
+				return
+			}
+
+			c := g.clusters[0]
+			if c.label != "https://github.com/kubernetes-sigs/kustomize (ref v1.0.6)" {
+				t.Fatalf("unexpected cluster label %q", c.label)
+			}
+			if len(c.vertices) != tc.wantVertices {
+				t.Fatalf("want %d vertices in cluster, got %d", tc.wantVertices, len(c.vertices))
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDot(g, &buf); err != nil {
+				t.Fatalf("failed to write dot: %s", err)
+			}
+			if !strings.Contains(buf.String(), `subgraph "cluster_0"`) {
+				t.Fatal("cluster is missing from the dot representation")
+			}
+		})
+	}
+}
+
+func TestWithSeparateRanks(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		wantRanks int
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "no separate ranks",
+			opts:      []Option{},
+			wantRanks: 0,
+		},
+		{
+			desc:      "separate ranks",
+			opts:      []Option{WithSeparateRanks()},
+			wantRanks: 2,
+		},
+		{
+			desc:      "separate ranks with no origins",
+			opts:      []Option{WithSeparateRanks(), WithGraphMode(GraphModeResources)},
+			wantRanks: 0,
+		},
+		{
+			desc:      "same rank kinds",
+			opts:      []Option{WithSameRankKind("deployment"), WithSameRankKind("v1/ConfigMap")},
+			wantRanks: 2,
+		},
+		{
+			desc:      "same rank kind with no matches",
+			opts:      []Option{WithSameRankKind("Secret")},
+			wantRanks: 0,
+		},
+		{
+			desc:      "same rank kinds and separate ranks",
+			opts:      []Option{WithSameRankKind("Deployment"), WithSeparateRanks()},
+			wantRanks: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			if len(g.ranks) != tc.wantRanks {
+				t.Fatalf("want %d rank(s), got %d", tc.wantRanks, len(g.ranks))
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDot(g, &buf); err != nil {
+				t.Fatalf("failed to write dot: %s", err)
+			}
+			gotRanks := strings.Count(buf.String(), `rank="same"`)
+			if gotRanks != tc.wantRanks {
+				t.Fatalf("want %d rank group(s) in dot output, got %d", tc.wantRanks, gotRanks)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// ErrInvalidEdgeStyle is returned when an edge style is not valid, or refers
+// to an unknown type of edges.
+var ErrInvalidEdgeStyle = errors.New("invalid edge style")
+
+// EdgeType is a type which represents the kind of relationship between the
+// vertices connected by an edge.
+type EdgeType string
+
+// String implements the [fmt.Stringer] interface
+func (et EdgeType) String() string {
+	return string(et)
+}
+
+const (
+	// EdgeTypeOrigin specifies edges, which connect resources and their
+	// origins.
+	EdgeTypeOrigin EdgeType = "origin"
+
+	// EdgeTypePatch specifies edges, which connect resources and the
+	// patches applied to them.
+	EdgeTypePatch EdgeType = "patch"
+
+	// EdgeTypeRelationship specifies edges, which connect resources
+	// related to each other, e.g. a ServiceMonitor and the Service it
+	// scrapes.
+	EdgeTypeRelationship EdgeType = "relationship"
+
+	// EdgeTypeImage specifies edges, which connect workloads and the
+	// container images they use.
+	EdgeTypeImage EdgeType = "image"
+)
+
+// EdgeTypes returns the supported types of edges.
+func EdgeTypes() []EdgeType {
+	return []EdgeType{
+		EdgeTypeOrigin,
+		EdgeTypePatch,
+		EdgeTypeRelationship,
+		EdgeTypeImage,
+	}
+}
+
+// defaultEdgeStyles contains the default styles of the edges by type. An
+// empty style renders the edge as a solid line.
+var defaultEdgeStyles = map[EdgeType]string{
+	EdgeTypeOrigin:       "",
+	EdgeTypePatch:        "dotted",
+	EdgeTypeRelationship: "dashed",
+	EdgeTypeImage:        "dashed",
+}
+
+// edgeStyles contains the styles supported by Graphviz for edges.
+//
+// See https://graphviz.org/docs/attr-types/style/ for more details.
+var edgeStyles = []string{
+	"bold",
+	"dashed",
+	"dotted",
+	"invis",
+	"solid",
+	"tapered",
+}
+
+// ValidateEdgeStyle validates the style for the given type of edges, which is
+// a comma-separated list of styles supported by Graphviz for edges.
+func ValidateEdgeStyle(edgeType EdgeType, style string) error {
+	if !slices.Contains(EdgeTypes(), edgeType) {
+		return fmt.Errorf("%w: unknown edge type %q", ErrInvalidEdgeStyle, edgeType)
+	}
+
+	for _, item := range strings.Split(style, ",") {
+		if !slices.Contains(edgeStyles, strings.TrimSpace(item)) {
+			return fmt.Errorf("%w: unknown style %q", ErrInvalidEdgeStyle, style)
+		}
+	}
+
+	return nil
+}
+
+// newEdgeStyles returns a copy of the default edge styles.
+func newEdgeStyles() map[EdgeType]string {
+	return maps.Clone(defaultEdgeStyles)
+}
+
+// styleEdge sets the style of the given edge according to its type.
+func (p *Parser) styleEdge(e *graph.Edge[string], edgeType EdgeType) {
+	if style := p.edgeStyles[edgeType]; style != "" {
+		e.DotAttributes["style"] = style
+	}
+}
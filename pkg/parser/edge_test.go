@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestValidateEdgeStyle(t *testing.T) {
+	type testCase struct {
+		desc      string
+		edgeType  EdgeType
+		style     string
+		wantError error
+	}
+
+	testCases := []testCase{
+		{desc: "valid style", edgeType: EdgeTypePatch, style: "bold", wantError: nil},
+		{desc: "valid list of styles", edgeType: EdgeTypeOrigin, style: "bold, dashed", wantError: nil},
+		{desc: "unknown edge type", edgeType: EdgeType("ownerRef"), style: "dotted", wantError: ErrInvalidEdgeStyle},
+		{desc: "unknown style", edgeType: EdgeTypeImage, style: "rounded", wantError: ErrInvalidEdgeStyle},
+		{desc: "empty style", edgeType: EdgeTypeImage, style: "", wantError: ErrInvalidEdgeStyle},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateEdgeStyle(tc.edgeType, tc.style)
+			if !errors.Is(err, tc.wantError) {
+				t.Fatalf("want error %v, got %v", tc.wantError, err)
+			}
+		})
+	}
+}
+
+func TestWithEdgeStyle(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc       string
+		opts       []Option
+		wantOrigin string
+		wantImage  string
+	}
+
+	testCases := []testCase{
+		{
+			desc:       "default styles",
+			opts:       []Option{},
+			wantOrigin: "",
+			wantImage:  "dashed",
+		},
+		{
+			desc:       "custom styles",
+			opts:       []Option{WithEdgeStyle(EdgeTypeOrigin, "bold"), WithEdgeStyle(EdgeTypeImage, "dotted")},
+			wantOrigin: "bold",
+			wantImage:  "dotted",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts := append(tc.opts, WithShowImages())
+			g, err := New(opts...).ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			origin := g.GetEdge("default/deployment/the-deployment", "examples/helloWorld/deployment.yaml")
+			if got := origin.DotAttributes["style"]; got != tc.wantOrigin {
+				t.Fatalf("want origin edge style %q, got %q", tc.wantOrigin, got)
+			}
+			image := g.GetEdge("default/deployment/the-deployment", "monopole/hello:1")
+			if got := image.DotAttributes["style"]; got != tc.wantImage {
+				t.Fatalf("want image edge style %q, got %q", tc.wantImage, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Explanation describes why a resource was kept or dropped by the filters.
+type Explanation struct {
+	// Resource is the name of the resource, e.g. default/deployment/web
+	Resource string `json:"resource"`
+
+	// Kept specifies whether the resource was kept by the filters
+	Kept bool `json:"kept"`
+
+	// Filters contains the names of the filters, which decided the fate of
+	// the resource, e.g. drop-kind. Dropped resources name the filter,
+	// which dropped them, and kept resources name the keep filters, which
+	// they satisfied.
+	Filters []string `json:"filters"`
+}
+
+// keepFilters returns the names of the keep filters, which were satisfied by
+// the given resource, which was kept.
+func (p *Parser) keepFilters(r *resource.Resource) []string {
+	filters := make([]string, 0)
+	if len(p.queries) > 0 {
+		filters = append(filters, "query")
+	}
+
+	// The first rule matching the resource has the final say, when rules
+	// take precedence over the options
+	_, ruleMatched := rulesDecide(p.rules, r)
+	if p.filterPrecedence == FilterPrecedenceRules && ruleMatched {
+		return append(filters, "rule")
+	}
+
+	conditions := []struct {
+		name string
+		ok   bool
+	}{
+		{"keep-cluster-scoped-only", p.keepClusterScopedOnly},
+		{"local-only", p.localOnly},
+		{"remote-only", p.remoteOnly},
+		{"keep-group", len(p.keepGroups) > 0},
+		{"keep-owner-kind", len(p.keepOwnerKinds) > 0},
+		{"keep-has-label", len(p.keepHasLabels) > 0},
+		{"keep-resource", len(p.keepResources) > 0},
+		{"keep-name-regex", len(p.keepNameRegexps) > 0},
+		{"keep-origin-path", len(p.keepOriginPaths) > 0},
+		{"rule", ruleMatched},
+		{"filter-expr", len(p.filterExprs) > 0},
+		{"keep-namespace", len(p.keepNamespaces) > 0 && !r.GetGvk().IsClusterScoped()},
+		{"keep-kind", len(p.keepResourceKinds) > 0},
+	}
+	for _, c := range conditions {
+		if c.ok {
+			filters = append(filters, c.name)
+		}
+	}
+
+	return filters
+}
+
+// Explain returns the [Explanation] of each given resource, i.e. whether it is
+// kept by the filters, and which of the filters decided so. The explanations
+// are returned in the order of the resources.
+func (p *Parser) Explain(resources []*resource.Resource) ([]*Explanation, error) {
+	// Select resources using the configured queries
+	selected := resources
+	for _, q := range p.queries {
+		result, err := q.selectResources(selected)
+		if err != nil {
+			return nil, err
+		}
+		selected = result
+	}
+	isSelected := make(map[*resource.Resource]bool, len(selected))
+	for _, r := range selected {
+		isSelected[r] = true
+	}
+
+	result := make([]*Explanation, 0, len(resources))
+	for _, r := range resources {
+		e := &Explanation{
+			Resource: p.vertexNameFromResource(r),
+		}
+		switch reason := p.dropReason(r); {
+		case !isSelected[r]:
+			e.Filters = []string{"query"}
+		case reason != "":
+			e.Filters = []string{reason}
+		default:
+			e.Kept = true
+			e.Filters = p.keepFilters(r)
+		}
+		result = append(result, e)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestExplain(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithDropKind("ConfigMap"),
+		WithKeepNamespace("default"),
+		WithDropNameRegexp(regexp.MustCompile("^the-serv")),
+	)
+	explanations, err := p.Explain(resources)
+	if err != nil {
+		t.Fatalf("failed to explain filters: %s", err)
+	}
+
+	want := []*Explanation{
+		{Resource: "default/configmap/the-map", Kept: false, Filters: []string{"drop-kind"}},
+		{Resource: "default/service/the-service", Kept: false, Filters: []string{"drop-name-regex"}},
+		{Resource: "default/deployment/the-deployment", Kept: true, Filters: []string{"keep-namespace"}},
+	}
+	if len(explanations) != len(want) {
+		t.Fatalf("want %d explanations, got %d", len(want), len(explanations))
+	}
+	for i, e := range explanations {
+		w := want[i]
+		if e.Resource != w.Resource || e.Kept != w.Kept || !slices.Equal(e.Filters, w.Filters) {
+			t.Fatalf("want explanation %+v, got %+v", w, e)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ErrInvalidFilterExpr is returned when a filter expression cannot be
+// compiled, or does not evaluate to a boolean.
+var ErrInvalidFilterExpr = errors.New("invalid filter expression")
+
+// filterExprVariable is the name of the variable, which holds the resource
+// being evaluated by a [FilterExpr].
+const filterExprVariable = "resource"
+
+// FilterExpr is a compiled CEL expression, which is evaluated against
+// resources in order to decide whether they are kept in the graph. The
+// resource is available to the expression as a map in the resource variable,
+// e.g. resource.kind == "Deployment".
+type FilterExpr struct {
+	// expr is the source of the expression
+	expr string
+
+	// program is the compiled expression
+	program cel.Program
+}
+
+// NewFilterExpr compiles the given CEL expression into a [FilterExpr]. The
+// expression must evaluate to a boolean.
+func NewFilterExpr(expr string) (*FilterExpr, error) {
+	env, err := cel.NewEnv(cel.Variable(filterExprVariable, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidFilterExpr, expr, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("%w: %s: must evaluate to bool, got %s", ErrInvalidFilterExpr, expr, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidFilterExpr, expr, err)
+	}
+
+	f := &FilterExpr{
+		expr:    expr,
+		program: program,
+	}
+
+	return f, nil
+}
+
+// String returns the source of the expression.
+func (f *FilterExpr) String() string {
+	return f.expr
+}
+
+// matches is a predicate, which returns true, if the expression evaluates to
+// true for the given [resource.Resource]. Expressions, which fail to evaluate,
+// e.g. because of a missing field, are considered as not matching.
+func (f *FilterExpr) matches(r *resource.Resource) bool {
+	m, err := r.Map()
+	if err != nil {
+		return false
+	}
+
+	out, _, err := f.program.Eval(map[string]any{filterExprVariable: m})
+	if err != nil {
+		return false
+	}
+	result, ok := out.Value().(bool)
+
+	return ok && result
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFilterExpr(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  labels:
+    tier: web
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: default
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+  labels:
+    tier: web
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc string
+		expr string
+		want []bool
+	}
+
+	testCases := []testCase{
+		{
+			desc: "match by kind",
+			expr: `resource.kind == "Deployment"`,
+			want: []bool{true, true, false},
+		},
+		{
+			desc: "match by kind and label",
+			expr: `resource.kind == "Deployment" && resource.metadata.labels["tier"] == "web"`,
+			want: []bool{true, false, false},
+		},
+		{
+			desc: "match by numeric field",
+			expr: `has(resource.spec.replicas) && resource.spec.replicas > 1`,
+			want: []bool{true, false, false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := NewFilterExpr(tc.expr)
+			if err != nil {
+				t.Fatalf("failed to compile expression: %s", err)
+			}
+
+			for i, r := range resources {
+				if got := f.matches(r); got != tc.want[i] {
+					t.Fatalf("want %t for %s, got %t", tc.want[i], r.GetName(), got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFilterExprInvalid(t *testing.T) {
+	invalid := []string{
+		`resource.kind ==`,
+		`resource.kind + 1 == ""`,
+		`1 + 2`,
+	}
+
+	for _, expr := range invalid {
+		if _, err := NewFilterExpr(expr); !errors.Is(err, ErrInvalidFilterExpr) {
+			t.Fatalf("want ErrInvalidFilterExpr for %q, got %v", expr, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"strconv"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// font represents the font used for the text of the graph, vertices or
+// edges.
+type font struct {
+	// name is the name of the font, e.g. Helvetica
+	name string
+
+	// size is the size of the font in points
+	size float64
+}
+
+// apply sets the font attributes in the given attributes. The zero values of
+// the font name and size are ignored.
+func (f font) apply(attrs graph.DotAttributes) {
+	if f.name != "" {
+		attrs["fontname"] = f.name
+	}
+	if f.size > 0 {
+		attrs["fontsize"] = strconv.FormatFloat(f.size, 'f', -1, 64)
+	}
+}
+
+// applyFonts applies the configured fonts to the graph, vertices and edges.
+func (p *Parser) applyFonts(g *Graph) {
+	p.graphFont.apply(g.GetDotAttributes())
+	p.nodeFont.apply(g.nodeAttributes)
+	p.edgeFont.apply(g.edgeAttributes)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+func TestFonts(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithGraphFont("Helvetica", 16),
+		WithNodeFont("", 10.5),
+		WithEdgeFont("Courier", 0),
+	)
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	type testCase struct {
+		desc     string
+		attrs    graph.DotAttributes
+		wantName string
+		wantSize string
+	}
+
+	testCases := []testCase{
+		{desc: "graph", attrs: g.GetDotAttributes(), wantName: "Helvetica", wantSize: "16"},
+		{desc: "node", attrs: g.nodeAttributes, wantName: "", wantSize: "10.5"},
+		{desc: "edge", attrs: g.edgeAttributes, wantName: "Courier", wantSize: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.attrs["fontname"]; got != tc.wantName {
+			t.Fatalf("%s: want font name %q, got %q", tc.desc, tc.wantName, got)
+		}
+		if got := tc.attrs["fontsize"]; got != tc.wantSize {
+			t.Fatalf("%s: want font size %q, got %q", tc.desc, tc.wantSize, got)
+		}
+	}
+}
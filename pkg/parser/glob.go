@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// globToRegexp converts the given glob pattern into a regular expression. A
+// single star matches any sequence of characters other than the path
+// separator, a double star matches any sequence of characters including the
+// path separator, and a question mark matches a single character other than
+// the path separator. A double star followed by a path separator also matches
+// zero directories, e.g. **/kustomization.yaml matches kustomization.yaml.
+func globToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
+
+// wildcards are the characters, which turn a filter value into a glob pattern.
+const wildcards = "*?"
+
+// wildcardMatches is a predicate, which returns true, if the given value
+// matches the filter. Filters containing wildcards are matched as glob
+// patterns, e.g. kube-* or *Policy, and all other filters are matched exactly.
+func wildcardMatches(filter string, value string) bool {
+	if !strings.ContainsAny(filter, wildcards) {
+		return filter == value
+	}
+
+	return globToRegexp(filter).MatchString(value)
+}
+
+// foldMatches is a predicate, which returns true, if the given value matches
+// the filter under case-insensitive comparison. The filter may contain
+// wildcards.
+func foldMatches(filter string, value string) bool {
+	return wildcardMatches(strings.ToLower(filter), strings.ToLower(value))
+}
+
+// negationPrefix is the prefix, which negates the values of filters.
+const negationPrefix = "!"
+
+// pattern is a regular expression used by filters, whose result may be
+// negated.
+type pattern struct {
+	// re is the regular expression to match
+	re *regexp.Regexp
+
+	// negate specifies whether the result of the match is negated
+	negate bool
+}
+
+// newPattern creates a new [pattern] from the given value using the compile
+// function. Values with the negation prefix produce a negated pattern.
+func newPattern(value string, compile func(string) *regexp.Regexp) pattern {
+	value, negate := strings.CutPrefix(value, negationPrefix)
+	pt := pattern{
+		re:     compile(value),
+		negate: negate,
+	}
+
+	return pt
+}
+
+// matches is a predicate, which returns true, if the given value matches the
+// pattern, taking into account whether the pattern is negated.
+func (pt pattern) matches(value string) bool {
+	return pt.re.MatchString(value) != pt.negate
+}
+
+// prefixToRegexp returns a regular expression matching values, which start
+// with the given prefix.
+func prefixToRegexp(prefix string) *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.QuoteMeta(prefix))
+}
+
+// suffixToRegexp returns a regular expression matching values, which end
+// with the given suffix.
+func suffixToRegexp(suffix string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(suffix) + "$")
+}
+
+// negatableMatches is a predicate, which returns the result of the match
+// function for the given filter, negating it when the filter has the negation
+// prefix.
+func negatableMatches(filter string, match func(string) bool) bool {
+	value, negate := strings.CutPrefix(filter, negationPrefix)
+
+	return match(value) != negate
+}
+
+// filterMatches is a predicate, which returns true, if the given value
+// matches the filter under case-insensitive comparison, or doesn't match it,
+// when the filter has the negation prefix. The filter may contain wildcards.
+func filterMatches(filter string, value string) bool {
+	return negatableMatches(filter, func(f string) bool { return foldMatches(f, value) })
+}
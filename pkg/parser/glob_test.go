@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import "testing"
+
+func TestGlobToRegexp(t *testing.T) {
+	type testCase struct {
+		pattern string
+		path    string
+		want    bool
+	}
+
+	testCases := []testCase{
+		{pattern: "overlays/prod/**", path: "overlays/prod/deployment.yaml", want: true},
+		{pattern: "overlays/prod/**", path: "overlays/prod/app/deployment.yaml", want: true},
+		{pattern: "overlays/prod/**", path: "overlays/dev/deployment.yaml", want: false},
+		{pattern: "base/*.yaml", path: "base/service.yaml", want: true},
+		{pattern: "base/*.yaml", path: "base/app/service.yaml", want: false},
+		{pattern: "**/service.yaml", path: "service.yaml", want: true},
+		{pattern: "**/service.yaml", path: "base/app/service.yaml", want: true},
+		{pattern: "base/?.yaml", path: "base/a.yaml", want: true},
+		{pattern: "base/?.yaml", path: "base/ab.yaml", want: false},
+		{pattern: "base/(app).yaml", path: "base/(app).yaml", want: true},
+	}
+
+	for _, tc := range testCases {
+		got := globToRegexp(tc.pattern).MatchString(tc.path)
+		if got != tc.want {
+			t.Fatalf("glob %q on %q: want %t, got %t", tc.pattern, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestWildcardMatches(t *testing.T) {
+	type testCase struct {
+		filter string
+		value  string
+		want   bool
+	}
+
+	testCases := []testCase{
+		{filter: "kube-system", value: "kube-system", want: true},
+		{filter: "kube-system", value: "kube-public", want: false},
+		{filter: "kube-*", value: "kube-public", want: true},
+		{filter: "kube-*", value: "monitoring", want: false},
+		{filter: "*policy", value: "networkpolicy", want: true},
+		{filter: "*policy", value: "policyreport", want: false},
+		{filter: "v?", value: "v1", want: true},
+	}
+
+	for _, tc := range testCases {
+		got := wildcardMatches(tc.filter, tc.value)
+		if got != tc.want {
+			t.Fatalf("filter %q on %q: want %t, got %t", tc.filter, tc.value, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// ErrVertexNotFound is returned when a vertex referenced by the parser
+// options is not part of the graph.
+var ErrVertexNotFound = errors.New("vertex not found")
+
+// longestPathColor is the color of the vertices and edges along the
+// highlighted longest path.
+const longestPathColor = "red"
+
+// longestPathPenWidth is the width of the outline of the vertices and edges
+// along the highlighted longest path.
+const longestPathPenWidth = "3"
+
+// Graph represents the directed graph of resources and their origins, as
+// generated by the [Parser].
+type Graph struct {
+	graph.Graph[string]
+
+	// nodeAttributes contains the default attributes for all vertices.
+	nodeAttributes graph.DotAttributes
+
+	// edgeAttributes contains the default attributes for all edges.
+	edgeAttributes graph.DotAttributes
+
+	// clusters contains the groups of vertices, which will be rendered
+	// as clusters.
+	clusters []*cluster
+
+	// ranks contains the groups of vertices, which will be placed on the
+	// same rank.
+	ranks [][]string
+}
+
+// cluster represents a group of vertices, which are rendered together.
+type cluster struct {
+	// label is the label of the cluster
+	label string
+
+	// vertices contains the names of the vertices in the cluster
+	vertices []string
+}
+
+// newGraph creates a new empty [Graph].
+func newGraph() *Graph {
+	g := &Graph{
+		Graph:          graph.New[string](graph.KindDirected),
+		nodeAttributes: maps.Clone(graph.DotDefaultNodeAttributes),
+		edgeAttributes: maps.Clone(graph.DotDefaultEdgeAttributes),
+		clusters:       make([]*cluster, 0),
+		ranks:          make([][]string, 0),
+	}
+
+	return g
+}
+
+// addToCluster adds the vertex to the cluster with the given label. The
+// cluster is created, if it doesn't exist already.
+func (g *Graph) addToCluster(label string, vertex string) {
+	for _, c := range g.clusters {
+		if c.label != label {
+			continue
+		}
+		if !slices.Contains(c.vertices, vertex) {
+			c.vertices = append(c.vertices, vertex)
+		}
+		return
+	}
+
+	c := &cluster{
+		label:    label,
+		vertices: []string{vertex},
+	}
+	g.clusters = append(g.clusters, c)
+}
+
+// IsEmpty returns true, if the graph has no vertices other than the ones of
+// the legend, e.g. when all resources were filtered out.
+func (g *Graph) IsEmpty() bool {
+	for _, v := range g.GetVertices() {
+		if !strings.HasPrefix(v.Value, legendPrefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addRank adds a group of vertices, which will be placed on the same rank.
+func (g *Graph) addRank(vertices []string) {
+	if len(vertices) == 0 {
+		return
+	}
+	g.ranks = append(g.ranks, vertices)
+}
+
+// undirectedAdjacency returns the adjacency lists of the graph, where edges
+// are followed in both directions.
+func undirectedAdjacency(g graph.Graph[string]) map[string][]string {
+	adj := make(map[string][]string)
+	for _, e := range g.GetEdges() {
+		adj[e.From] = append(adj[e.From], e.To)
+		adj[e.To] = append(adj[e.To], e.From)
+	}
+
+	return adj
+}
+
+// directedAdjacency returns the adjacency lists of the graph, where edges are
+// followed from their source to their destination.
+func directedAdjacency(g graph.Graph[string]) map[string][]string {
+	adj := make(map[string][]string)
+	for _, e := range g.GetEdges() {
+		adj[e.From] = append(adj[e.From], e.To)
+	}
+
+	return adj
+}
+
+// reverseAdjacency returns the adjacency lists of the graph, where edges are
+// followed from their destination to their source.
+func reverseAdjacency(g graph.Graph[string]) map[string][]string {
+	adj := make(map[string][]string)
+	for _, e := range g.GetEdges() {
+		adj[e.To] = append(adj[e.To], e.From)
+	}
+
+	return adj
+}
+
+// neighbourhood returns the set of vertices, which are within the given
+// number of hops from any of the source vertices. Edges are followed in both
+// directions.
+func neighbourhood(g graph.Graph[string], sources []string, depth int) map[string]bool {
+	return withinHops(undirectedAdjacency(g), sources, depth)
+}
+
+// withinHops returns the set of vertices, which are within the given number of
+// hops from any of the source vertices, using the given adjacency lists.
+func withinHops(adj map[string][]string, sources []string, depth int) map[string]bool {
+	visited := make(map[string]bool)
+	frontier := make([]string, 0, len(sources))
+	for _, source := range sources {
+		visited[source] = true
+		frontier = append(frontier, source)
+	}
+
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		next := make([]string, 0)
+		for _, u := range frontier {
+			for _, v := range adj[u] {
+				if visited[v] {
+					continue
+				}
+				visited[v] = true
+				next = append(next, v)
+			}
+		}
+		frontier = next
+	}
+
+	return visited
+}
+
+// sinkVertices returns the vertices of the graph, which have no outgoing
+// edges, e.g. the sources of origins.
+func sinkVertices(g graph.Graph[string]) []string {
+	hasOutEdges := make(map[string]bool)
+	for _, e := range g.GetEdges() {
+		hasOutEdges[e.From] = true
+	}
+
+	sinks := make([]string, 0)
+	for _, v := range g.GetVertexValues() {
+		if !hasOutEdges[v] {
+			sinks = append(sinks, v)
+		}
+	}
+
+	return sinks
+}
+
+// keepVertices removes any vertex from the graph, which is not part of the
+// given set.
+func keepVertices(g graph.Graph[string], keep map[string]bool) {
+	for _, v := range g.GetVertexValues() {
+		if !keep[v] {
+			g.DeleteVertex(v)
+		}
+	}
+}
+
+// applyFocus reduces the graph to the neighbourhood of the focus vertices.
+func (p *Parser) applyFocus(g graph.Graph[string]) error {
+	if len(p.focusVertices) == 0 {
+		return nil
+	}
+
+	for _, v := range p.focusVertices {
+		if !g.VertexExists(v) {
+			return fmt.Errorf("%w: %s", ErrVertexNotFound, v)
+		}
+	}
+
+	keepVertices(g, neighbourhood(g, p.focusVertices, p.focusDepth))
+
+	return nil
+}
+
+// applyMaxDepth reduces the graph to the vertices, which are within the
+// configured max depth from the root vertices. Edges are followed in reverse
+// direction, i.e. from the roots towards the resources.
+func (p *Parser) applyMaxDepth(g graph.Graph[string]) error {
+	if p.maxDepth < 0 {
+		return nil
+	}
+
+	roots := p.rootVertices
+	if len(roots) == 0 {
+		roots = sinkVertices(g)
+	}
+	for _, v := range roots {
+		if !g.VertexExists(v) {
+			return fmt.Errorf("%w: %s", ErrVertexNotFound, v)
+		}
+	}
+
+	keepVertices(g, withinHops(reverseAdjacency(g), roots, p.maxDepth))
+
+	return nil
+}
+
+// pruneUnreachable removes any vertex from the graph, which is not reachable
+// from any of the given resource vertices.
+func pruneUnreachable(g graph.Graph[string], resourceVertices map[string]bool) {
+	sources := make([]string, 0)
+	for _, v := range g.GetVertexValues() {
+		if resourceVertices[v] {
+			sources = append(sources, v)
+		}
+	}
+
+	keepVertices(g, withinHops(directedAdjacency(g), sources, len(g.GetVertexValues())))
+}
+
+// dropIsolatedVertices removes any vertex from the graph, which is not
+// connected to any other vertex.
+func dropIsolatedVertices(g graph.Graph[string]) {
+	connected := make(map[string]bool)
+	for _, e := range g.GetEdges() {
+		connected[e.From] = true
+		connected[e.To] = true
+	}
+
+	keepVertices(g, connected)
+}
+
+// longestPath returns the vertices along the longest path of the graph, which
+// ends at the given target vertex, or anywhere in the graph, if the target is
+// empty. Edges, which close a cycle, are ignored. Ties are broken by picking
+// the vertices in sorted order, so that the result is stable.
+func longestPath(g graph.Graph[string], target string) []string {
+	adj := directedAdjacency(g)
+	starts := g.GetVertexValues()
+	if target != "" {
+		adj = reverseAdjacency(g)
+		starts = []string{target}
+	}
+	for _, vs := range adj {
+		slices.Sort(vs)
+	}
+	slices.Sort(starts)
+
+	// length contains the number of edges of the longest path starting at
+	// a vertex, and next contains the vertex following it on the path.
+	length := make(map[string]int)
+	next := make(map[string]string)
+	onPath := make(map[string]bool)
+	var walk func(u string) int
+	walk = func(u string) int {
+		if n, ok := length[u]; ok {
+			return n
+		}
+		onPath[u] = true
+		best := 0
+		for _, v := range adj[u] {
+			if onPath[v] {
+				continue
+			}
+			if n := walk(v) + 1; n > best {
+				best = n
+				next[u] = v
+			}
+		}
+		onPath[u] = false
+		length[u] = best
+
+		return best
+	}
+
+	start := ""
+	for _, v := range starts {
+		if n := walk(v); start == "" || n > length[start] {
+			start = v
+		}
+	}
+
+	path := make([]string, 0)
+	for v := start; v != ""; v = next[v] {
+		path = append(path, v)
+	}
+	if target != "" {
+		slices.Reverse(path)
+	}
+
+	return path
+}
+
+// applyLongestPath highlights the vertices and edges along the longest path
+// of the graph.
+func (p *Parser) applyLongestPath(g graph.Graph[string]) error {
+	if !p.longestPath {
+		return nil
+	}
+
+	if p.longestPathTarget != "" && !g.VertexExists(p.longestPathTarget) {
+		return fmt.Errorf("%w: %s", ErrVertexNotFound, p.longestPathTarget)
+	}
+
+	path := longestPath(g, p.longestPathTarget)
+	for i, name := range path {
+		v := g.GetVertex(name)
+		v.DotAttributes["color"] = longestPathColor
+		v.DotAttributes["penwidth"] = longestPathPenWidth
+		if i == 0 {
+			continue
+		}
+		e := g.GetEdge(path[i-1], name)
+		e.DotAttributes["color"] = longestPathColor
+		e.DotAttributes["penwidth"] = longestPathPenWidth
+	}
+
+	return nil
+}
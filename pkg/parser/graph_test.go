@@ -0,0 +1,423 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestWithFocus(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.KubePrometheus))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		wantVs    []string
+		wantNotVs []string
+		wantError error
+	}
+
+	testCases := []testCase{
+		{
+			desc: "focus with depth 0",
+			opts: []Option{
+				WithFocus("monitoring/deployment/grafana"),
+				WithFocusDepth(0),
+			},
+			wantVs: []string{"monitoring/deployment/grafana"},
+			wantNotVs: []string{
+				"monitoring/serviceaccount/grafana",
+			},
+		},
+		{
+			desc: "focus with default depth",
+			opts: []Option{
+				WithFocus("monitoring/deployment/grafana"),
+			},
+			wantVs: []string{
+				"monitoring/deployment/grafana",
+				"monitoring/serviceaccount/grafana",
+			},
+			wantNotVs: []string{
+				"clusterrolebinding/blackbox-exporter",
+			},
+		},
+		{
+			desc: "focus with depth 2",
+			opts: []Option{
+				WithFocus("monitoring/daemonset/node-exporter"),
+				WithFocusDepth(2),
+			},
+			wantVs: []string{
+				"monitoring/daemonset/node-exporter",
+				"monitoring/serviceaccount/node-exporter",
+				"manifests/nodeExporter-serviceAccount.yaml",
+			},
+		},
+		{
+			desc:      "missing focus vertex",
+			opts:      []Option{WithFocus("default/deployment/missing")},
+			wantError: ErrVertexNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if !errors.Is(err, tc.wantError) {
+				t.Fatalf("want %v error, got %v", tc.wantError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, v := range tc.wantVs {
+				if !g.VertexExists(v) {
+					t.Fatalf("missing vertex %s", v)
+				}
+			}
+			for _, v := range tc.wantNotVs {
+				if g.VertexExists(v) {
+					t.Fatalf("unexpected vertex %s", v)
+				}
+			}
+		})
+	}
+}
+
+func TestWithDropOrphans(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc   string
+		opts   []Option
+		wantVs int
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "resources without orphans dropped",
+			opts:   []Option{WithGraphMode(GraphModeResources)},
+			wantVs: 3,
+		},
+		{
+			desc:   "resources with orphans dropped",
+			opts:   []Option{WithGraphMode(GraphModeResources), WithDropOrphans()},
+			wantVs: 0,
+		},
+		{
+			desc:   "full graph with orphans dropped",
+			opts:   []Option{WithDropOrphans()},
+			wantVs: 6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			gotVs := g.GetVertexValues()
+			if len(gotVs) != tc.wantVs {
+				t.Fatalf("want |V|=%d, got |V|=%d", tc.wantVs, len(gotVs))
+			}
+		})
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	source := "https://github.com/kubernetes-sigs/kustomize (ref v1.0.6)"
+	dir := "https://github.com/kubernetes-sigs/kustomize//examples/helloWorld"
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		wantVs    int
+		wantError error
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "unlimited depth",
+			opts:   []Option{WithGraphMode(GraphModeOrigins)},
+			wantVs: 5,
+		},
+		{
+			desc:   "depth 1 from default roots",
+			opts:   []Option{WithGraphMode(GraphModeOrigins), WithMaxDepth(1)},
+			wantVs: 2, // source + directory
+		},
+		{
+			desc:   "depth 1 from explicit root",
+			opts:   []Option{WithGraphMode(GraphModeOrigins), WithMaxDepth(1), WithRoot(dir)},
+			wantVs: 4, // directory + 3 files
+		},
+		{
+			desc:   "depth 0 from explicit root",
+			opts:   []Option{WithGraphMode(GraphModeOrigins), WithMaxDepth(0), WithRoot(source)},
+			wantVs: 1,
+		},
+		{
+			desc:      "missing root",
+			opts:      []Option{WithMaxDepth(1), WithRoot("no/such/vertex")},
+			wantError: ErrVertexNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if !errors.Is(err, tc.wantError) {
+				t.Fatalf("want error %v, got %v", tc.wantError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			gotVs := g.GetVertexValues()
+			if len(gotVs) != tc.wantVs {
+				t.Fatalf("want |V|=%d, got |V|=%d", tc.wantVs, len(gotVs))
+			}
+		})
+	}
+}
+
+func TestWithPruneUnreachable(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	origin := "examples/helloWorld/configMap.yaml"
+
+	type testCase struct {
+		desc          string
+		opts          []Option
+		wantVs        int
+		wantOriginVtx bool
+	}
+
+	testCases := []testCase{
+		{
+			desc:          "max depth from origin without pruning",
+			opts:          []Option{WithMaxDepth(0), WithRoot(origin)},
+			wantVs:        1,
+			wantOriginVtx: true,
+		},
+		{
+			desc:          "max depth from origin with pruning",
+			opts:          []Option{WithMaxDepth(0), WithRoot(origin), WithPruneUnreachable()},
+			wantVs:        0,
+			wantOriginVtx: false,
+		},
+		{
+			desc:          "full graph with pruning",
+			opts:          []Option{WithPruneUnreachable()},
+			wantVs:        6,
+			wantOriginVtx: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			gotVs := g.GetVertexValues()
+			if len(gotVs) != tc.wantVs {
+				t.Fatalf("want |V|=%d, got |V|=%d", tc.wantVs, len(gotVs))
+			}
+			if g.VertexExists(origin) != tc.wantOriginVtx {
+				t.Fatalf("want origin vertex present %t", tc.wantOriginVtx)
+			}
+		})
+	}
+}
+
+func TestLongestPath(t *testing.T) {
+	type testCase struct {
+		desc     string
+		edges    [][2]string
+		target   string
+		wantPath []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "empty graph",
+			edges:    [][2]string{},
+			wantPath: []string{},
+		},
+		{
+			desc:     "longest of two chains",
+			edges:    [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"x", "d"}},
+			wantPath: []string{"a", "b", "c", "d"},
+		},
+		{
+			desc:     "path to target",
+			edges:    [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"x", "c"}},
+			target:   "b",
+			wantPath: []string{"a", "b"},
+		},
+		{
+			desc:     "cycle is ignored",
+			edges:    [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+			wantPath: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := newGraph()
+			for _, e := range tc.edges {
+				g.AddVertex(e[0])
+				g.AddVertex(e[1])
+				g.AddEdge(e[0], e[1])
+			}
+
+			got := longestPath(g, tc.target)
+			if !slices.Equal(got, tc.wantPath) {
+				t.Fatalf("want path %v, got %v", tc.wantPath, got)
+			}
+		})
+	}
+}
+
+func TestWithLongestPath(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.KubePrometheus))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		wantVs    []string
+		wantError error
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "longest path to vertex",
+			opts:   []Option{WithLongestPathTo("monitoring/serviceaccount/grafana")},
+			wantVs: []string{"monitoring/deployment/grafana", "monitoring/serviceaccount/grafana"},
+		},
+		{
+			desc:      "missing target vertex",
+			opts:      []Option{WithLongestPathTo("default/deployment/missing")},
+			wantError: ErrVertexNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if !errors.Is(err, tc.wantError) {
+				t.Fatalf("want error %v, got %v", tc.wantError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, v := range tc.wantVs {
+				if got := g.GetVertex(v).DotAttributes["color"]; got != longestPathColor {
+					t.Fatalf("want vertex %s on the longest path, got color %q", v, got)
+				}
+			}
+			for i := 1; i < len(tc.wantVs); i++ {
+				e := g.GetEdge(tc.wantVs[i-1], tc.wantVs[i])
+				if e.DotAttributes["penwidth"] != longestPathPenWidth {
+					t.Fatalf("want edge %s -> %s on the longest path", tc.wantVs[i-1], tc.wantVs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc string
+		opts []Option
+		want bool
+	}
+
+	testCases := []testCase{
+		{
+			desc: "all resources kept",
+			opts: []Option{},
+			want: false,
+		},
+		{
+			desc: "all resources dropped",
+			opts: []Option{WithDropNamespace("default")},
+			want: true,
+		},
+		{
+			desc: "all resources dropped with legend",
+			opts: []Option{WithDropNamespace("default"), WithLegend(), WithHighlightKind("Deployment", "yellow")},
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		g, err := New(tc.opts...).ParseGraph(resources)
+		if err != nil {
+			t.Fatalf("%s: failed to parse resources as graph: %s", tc.desc, err)
+		}
+		if got := g.IsEmpty(); got != tc.want {
+			t.Fatalf("%s: want empty %t, got %t", tc.desc, tc.want, got)
+		}
+	}
+}
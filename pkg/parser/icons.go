@@ -0,0 +1,190 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// bundledIcons contains the Kubernetes icons, which are bundled with the
+// parser. They are drawn in the style of the official Kubernetes icon set,
+// i.e. the abbreviated kind on a blue heptagon.
+//
+//go:embed icons/*.svg
+var bundledIcons embed.FS
+
+// iconSize is the size in points of the icons embedded in vertex labels.
+const iconSize = 48
+
+// kindIcons maps the lower-cased resource kinds to the names of the icons,
+// which follow the official Kubernetes icon set available at
+// https://github.com/kubernetes/community/tree/master/icons. The icons are
+// SVG files named after the abbreviated kinds, e.g. deploy.svg.
+var kindIcons = map[string]string{
+	"clusterrole":              "c-role",
+	"clusterrolebinding":       "crb",
+	"configmap":                "cm",
+	"cronjob":                  "cronjob",
+	"customresourcedefinition": "crd",
+	"daemonset":                "ds",
+	"deployment":               "deploy",
+	"endpoints":                "ep",
+	"horizontalpodautoscaler":  "hpa",
+	"ingress":                  "ing",
+	"job":                      "job",
+	"limitrange":               "limits",
+	"namespace":                "ns",
+	"networkpolicy":            "netpol",
+	"persistentvolume":         "pv",
+	"persistentvolumeclaim":    "pvc",
+	"pod":                      "pod",
+	"podsecuritypolicy":        "psp",
+	"replicaset":               "rs",
+	"resourcequota":            "quota",
+	"role":                     "role",
+	"rolebinding":              "rb",
+	"secret":                   "secret",
+	"service":                  "svc",
+	"serviceaccount":           "sa",
+	"statefulset":              "sts",
+	"storageclass":             "sc",
+}
+
+// isHTMLLabel is a predicate, which returns true, if the given attribute
+// value is an HTML-like label, i.e. it is delimited by angle brackets.
+func isHTMLLabel(value string) bool {
+	return strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">")
+}
+
+// bundledIconsDir returns the directory in the user cache directory, where
+// the bundled icons are written, since Graphviz loads the images embedded in
+// labels from files.
+func bundledIconsDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "kustomize-dot", "icons")
+}
+
+// writeBundledIcons writes the bundled icons to the given directory, unless
+// they are already there.
+func writeBundledIcons(dir string) error {
+	entries, err := fs.ReadDir(bundledIcons, "icons")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		data, err := bundledIcons.ReadFile(path.Join("icons", entry.Name()))
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(dir, entry.Name())
+		if existing, err := os.ReadFile(name); err == nil && bytes.Equal(existing, data) {
+			continue
+		}
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveIcons returns the paths to the icons of the resource kinds, keyed by
+// the names of the icons. The icons found in the given directory override the
+// bundled icons, which are written to the user cache directory.
+func resolveIcons(dir string) (map[string]string, error) {
+	bundledDir := bundledIconsDir()
+	if err := writeBundledIcons(bundledDir); err != nil {
+		return nil, fmt.Errorf("cannot write bundled icons: %w", err)
+	}
+
+	icons := make(map[string]string, len(kindIcons))
+	for _, icon := range kindIcons {
+		icons[icon] = filepath.Join(bundledDir, icon+".svg")
+		if dir == "" {
+			continue
+		}
+
+		override := filepath.Join(dir, icon+".svg")
+		_, err := os.Stat(override)
+		switch {
+		case err == nil:
+			icons[icon] = override
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	return icons, nil
+}
+
+// applyIcon replaces the label of the [graph.Vertex] u for [resource.Resource]
+// r with an HTML-like label, which embeds the icon of the resource kind from
+// the given icon paths above the original label. Resources of kinds without
+// an icon keep their label.
+func applyIcon(u *graph.Vertex[string], r *resource.Resource, icons map[string]string) {
+	icon, ok := icons[kindIcons[strings.ToLower(r.GetKind())]]
+	if !ok {
+		return
+	}
+
+	text, ok := u.DotAttributes["label"]
+	if !ok {
+		text = u.Value
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+
+	src := html.EscapeString(icon)
+	u.DotAttributes["label"] = fmt.Sprintf(
+		`<<TABLE BORDER="0" CELLBORDER="0" CELLSPACING="0">`+
+			`<TR><TD FIXEDSIZE="TRUE" WIDTH="%d" HEIGHT="%d"><IMG SRC="%s" SCALE="TRUE"/></TD></TR>`+
+			`<TR><TD>%s</TD></TR>`+
+			`</TABLE>>`,
+		iconSize, iconSize, src, strings.Join(lines, "<BR/>"),
+	)
+}
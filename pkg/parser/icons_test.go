@@ -0,0 +1,124 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestBundledIcons(t *testing.T) {
+	for kind, icon := range kindIcons {
+		data, err := bundledIcons.ReadFile("icons/" + icon + ".svg")
+		if err != nil {
+			t.Fatalf("missing bundled icon of %s: %s", kind, err)
+		}
+		if !bytes.HasPrefix(data, []byte("<svg")) {
+			t.Fatalf("want SVG icon of %s", kind)
+		}
+	}
+}
+
+func TestWithIcons(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	// The official icons override the bundled ones
+	official := t.TempDir()
+	if err := os.WriteFile(filepath.Join(official, "deploy.svg"), []byte("<svg/>"), 0o644); err != nil {
+		t.Fatalf("writing icon failed: %s", err)
+	}
+
+	type testCase struct {
+		desc        string
+		dir         string
+		wantDeploy  string
+		wantService string
+	}
+
+	testCases := []testCase{
+		{
+			desc:        "bundled icons",
+			dir:         "",
+			wantDeploy:  filepath.Join("kustomize-dot", "icons", "deploy.svg"),
+			wantService: filepath.Join("kustomize-dot", "icons", "svc.svg"),
+		},
+		{
+			desc:        "icons from directory",
+			dir:         official,
+			wantDeploy:  filepath.Join(official, "deploy.svg"),
+			wantService: filepath.Join("kustomize-dot", "icons", "svc.svg"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+			p := New(WithIcons(tc.dir), WithShowDetails())
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			label := g.GetVertex("default/deployment/the-deployment").DotAttributes["label"]
+			if !isHTMLLabel(label) {
+				t.Fatalf("want HTML-like label, got %q", label)
+			}
+			src := regexp.MustCompile(`<IMG SRC="([^"]+)"`).FindStringSubmatch(label)
+			if src == nil || !strings.HasSuffix(src[1], tc.wantDeploy) {
+				t.Fatalf("want icon %s in label %q", tc.wantDeploy, label)
+			}
+			if _, err := os.Stat(src[1]); err != nil {
+				t.Fatalf("want icon file: %s", err)
+			}
+			if !strings.Contains(label, "default/deployment/the-deployment<BR/>") {
+				t.Fatalf("want details in label %q", label)
+			}
+
+			label = g.GetVertex("default/service/the-service").DotAttributes["label"]
+			if !strings.Contains(label, tc.wantService+`"`) {
+				t.Fatalf("want icon %s in label %q", tc.wantService, label)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDot(g, &buf); err != nil {
+				t.Fatalf("failed to write dot: %s", err)
+			}
+			if !strings.Contains(buf.String(), `label=<<TABLE`) {
+				t.Fatalf("want unquoted HTML-like label in dot output")
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// jsonGraph is the JSON representation of a [Graph].
+type jsonGraph struct {
+	Attributes graph.DotAttributes `json:"attributes"`
+	Vertices   []jsonVertex        `json:"vertices"`
+	Edges      []jsonEdge          `json:"edges"`
+	Clusters   []jsonCluster       `json:"clusters"`
+}
+
+// jsonVertex is the JSON representation of a vertex.
+type jsonVertex struct {
+	Name       string              `json:"name"`
+	Attributes graph.DotAttributes `json:"attributes"`
+}
+
+// jsonEdge is the JSON representation of an edge.
+type jsonEdge struct {
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Attributes graph.DotAttributes `json:"attributes"`
+}
+
+// jsonCluster is the JSON representation of a cluster.
+type jsonCluster struct {
+	Label    string   `json:"label"`
+	Vertices []string `json:"vertices"`
+}
+
+// WriteJSON writes the JSON representation of the [Graph] to the given
+// [io.Writer]. Vertices and edges are written in a stable order, along with
+// their Dot attributes, so that the graph can be consumed by other tools.
+func WriteJSON(g *Graph, w io.Writer) error {
+	jg := jsonGraph{
+		Attributes: g.GetDotAttributes(),
+		Vertices:   make([]jsonVertex, 0),
+		Edges:      make([]jsonEdge, 0),
+		Clusters:   make([]jsonCluster, 0),
+	}
+
+	vertices := g.GetVertexValues()
+	slices.Sort(vertices)
+	for _, v := range vertices {
+		jg.Vertices = append(jg.Vertices, jsonVertex{Name: v, Attributes: g.GetVertex(v).DotAttributes})
+	}
+
+	for _, e := range sortedEdges(g) {
+		jg.Edges = append(jg.Edges, jsonEdge{From: e.From, To: e.To, Attributes: e.DotAttributes})
+	}
+
+	for _, c := range g.clusters {
+		members := make([]string, 0, len(c.vertices))
+		for _, v := range c.vertices {
+			if g.VertexExists(v) {
+				members = append(members, v)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		slices.Sort(members)
+		jg.Clusters = append(jg.Clusters, jsonCluster{Label: c.label, Vertices: members})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(jg)
+}
+
+// sortedEdges returns the edges of the graph sorted by their source and
+// destination vertices.
+func sortedEdges(g *Graph) []*graph.Edge[string] {
+	edges := slices.Clone(g.GetEdges())
+	slices.SortFunc(edges, func(a, b *graph.Edge[string]) int {
+		if c := strings.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+		return strings.Compare(a.To, b.To)
+	})
+
+	return edges
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ErrInvalidLabelTemplate is returned when a label template cannot be
+// parsed.
+var ErrInvalidLabelTemplate = errors.New("invalid label template")
+
+// ErrLabelTemplateFailed is returned when a label template fails to render
+// the label of a resource.
+var ErrLabelTemplateFailed = errors.New("label template failed")
+
+// LabelData is the data, which is available to a [LabelTemplate] when
+// rendering the label of a resource vertex.
+type LabelData struct {
+	// Vertex is the name of the vertex representing the resource
+	Vertex string
+
+	// APIVersion is the API version of the resource
+	APIVersion string
+
+	// Kind is the kind of the resource
+	Kind string
+
+	// Name is the name of the resource
+	Name string
+
+	// Namespace is the namespace of the resource, which is empty for
+	// cluster-scoped resources.
+	Namespace string
+
+	// Labels contains the labels of the resource
+	Labels map[string]string
+
+	// Annotations contains the annotations of the resource
+	Annotations map[string]string
+
+	// Details contains the key details of the resource, such as container
+	// images, replica count and service type.
+	Details []string
+}
+
+// labelTemplateFuncs contains the functions available to label templates, in
+// addition to the builtin template functions.
+var labelTemplateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  func(sep string, items []string) string { return strings.Join(items, sep) },
+	"trunc": func(n int, s string) string {
+		if n < 0 || len(s) <= n {
+			return s
+		}
+		return s[:n]
+	},
+}
+
+// LabelTemplate is a parsed Go template, which renders the labels of the
+// resource vertices using [LabelData], e.g. {{ .Kind }}\n{{ .Name }}.
+type LabelTemplate struct {
+	// text is the source of the template
+	text string
+
+	// tmpl is the parsed template
+	tmpl *template.Template
+}
+
+// NewLabelTemplate parses the given text into a [LabelTemplate]. The \n
+// escape sequence within the text is interpreted as a line break, which is
+// convenient when the template is given on the command-line.
+func NewLabelTemplate(text string) (*LabelTemplate, error) {
+	tmpl, err := template.New("label").
+		Funcs(labelTemplateFuncs).
+		Option("missingkey=zero").
+		Parse(strings.ReplaceAll(text, `\n`, "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidLabelTemplate, text, err)
+	}
+
+	lt := &LabelTemplate{
+		text: text,
+		tmpl: tmpl,
+	}
+
+	return lt, nil
+}
+
+// String returns the source of the template.
+func (lt *LabelTemplate) String() string {
+	return lt.text
+}
+
+// render renders the label of the given [resource.Resource], which is
+// represented by the given vertex.
+func (lt *LabelTemplate) render(vertex string, r *resource.Resource) (string, error) {
+	data := LabelData{
+		Vertex:      vertex,
+		APIVersion:  r.GetApiVersion(),
+		Kind:        r.GetKind(),
+		Name:        r.GetName(),
+		Namespace:   r.GetNamespace(),
+		Labels:      r.GetLabels(),
+		Annotations: r.GetAnnotations(),
+		Details:     resourceDetails(r),
+	}
+
+	var buf bytes.Buffer
+	if err := lt.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrLabelTemplateFailed, vertex, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestWithLabelTemplate(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		text      string
+		vertex    string
+		wantLabel string
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "kind and name",
+			text:      `{{ .Kind }}\n{{ .Name }}`,
+			vertex:    "default/configmap/the-map",
+			wantLabel: "ConfigMap\nthe-map",
+		},
+		{
+			desc:      "functions",
+			text:      `{{ .Kind | lower }}/{{ .Name | trunc 7 }}`,
+			vertex:    "default/service/the-service",
+			wantLabel: "service/the-ser",
+		},
+		{
+			desc:      "missing label",
+			text:      `{{ .Name }}:{{ index .Labels "missing" }}`,
+			vertex:    "default/deployment/the-deployment",
+			wantLabel: "the-deployment:",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			lt, err := NewLabelTemplate(tc.text)
+			if err != nil {
+				t.Fatalf("failed to parse label template: %s", err)
+			}
+
+			p := New(WithLabelTemplate(lt))
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			v := g.GetVertex(tc.vertex)
+			if v == nil {
+				t.Fatalf("vertex %s not found", tc.vertex)
+			}
+			if got := v.DotAttributes["label"]; got != tc.wantLabel {
+				t.Fatalf("want label %q, got %q", tc.wantLabel, got)
+			}
+		})
+	}
+}
+
+func TestLabelTemplateErrors(t *testing.T) {
+	if _, err := NewLabelTemplate(`{{ .Kind `); !errors.Is(err, ErrInvalidLabelTemplate) {
+		t.Fatalf("want ErrInvalidLabelTemplate, got %v", err)
+	}
+
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	lt, err := NewLabelTemplate(`{{ .Unknown }}`)
+	if err != nil {
+		t.Fatalf("failed to parse label template: %s", err)
+	}
+
+	p := New(WithLabelTemplate(lt))
+	if _, err := p.ParseGraph(resources); !errors.Is(err, ErrLabelTemplateFailed) {
+		t.Fatalf("want ErrLabelTemplateFailed, got %v", err)
+	}
+}
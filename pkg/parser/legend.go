@@ -0,0 +1,245 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// legendLabel is the label of the cluster containing the legend.
+const legendLabel = "Legend"
+
+// legendPrefix is the prefix of the names of the legend vertices, which
+// keeps them apart from the rest of the vertices.
+const legendPrefix = "legend/"
+
+// LegendPosition is a type which represents the position of the legend
+// relative to the rest of the graph.
+type LegendPosition string
+
+// String implements the [fmt.Stringer] interface
+func (lp LegendPosition) String() string {
+	return string(lp)
+}
+
+const (
+	// LegendPositionBottom places the legend below the graph
+	LegendPositionBottom LegendPosition = "bottom"
+
+	// LegendPositionRight places the legend to the right of the graph
+	LegendPositionRight LegendPosition = "right"
+)
+
+// LegendPositions returns the list of supported legend positions.
+func LegendPositions() []LegendPosition {
+	positions := []LegendPosition{
+		LegendPositionBottom,
+		LegendPositionRight,
+	}
+
+	return positions
+}
+
+// sortedKeys returns the keys of the given map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// description returns a short description of the criteria of the rule.
+func (rule *Rule) description() string {
+	items := make([]string, 0)
+	if rule.Kind != "" {
+		items = append(items, fmt.Sprintf("kind=%s", rule.Kind))
+	}
+	if rule.Namespace != "" {
+		items = append(items, fmt.Sprintf("namespace=%s", rule.Namespace))
+	}
+	for _, k := range sortedKeys(rule.Labels) {
+		items = append(items, fmt.Sprintf("%s=%s", k, rule.Labels[k]))
+	}
+	if rule.Origin != "" {
+		items = append(items, fmt.Sprintf("origin=%s", rule.Origin))
+	}
+	if len(items) == 0 {
+		return "any"
+	}
+
+	return strings.Join(items, " ")
+}
+
+// addLegend adds a cluster to the graph, which describes the shapes of the
+// vertices and the styles of the edges found in the graph, along with the
+// colors of the highlighted kinds, namespaces and rules. The autoColored map
+// contains the automatically assigned colors of the namespaces or kinds.
+func (p *Parser) addLegend(g *Graph, autoColored map[string]string) {
+	// Collect the shapes and styles before adding any legend vertices
+	shapes := make(map[string]bool)
+	for _, v := range g.GetVertices() {
+		shapes[v.DotAttributes["shape"]] = true
+	}
+	styles := make(map[string]bool)
+	for _, e := range g.GetEdges() {
+		styles[e.DotAttributes["style"]] = true
+	}
+
+	// The heads are the legend vertices without incoming edges, which are
+	// used to position the legend.
+	heads := make([]string, 0)
+	addVertex := func(name, label string) *graph.Vertex[string] {
+		v := g.AddVertex(legendPrefix + name)
+		v.DotAttributes["label"] = label
+		g.addToCluster(legendLabel, v.Value)
+		heads = append(heads, v.Value)
+		return v
+	}
+
+	// Vertex shapes
+	vertexShapes := []struct {
+		shape string
+		label string
+	}{
+		{shape: p.resourceShape, label: "resource"},
+		{shape: p.originShape, label: "origin"},
+		{shape: directoryShape, label: "source"},
+		{shape: imageShape, label: "image"},
+	}
+	for _, item := range vertexShapes {
+		if shapes[item.shape] {
+			addVertex("shape/"+item.label, item.label).DotAttributes["shape"] = item.shape
+		}
+	}
+
+	// Orphan resources
+	if p.orphanStyle != nil {
+		v := addVertex("orphan", "orphan")
+		v.DotAttributes["shape"] = p.resourceShape
+		maps.Copy(v.DotAttributes, p.orphanStyle)
+	}
+
+	// Highlight colors
+	paint := func(v *graph.Vertex[string], color string) {
+		v.DotAttributes["shape"] = p.resourceShape
+		v.DotAttributes["color"] = color
+		v.DotAttributes["fillcolor"] = color
+	}
+	for _, key := range sortedKeys(autoColored) {
+		label := strings.TrimSuffix(p.autoColor.String(), "s") + " " + key
+		paint(addVertex("auto/"+key, label), autoColored[key])
+	}
+	for _, kind := range sortedKeys(p.highlightKindMap) {
+		paint(addVertex("kind/"+kind, "kind "+kind), p.highlightKindMap[kind])
+	}
+	for _, ns := range sortedKeys(p.highlightNamespaceMap) {
+		paint(addVertex("namespace/"+ns, "namespace "+ns), p.highlightNamespaceMap[ns])
+	}
+	for i, nh := range p.highlightNameRegexps {
+		paint(addVertex(fmt.Sprintf("name/%d", i), "name "+nh.re.String()), nh.color)
+	}
+	for i, rule := range p.rules {
+		if rule.Action == RuleActionHighlight {
+			v := addVertex(fmt.Sprintf("rule/%d", i), "rule "+rule.description())
+			v.DotAttributes["shape"] = p.resourceShape
+			rule.highlight(v)
+		}
+	}
+
+	if p.previousResources != nil {
+		for _, change := range []Change{ChangeAdded, ChangeModified} {
+			v := addVertex("change/"+string(change), string(change))
+			v.DotAttributes["shape"] = p.resourceShape
+			applyChange(v, change)
+		}
+	}
+
+	for i, style := range p.styles {
+		v := addVertex(fmt.Sprintf("style/%d", i), "style "+style.criteria().description())
+		v.DotAttributes["shape"] = p.resourceShape
+		style.apply(v)
+	}
+
+	// Edge styles. Types of edges sharing the same style are described
+	// together, since they cannot be told apart in the graph.
+	edgeTypes := make(map[string][]string)
+	for _, et := range EdgeTypes() {
+		style := p.edgeStyles[et]
+		edgeTypes[style] = append(edgeTypes[style], et.String())
+	}
+	for _, style := range sortedKeys(edgeTypes) {
+		if !styles[style] {
+			continue
+		}
+		name := edgeTypes[style][0]
+		from := addVertex("edge/"+name, strings.Join(edgeTypes[style], ", ")+" edge")
+		from.DotAttributes["shape"] = "plaintext"
+		from.DotAttributes["style"] = ""
+		to := addVertex("edge/"+name+"/end", "")
+		to.DotAttributes["shape"] = "point"
+		heads = heads[:len(heads)-1]
+		e := g.AddEdge(from.Value, to.Value)
+		if style != "" {
+			e.DotAttributes["style"] = style
+		}
+	}
+
+	p.positionLegend(g, heads)
+}
+
+// positionLegend moves the legend with the given head vertices to the
+// configured position. The legend vertices are placed in the first rank of
+// the graph, and after the rest of the vertices of that rank, which puts the
+// legend in the requested position, unless it is the side of the last rank.
+// In that case invisible edges are added from the vertices without outgoing
+// edges to the legend, so that it is ranked after them.
+func (p *Parser) positionLegend(g *Graph, heads []string) {
+	lastRank := map[LayoutDirection]LegendPosition{
+		LayoutDirectionLR: LegendPositionRight,
+		LayoutDirectionTB: LegendPositionBottom,
+	}
+	if p.legendPosition == "" || lastRank[p.layoutDirection] != p.legendPosition {
+		return
+	}
+
+	for _, v := range sinkVertices(g) {
+		if strings.HasPrefix(v, legendPrefix) {
+			continue
+		}
+		for _, head := range heads {
+			e := g.AddEdge(v, head)
+			e.DotAttributes["style"] = "invis"
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestWithLegend(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc         string
+		opts         []Option
+		wantVertices []string
+		missing      []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:         "no legend",
+			opts:         []Option{},
+			wantVertices: []string{},
+			missing:      []string{"legend/shape/resource"},
+		},
+		{
+			desc: "full graph with highlights",
+			opts: []Option{
+				WithLegend(),
+				WithShowImages(),
+				WithHighlightKind("Service", "yellow"),
+				WithHighlightNamespace("default", "green"),
+				WithRules(&Rule{Action: RuleActionHighlight, Kind: "ConfigMap", Color: "red"}),
+			},
+			wantVertices: []string{
+				"legend/shape/resource",
+				"legend/shape/origin",
+				"legend/shape/image",
+				"legend/kind/service",
+				"legend/namespace/default",
+				"legend/rule/0",
+				"legend/edge/origin",
+				"legend/edge/relationship",
+			},
+			missing: []string{"legend/shape/source", "legend/edge/patch", "legend/orphan"},
+		},
+		{
+			desc:         "marked orphans",
+			opts:         []Option{WithLegend(), WithOrphanStyle(nil)},
+			wantVertices: []string{"legend/orphan"},
+		},
+		{
+			desc:         "auto colored namespaces",
+			opts:         []Option{WithLegend(), WithAutoColor(AutoColorNamespaces)},
+			wantVertices: []string{"legend/shape/resource", "legend/auto/default"},
+			missing:      []string{"legend/namespace/default"},
+		},
+		{
+			desc:         "custom edge styles",
+			opts:         []Option{WithLegend(), WithShowImages(), WithEdgeStyle(EdgeTypeImage, "bold")},
+			wantVertices: []string{"legend/edge/origin", "legend/edge/image"},
+			missing:      []string{"legend/edge/relationship"},
+		},
+		{
+			desc:         "changed resources",
+			opts:         []Option{WithLegend(), WithPreviousResources(resources[:1])},
+			wantVertices: []string{"legend/change/added", "legend/change/modified"},
+		},
+		{
+			desc:         "resources only",
+			opts:         []Option{WithLegend(), WithGraphMode(GraphModeResources)},
+			wantVertices: []string{"legend/shape/resource"},
+			missing:      []string{"legend/shape/origin", "legend/edge/origin", "legend/edge/relationship"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			vertices := g.GetVertexValues()
+			for _, v := range tc.wantVertices {
+				if !slices.Contains(vertices, v) {
+					t.Fatalf("want legend vertex %s", v)
+				}
+			}
+			for _, v := range tc.missing {
+				if slices.Contains(vertices, v) {
+					t.Fatalf("unexpected legend vertex %s", v)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDot(g, &buf); err != nil {
+				t.Fatalf("failed to write dot: %s", err)
+			}
+			gotLegend := strings.Contains(buf.String(), `label="Legend"`)
+			if gotLegend != (len(tc.wantVertices) > 0) {
+				t.Fatalf("want legend cluster %t, got %t", len(tc.wantVertices) > 0, gotLegend)
+			}
+		})
+	}
+}
+
+func TestWithLegendPosition(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc       string
+		layout     LayoutDirection
+		position   LegendPosition
+		wantRanked bool
+	}
+
+	testCases := []testCase{
+		{desc: "right of left-to-right", layout: LayoutDirectionLR, position: LegendPositionRight, wantRanked: true},
+		{desc: "bottom of left-to-right", layout: LayoutDirectionLR, position: LegendPositionBottom, wantRanked: false},
+		{desc: "bottom of top-to-bottom", layout: LayoutDirectionTB, position: LegendPositionBottom, wantRanked: true},
+		{desc: "right of top-to-bottom", layout: LayoutDirectionTB, position: LegendPositionRight, wantRanked: false},
+		{desc: "right of right-to-left", layout: LayoutDirectionRL, position: LegendPositionRight, wantRanked: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(WithLayoutDirection(tc.layout), WithLegendPosition(tc.position))
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			if !slices.Contains(g.GetVertexValues(), "legend/shape/resource") {
+				t.Fatal("want legend vertices")
+			}
+
+			invisible := 0
+			for _, e := range g.GetEdges() {
+				if e.DotAttributes["style"] != "invis" {
+					continue
+				}
+				invisible++
+				if strings.HasPrefix(e.From, legendPrefix) || !strings.HasPrefix(e.To, legendPrefix) {
+					t.Fatalf("unexpected invisible edge %s -> %s", e.From, e.To)
+				}
+				if strings.HasSuffix(e.To, "/end") {
+					t.Fatalf("unexpected invisible edge to the end of legend edge %s", e.To)
+				}
+			}
+			if gotRanked := invisible > 0; gotRanked != tc.wantRanked {
+				t.Fatalf("want legend ranked after the graph %t, got %t", tc.wantRanked, gotRanked)
+			}
+		})
+	}
+}
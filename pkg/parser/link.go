@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ErrInvalidSourceLinkTemplate is returned when a source link template cannot
+// be parsed or executed.
+var ErrInvalidSourceLinkTemplate = errors.New("invalid source link template")
+
+// defaultRef is the ref used in source links for origins without a ref.
+const defaultRef = "HEAD"
+
+const (
+	// SourceLinkGitHub is the source link template for files hosted on
+	// GitHub.
+	SourceLinkGitHub = "{{ .Repo }}/blob/{{ .Ref }}/{{ .Path }}"
+
+	// SourceLinkGitLab is the source link template for files hosted on
+	// GitLab.
+	SourceLinkGitLab = "{{ .Repo }}/-/blob/{{ .Ref }}/{{ .Path }}"
+)
+
+// SourceLinkData is the data, which is available to a [SourceLinkTemplate]
+// when rendering the link to the file an origin refers to.
+type SourceLinkData struct {
+	// Repo is the URL of the remote repository, e.g.
+	// https://github.com/kubernetes-sigs/kustomize
+	Repo string
+
+	// Ref is the ref of the remote repository, or HEAD, when the origin
+	// doesn't specify one.
+	Ref string
+
+	// Path is the path to the file, rooted at the repository
+	Path string
+}
+
+// SourceLinkTemplate is a parsed Go template, which renders the links from
+// origin vertices to the respective files in the forge hosting the remote
+// repository, using [SourceLinkData].
+type SourceLinkTemplate struct {
+	// text is the source of the template
+	text string
+
+	// tmpl is the parsed template
+	tmpl *template.Template
+}
+
+// NewSourceLinkTemplate parses the given text into a [SourceLinkTemplate],
+// e.g. [SourceLinkGitHub]. The template is executed once against empty data,
+// so that invalid field references are reported early.
+func NewSourceLinkTemplate(text string) (*SourceLinkTemplate, error) {
+	tmpl, err := template.New("link").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSourceLinkTemplate, text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, SourceLinkData{}); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSourceLinkTemplate, text, err)
+	}
+
+	lt := &SourceLinkTemplate{
+		text: text,
+		tmpl: tmpl,
+	}
+
+	return lt, nil
+}
+
+// String returns the source of the template.
+func (lt *SourceLinkTemplate) String() string {
+	return lt.text
+}
+
+// render renders the link to the file the given [resource.Origin] refers to,
+// which is represented by the given path. It returns false for local origins,
+// and when the template fails to render.
+func (lt *SourceLinkTemplate) render(path string, origin *resource.Origin) (string, bool) {
+	if origin.Repo == "" {
+		return "", false
+	}
+
+	ref := origin.Ref
+	if ref == "" {
+		ref = defaultRef
+	}
+
+	data := SourceLinkData{
+		Repo: repoURL(origin.Repo),
+		Ref:  ref,
+		Path: strings.TrimPrefix(path, "/"),
+	}
+
+	var buf bytes.Buffer
+	if err := lt.tmpl.Execute(&buf, data); err != nil {
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+// repoURL returns the web URL of the given remote repository, e.g.
+// git@github.com:org/repo.git becomes https://github.com/org/repo.
+func repoURL(repo string) string {
+	repo = strings.TrimSuffix(repo, ".git")
+	if rest, ok := strings.CutPrefix(repo, "git@"); ok {
+		return "https://" + strings.Replace(rest, ":", "/", 1)
+	}
+	if !strings.Contains(repo, "://") {
+		return "https://" + repo
+	}
+
+	return repo
+}
+
+// applySourceLink sets the link to the file the given [resource.Origin] refers
+// to on the [graph.Vertex] v representing the origin, if a source link
+// template is configured.
+func (p *Parser) applySourceLink(v *graph.Vertex[string], origin *resource.Origin) {
+	if p.sourceLinkTemplate == nil {
+		return
+	}
+
+	if link, ok := p.sourceLinkTemplate.render(v.Value, origin); ok {
+		v.DotAttributes["href"] = link
+	}
+}
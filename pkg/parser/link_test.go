@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithSourceLinks(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: examples/helloWorld/configMap.yaml
+      repo: https://github.com/kubernetes-sigs/kustomize
+      ref: v1.0.6
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote-no-ref
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/configMap.yaml
+      repo: git@gitlab.com:example/app.git
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: configmap.yaml
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc     string
+		text     string
+		vertex   string
+		wantLink string
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "github",
+			text:     SourceLinkGitHub,
+			vertex:   "examples/helloWorld/configMap.yaml",
+			wantLink: "https://github.com/kubernetes-sigs/kustomize/blob/v1.0.6/examples/helloWorld/configMap.yaml",
+		},
+		{
+			desc:     "gitlab without ref",
+			text:     SourceLinkGitLab,
+			vertex:   "base/configMap.yaml",
+			wantLink: "https://gitlab.com/example/app/-/blob/HEAD/base/configMap.yaml",
+		},
+		{
+			desc:     "local origin",
+			text:     SourceLinkGitHub,
+			vertex:   "configmap.yaml",
+			wantLink: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			lt, err := NewSourceLinkTemplate(tc.text)
+			if err != nil {
+				t.Fatalf("failed to parse source link template: %s", err)
+			}
+
+			p := New(WithSourceLinks(lt))
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			v := g.GetVertex(tc.vertex)
+			if v == nil {
+				t.Fatalf("vertex %s not found", tc.vertex)
+			}
+			if got := v.DotAttributes["href"]; got != tc.wantLink {
+				t.Fatalf("want link %q, got %q", tc.wantLink, got)
+			}
+		})
+	}
+}
+
+func TestSourceLinkTemplateErrors(t *testing.T) {
+	for _, text := range []string{`{{ .Repo `, `{{ .Unknown }}`} {
+		if _, err := NewSourceLinkTemplate(text); !errors.Is(err, ErrInvalidSourceLinkTemplate) {
+			t.Fatalf("want ErrInvalidSourceLinkTemplate for %s, got %v", text, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"cmp"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// distinct returns the distinct non-empty values returned by fn for the given
+// resources in sorted order.
+func distinct(resources []*resource.Resource, fn func(r *resource.Resource) (string, error)) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, r := range resources {
+		v, err := fn(r)
+		if err != nil {
+			return nil, err
+		}
+		if v != "" {
+			seen[v] = true
+		}
+	}
+
+	return sortedKeys(seen), nil
+}
+
+// Kinds returns the distinct kinds of the given resources in sorted order.
+func Kinds(resources []*resource.Resource) []string {
+	kinds, _ := distinct(resources, func(r *resource.Resource) (string, error) {
+		return r.GetKind(), nil
+	})
+
+	return kinds
+}
+
+// Namespaces returns the distinct namespaces of the given resources in sorted
+// order. Cluster-scoped resources have no namespace, and are skipped.
+func Namespaces(resources []*resource.Resource) []string {
+	namespaces, _ := distinct(resources, func(r *resource.Resource) (string, error) {
+		return r.GetNamespace(), nil
+	})
+
+	return namespaces
+}
+
+// Origins returns the distinct origin paths of the given resources in sorted
+// order. The origin path of generated resources is the path of their
+// generator config. Resources without origin are skipped.
+func Origins(resources []*resource.Resource) ([]string, error) {
+	return distinct(resources, func(r *resource.Resource) (string, error) {
+		origin, err := r.GetOrigin()
+		if err != nil || origin == nil {
+			return "", err
+		}
+
+		return cmp.Or(origin.Path, origin.ConfiguredIn), nil
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestList(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: monitoring
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: monitoring
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: base/kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+`
+	extra, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	resources = append(resources, extra...)
+
+	wantKinds := []string{"ConfigMap", "Deployment", "Namespace", "Service"}
+	if got := Kinds(resources); !slices.Equal(got, wantKinds) {
+		t.Fatalf("want kinds %v, got %v", wantKinds, got)
+	}
+
+	wantNamespaces := []string{"default", "monitoring"}
+	if got := Namespaces(resources); !slices.Equal(got, wantNamespaces) {
+		t.Fatalf("want namespaces %v, got %v", wantNamespaces, got)
+	}
+
+	wantOrigins := []string{
+		"base/kustomization.yaml",
+		"examples/helloWorld/configMap.yaml",
+		"examples/helloWorld/deployment.yaml",
+		"examples/helloWorld/service.yaml",
+	}
+	gotOrigins, err := Origins(resources)
+	if err != nil {
+		t.Fatalf("failed to list origins: %s", err)
+	}
+	if !slices.Equal(gotOrigins, wantOrigins) {
+		t.Fatalf("want origins %v, got %v", wantOrigins, gotOrigins)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"log/slog"
+	"time"
+)
+
+// logStage logs the duration of the parsing stage, which started at the given
+// time, along with the size of the graph after it. It returns the start time
+// of the next stage.
+func (p *Parser) logStage(g *Graph, stage string, start time.Time) time.Time {
+	p.logger.Debug(
+		"completed stage",
+		"stage", stage,
+		"duration", time.Since(start),
+		"vertices", len(g.GetVertices()),
+		"edges", len(g.GetEdges()),
+	)
+
+	return time.Now()
+}
+
+// filterLogAttrs returns the log attributes describing the configured filters.
+// Filters, which are not configured, are omitted.
+func (p *Parser) filterLogAttrs() []any {
+	attrs := make([]any, 0)
+	values := []struct {
+		name   string
+		values []string
+	}{
+		{"drop-kind", p.dropResourceKinds},
+		{"drop-namespace", p.dropNamespaces},
+		{"keep-kind", p.keepResourceKinds},
+		{"keep-namespace", p.keepNamespaces},
+		{"drop-group", p.dropGroups},
+		{"keep-group", p.keepGroups},
+		{"drop-owner-kind", p.dropOwnerKinds},
+		{"keep-owner-kind", p.keepOwnerKinds},
+		{"drop-has-label", p.dropHasLabels},
+		{"keep-has-label", p.keepHasLabels},
+		{"keep-resource", p.keepResources},
+	}
+	for _, v := range values {
+		if len(v.values) > 0 {
+			attrs = append(attrs, slog.Any(v.name, v.values))
+		}
+	}
+
+	counts := []struct {
+		name  string
+		count int
+	}{
+		{"drop-name-regex", len(p.dropNameRegexps)},
+		{"keep-name-regex", len(p.keepNameRegexps)},
+		{"drop-origin-path", len(p.dropOriginPaths)},
+		{"keep-origin-path", len(p.keepOriginPaths)},
+		{"filter-expr", len(p.filterExprs)},
+		{"rule", len(p.rules)},
+		{"query", len(p.queries)},
+	}
+	for _, c := range counts {
+		if c.count > 0 {
+			attrs = append(attrs, slog.Int(c.name, c.count))
+		}
+	}
+
+	flags := []struct {
+		name  string
+		value bool
+	}{
+		{"drop-cluster-scoped", p.dropClusterScoped},
+		{"keep-cluster-scoped-only", p.keepClusterScopedOnly},
+		{"local-only", p.localOnly},
+		{"remote-only", p.remoteOnly},
+		{"drop-generated", p.dropGenerated},
+	}
+	for _, f := range flags {
+		if f.value {
+			attrs = append(attrs, slog.Bool(f.name, f.value))
+		}
+	}
+
+	return attrs
+}
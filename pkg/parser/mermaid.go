@@ -0,0 +1,154 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// mermaidDirections maps the Dot rankdir values to Mermaid flowchart
+// directions.
+var mermaidDirections = map[string]string{
+	"TB": "TB",
+	"BT": "BT",
+	"LR": "LR",
+	"RL": "RL",
+}
+
+// mermaidLabel escapes the label, so that it can be used as a Mermaid node or
+// edge label.
+func mermaidLabel(label string) string {
+	label = strings.ReplaceAll(label, `"`, "#quot;")
+	label = strings.ReplaceAll(label, `\n`, "<br>")
+	label = strings.ReplaceAll(label, `\l`, "<br>")
+	label = strings.ReplaceAll(label, "|", "#124;")
+
+	return strings.TrimSuffix(label, "<br>")
+}
+
+// mermaidColor converts the Dot color to a hex color, which can be used in
+// Mermaid styles.
+func mermaidColor(color string) (string, bool) {
+	rgb, ok := parseColor(color)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("#%06x", rgb), true
+}
+
+// mermaidArrow returns the Mermaid arrow for an edge with the given style.
+func mermaidArrow(style string) string {
+	switch style {
+	case "dotted", "dashed":
+		return "-.->"
+	case "bold":
+		return "==>"
+	case "invis":
+		return "~~~"
+	default:
+		return "-->"
+	}
+}
+
+// WriteMermaid writes the Mermaid flowchart representation of the [Graph] to
+// the given [io.Writer]. Vertices are written with their labels and colors,
+// HTML labels are replaced by the vertex names, and the clusters of the graph
+// are rendered as subgraphs.
+func WriteMermaid(g *Graph, w io.Writer) error {
+	direction, ok := mermaidDirections[g.GetDotAttributes()["rankdir"]]
+	if !ok {
+		direction = "TB"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "flowchart %s\n", direction)
+
+	vertices := g.GetVertexValues()
+	slices.Sort(vertices)
+	ids := make(map[string]string, len(vertices))
+	for i, v := range vertices {
+		ids[v] = fmt.Sprintf("n%d", i)
+	}
+
+	for _, v := range vertices {
+		attrs := g.GetVertex(v).DotAttributes
+		label, ok := attrs["label"]
+		if !ok || isHTMLLabel(label) {
+			label = v
+		}
+		fmt.Fprintf(&sb, "\t%s[\"%s\"]\n", ids[v], mermaidLabel(label))
+	}
+
+	for i, c := range g.clusters {
+		members := make([]string, 0, len(c.vertices))
+		for _, v := range c.vertices {
+			if id, ok := ids[v]; ok {
+				members = append(members, id)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\tsubgraph cluster_%d[\"%s\"]\n", i, mermaidLabel(c.label))
+		for _, id := range members {
+			fmt.Fprintf(&sb, "\t\t%s\n", id)
+		}
+		sb.WriteString("\tend\n")
+	}
+
+	for _, e := range sortedEdges(g) {
+		arrow := mermaidArrow(e.DotAttributes["style"])
+		if label := e.DotAttributes["label"]; label != "" {
+			arrow = fmt.Sprintf("%s|\"%s\"|", arrow, mermaidLabel(label))
+		}
+		fmt.Fprintf(&sb, "\t%s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+
+	for _, v := range vertices {
+		attrs := g.GetVertex(v).DotAttributes
+		styles := make([]string, 0)
+		if fill, ok := mermaidColor(attrs["fillcolor"]); ok {
+			styles = append(styles, "fill:"+fill)
+		}
+		if stroke, ok := mermaidColor(attrs["color"]); ok {
+			styles = append(styles, "stroke:"+stroke)
+		}
+		if font, ok := mermaidColor(attrs["fontcolor"]); ok {
+			styles = append(styles, "color:"+font)
+		}
+		if len(styles) > 0 {
+			fmt.Fprintf(&sb, "\tstyle %s %s\n", ids[v], strings.Join(styles, ","))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
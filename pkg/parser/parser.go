@@ -28,14 +28,22 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/dnaeon/go-graph.v1"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -43,6 +51,62 @@ import (
 // which will be stripped when we generate the graph.
 const notClonedPrefix = "notCloned/"
 
+// helmChartInflationGeneratorKind is the kind of the builtin kustomize
+// generator, which inflates Helm charts.
+const helmChartInflationGeneratorKind = "HelmChartInflationGenerator"
+
+// patchTransformerKinds contains the kinds of the builtin kustomize
+// transformers, which apply patches to resources.
+var patchTransformerKinds = []string{
+	"PatchTransformer",
+	"PatchStrategicMergeTransformer",
+	"PatchJson6902Transformer",
+}
+
+// generatorKinds contains the kinds of the builtin kustomize generators, which
+// generate ConfigMap and Secret resources.
+var generatorKinds = []string{
+	"ConfigMapGenerator",
+	"SecretGenerator",
+}
+
+// generatedKinds contains the lower-cased kinds of resources, which are
+// produced by the builtin kustomize generators.
+var generatedKinds = []string{
+	"configmap",
+	"secret",
+}
+
+// nameHashSuffixRegexp matches the content hash suffix, which kustomize
+// appends to the names of generated resources. The hash consists of 10
+// characters from the alphabet used by kustomize to encode it.
+var nameHashSuffixRegexp = regexp.MustCompile(`-[2456789bcdfghkmt]{10}$`)
+
+// coreGroup is the name used to refer to the core Kubernetes API group, which
+// is otherwise represented by an empty string.
+const coreGroup = "core"
+
+// helmChartLabel is the standard label set by Helm charts on their resources,
+// which contains the chart name and version.
+const helmChartLabel = "helm.sh/chart"
+
+const (
+	// DefaultResourceShape is the default shape of vertices representing
+	// resources.
+	DefaultResourceShape = "box"
+
+	// DefaultOriginShape is the default shape of vertices representing
+	// the files from which resources originate.
+	DefaultOriginShape = "note"
+
+	// directoryShape is the shape of vertices representing directories
+	// and sources of origins.
+	directoryShape = "folder"
+
+	// imageShape is the shape of vertices representing container images.
+	imageShape = "cylinder"
+)
+
 // LayoutDirection is a type which represents the direction of the graph layout.
 type LayoutDirection string
 
@@ -65,6 +129,53 @@ const (
 	LayoutDirectionRL LayoutDirection = "RL"
 )
 
+// FilterPrecedence is a type which represents the order in which the filter
+// rules and the rest of the filtering options are evaluated.
+type FilterPrecedence string
+
+// String implements the [fmt.Stringer] interface
+func (fp FilterPrecedence) String() string {
+	return string(fp)
+}
+
+const (
+	// FilterPrecedenceOptions specifies that the filtering options are
+	// evaluated first, and drop filters take precedence over keep filters.
+	// The filter rules are evaluated only for resources, which were not
+	// dropped by the options.
+	FilterPrecedenceOptions FilterPrecedence = "options"
+
+	// FilterPrecedenceRules specifies that the filter rules are evaluated
+	// first, and the first keep or drop rule matching a resource decides
+	// whether it is kept, regardless of the filtering options. The
+	// filtering options are evaluated only for resources, which were not
+	// matched by any rule.
+	FilterPrecedenceRules FilterPrecedence = "rules"
+)
+
+// GraphMode is a type which represents the kind of vertices, which are
+// included in the graph.
+type GraphMode string
+
+// String implements the [fmt.Stringer] interface
+func (gm GraphMode) String() string {
+	return string(gm)
+}
+
+const (
+	// GraphModeFull specifies that both resources and their origins are
+	// included in the graph.
+	GraphModeFull GraphMode = "full"
+
+	// GraphModeOrigins specifies that only the origins of resources and
+	// the relationships between them are included in the graph.
+	GraphModeOrigins GraphMode = "origins"
+
+	// GraphModeResources specifies that only resources and the
+	// relationships between them are included in the graph.
+	GraphModeResources GraphMode = "resources"
+)
+
 // NewDepProvider creates a new [provider.DepProvider].
 func NewDepProvider() *provider.DepProvider {
 	return provider.NewDefaultDepProvider()
@@ -124,9 +235,112 @@ type Parser struct {
 	// respective namespace.
 	highlightNamespaceMap map[string]string
 
+	// highlightNameRegexps contains the regular expressions matching the
+	// names of resources, and the color with which to paint the matching
+	// resources.
+	highlightNameRegexps []nameHighlight
+
+	// styles contains the ordered list of style rules, which set the
+	// visual attributes of the resource vertices.
+	styles []*StyleRule
+
 	// layoutDirection specifies the direction of graph layout.
 	layoutDirection LayoutDirection
 
+	// theme specifies the set of graph, node and edge attributes, which
+	// are applied to the graph.
+	theme Theme
+
+	// graphAttributes contains the Dot attributes, which are set on the
+	// graph as is.
+	graphAttributes graph.DotAttributes
+
+	// nodeAttributes contains the Dot attributes, which are set on the
+	// vertices of resources of a given kind.
+	nodeAttributes []kindAttributes
+
+	// edgeStyles contains the styles of the edges by type.
+	edgeStyles map[EdgeType]string
+
+	// gradientHighlights specifies whether resources highlighted by both
+	// namespace and kind are filled with a gradient of the two colors.
+	gradientHighlights bool
+
+	// background specifies the background color of the graph, which
+	// overrides the background of the theme. When empty, the background
+	// of the theme is used.
+	background string
+
+	// fontContrast specifies whether the font color of vertices with a fill
+	// color of their own is adjusted to the brightness of the fill.
+	fontContrast bool
+
+	// autoColor specifies the property of resources, by which they are
+	// automatically assigned a color.
+	autoColor AutoColor
+
+	// graphFont is the font used for the graph and cluster labels.
+	graphFont font
+
+	// nodeFont is the font used for the vertex labels.
+	nodeFont font
+
+	// edgeFont is the font used for the edge labels.
+	edgeFont font
+
+	// imageSize is the size and resolution of the rendered images.
+	imageSize imageSize
+
+	// maxVertices is the max number of vertices of the graph, before the
+	// threshold action is taken. Non-positive values mean unlimited.
+	maxVertices int
+
+	// maxEdges is the max number of edges of the graph, before the
+	// threshold action is taken. Non-positive values mean unlimited.
+	maxEdges int
+
+	// thresholdAction specifies what is done, when the graph has more
+	// vertices or edges than allowed.
+	thresholdAction ThresholdAction
+
+	// collapse specifies whether the resources are always collapsed into
+	// one vertex per namespace and kind.
+	collapse bool
+
+	// showDetails specifies whether the key details of resources, such as
+	// container images, replica count and service type, are included in
+	// the vertex labels.
+	showDetails bool
+
+	// showImages specifies whether the container images of workloads are
+	// added as vertices to the graph.
+	showImages bool
+
+	// showPatches specifies whether resources are connected to the
+	// kustomizations, whose patches modified them.
+	showPatches bool
+
+	// groupRemotes specifies whether origins from the same remote
+	// repository and ref are grouped into a cluster.
+	groupRemotes bool
+
+	// separateRanks specifies whether origins and resources are placed on
+	// separate ranks.
+	separateRanks bool
+
+	// sameRankKinds contains the kinds of resources, which are placed on
+	// the same rank, one rank per kind.
+	sameRankKinds []string
+
+	// graphMode specifies the kind of vertices included in the graph.
+	graphMode GraphMode
+
+	// resourceShape specifies the shape of resource vertices.
+	resourceShape string
+
+	// originShape specifies the shape of origin vertices.
+	originShape string
+
 	// dropResourceKinds contains the list of resource kinds, which will be
 	// dropped from the resulting graph.
 	dropResourceKinds []string
@@ -145,6 +359,185 @@ type Parser struct {
 	// will be kept. Any resource, which is not in the specified namespaces
 	// will be dropped.
 	keepNamespaces []string
+
+	// dropClusterScoped specifies whether cluster-scoped resources are
+	// dropped from the resulting graph.
+	dropClusterScoped bool
+
+	// keepClusterScopedOnly specifies whether only cluster-scoped
+	// resources are kept in the resulting graph.
+	keepClusterScopedOnly bool
+
+	// localOnly specifies whether only resources originating from the
+	// local kustomization are kept in the resulting graph.
+	localOnly bool
+
+	// remoteOnly specifies whether only resources originating from remote
+	// repositories are kept in the resulting graph.
+	remoteOnly bool
+
+	// dropGroups contains the list of API groups, or group/version pairs,
+	// whose resources will be dropped from the resulting graph.
+	dropGroups []string
+
+	// keepGroups contains the list of API groups, or group/version pairs,
+	// whose resources will be kept. Any other resource will be dropped
+	// from the resulting graph.
+	keepGroups []string
+
+	// dropOwnerKinds contains the list of kinds. Resources having an owner
+	// reference of any of the kinds will be dropped from the resulting
+	// graph.
+	dropOwnerKinds []string
+
+	// keepOwnerKinds contains the list of kinds. Resources, which don't
+	// have an owner reference of any of the kinds will be dropped from the
+	// resulting graph.
+	keepOwnerKinds []string
+
+	// dropHasLabels contains the list of label keys. Resources having any
+	// of the labels will be dropped from the resulting graph.
+	dropHasLabels []string
+
+	// keepHasLabels contains the list of label keys. Resources, which
+	// don't have any of the labels will be dropped from the resulting
+	// graph.
+	keepHasLabels []string
+
+	// keepResources contains the names of the resource vertices, which
+	// will be kept. Any other resource will be dropped from the resulting
+	// graph.
+	keepResources []string
+
+	// dropNameRegexps contains the list of regular expressions, which are
+	// matched against resource names. Resources with matching names will
+	// be dropped from the resulting graph.
+	dropNameRegexps []pattern
+
+	// keepNameRegexps contains the list of regular expressions, which are
+	// matched against resource names. Resources, whose names don't match
+	// any of the expressions will be dropped from the resulting graph.
+	keepNameRegexps []pattern
+
+	// dropOriginPaths contains the list of glob patterns, which are
+	// matched against the origin paths of resources. Resources with
+	// matching origin paths will be dropped from the resulting graph.
+	dropOriginPaths []pattern
+
+	// keepOriginPaths contains the list of glob patterns, which are
+	// matched against the origin paths of resources. Resources, whose
+	// origin paths don't match any of the patterns will be dropped from
+	// the resulting graph.
+	keepOriginPaths []pattern
+
+	// filterExprs contains the list of CEL expressions, which are
+	// evaluated against resources. Resources, for which any of the
+	// expressions doesn't evaluate to true will be dropped from the
+	// resulting graph.
+	filterExprs []*FilterExpr
+
+	// rules contains the ordered list of filter rules, which keep, drop
+	// and highlight resources.
+	rules []*Rule
+
+	// filterPrecedence specifies the order in which the filter rules and
+	// the filtering options are evaluated.
+	filterPrecedence FilterPrecedence
+
+	// caseSensitive specifies whether the kind, namespace and group filters
+	// are matched case-sensitively.
+	caseSensitive bool
+
+	// exactMatch specifies whether the kind, namespace and group filters
+	// are matched literally, without expanding wildcards.
+	exactMatch bool
+
+	// showLegend specifies whether a legend describing the shapes, styles
+	// and colors used in the graph is added to it.
+	showLegend bool
+
+	// legendPosition specifies the position of the legend. When empty, the
+	// legend is placed wherever the layout puts it.
+	legendPosition LegendPosition
+
+	// showTooltips specifies whether the metadata of resources is shown
+	// as tooltips of the resource vertices.
+	showTooltips bool
+
+	// sourceLinkTemplate is the template used to render the links from
+	// origin vertices to the files in the forge hosting them.
+	sourceLinkTemplate *SourceLinkTemplate
+
+	// showIcons specifies whether to embed the Kubernetes icons in the
+	// labels of the resource vertices.
+	showIcons bool
+
+	// iconsDir is the directory containing the Kubernetes icons, which
+	// override the bundled icons.
+	iconsDir string
+
+	// labelTemplate is the template used to render the labels of the
+	// resource vertices.
+	labelTemplate *LabelTemplate
+
+	// queries contains the list of jq queries, which select the resources
+	// before the graph is constructed.
+	queries []*Query
+
+	// dropGenerated specifies whether resources produced by the ConfigMap
+	// and Secret generators are dropped from the resulting graph.
+	dropGenerated bool
+
+	// pruneUnreachable specifies whether vertices, which are not
+	// reachable from any resource vertex are dropped from the resulting
+	// graph.
+	pruneUnreachable bool
+
+	// dropOrphans specifies whether vertices, which are not connected to
+	// any other vertex are dropped from the resulting graph.
+	dropOrphans bool
+
+	// orphanStyle contains the Dot attributes, which are set on the
+	// resources, whose relationships are expected but were not discovered,
+	// e.g. PodDisruptionBudgets matching no workload. Orphan resources are
+	// not marked, unless it is set.
+	orphanStyle graph.DotAttributes
+
+	// focusVertices contains the names of the vertices, whose
+	// neighbourhood will be kept in the resulting graph. Any vertex
+	// outside of the neighbourhood will be dropped.
+	focusVertices []string
+
+	// focusDepth specifies the max number of hops from the focus vertices,
+	// which will be kept in the resulting graph.
+	focusDepth int
+
+	// rootVertices contains the names of the vertices, from which the max
+	// depth is measured. When empty, the vertices without outgoing edges
+	// are used as roots.
+	rootVertices []string
+
+	// maxDepth specifies the max number of hops from the root vertices,
+	// which will be kept in the resulting graph. A negative value means
+	// that the depth is not limited.
+	maxDepth int
+
+	// previousResources contains the resources from a previous build,
+	// against which added and modified resources are emphasized. When nil,
+	// no resources are emphasized.
+	previousResources []*resource.Resource
+
+	// longestPath specifies whether the longest path of the graph is
+	// highlighted.
+	longestPath bool
+
+	// longestPathTarget is the vertex, at which the highlighted longest
+	// path ends. When empty, the longest path of the whole graph is
+	// highlighted.
+	longestPathTarget string
+
+	// logger is used to log the progress of parsing at debug level
+	logger *slog.Logger
 }
 
 // New creates a new [Parser] and configures it using the specified options.
@@ -152,11 +545,43 @@ func New(opts ...Option) *Parser {
 	p := &Parser{
 		highlightKindMap:      make(map[string]string),
 		highlightNamespaceMap: make(map[string]string),
+		highlightNameRegexps:  make([]nameHighlight, 0),
+		styles:                make([]*StyleRule, 0),
 		layoutDirection:       LayoutDirectionLR,
+		theme:                 ThemeDefault,
+		autoColor:             AutoColorNone,
+		edgeStyles:            newEdgeStyles(),
+		nodeAttributes:        make([]kindAttributes, 0),
+		graphAttributes:       make(graph.DotAttributes),
+		graphMode:             GraphModeFull,
+		thresholdAction:       ThresholdActionFail,
+		resourceShape:         DefaultResourceShape,
+		originShape:           DefaultOriginShape,
 		dropResourceKinds:     make([]string, 0),
 		dropNamespaces:        make([]string, 0),
 		keepResourceKinds:     make([]string, 0),
 		keepNamespaces:        make([]string, 0),
+		dropGroups:            make([]string, 0),
+		keepGroups:            make([]string, 0),
+		dropOwnerKinds:        make([]string, 0),
+		keepOwnerKinds:        make([]string, 0),
+		dropHasLabels:         make([]string, 0),
+		keepHasLabels:         make([]string, 0),
+		keepResources:         make([]string, 0),
+		dropNameRegexps:       make([]pattern, 0),
+		keepNameRegexps:       make([]pattern, 0),
+		dropOriginPaths:       make([]pattern, 0),
+		keepOriginPaths:       make([]pattern, 0),
+		filterExprs:           make([]*FilterExpr, 0),
+		rules:                 make([]*Rule, 0),
+		filterPrecedence:      FilterPrecedenceOptions,
+		queries:               make([]*Query, 0),
+		focusVertices:         make([]string, 0),
+		focusDepth:            1,
+		rootVertices:          make([]string, 0),
+		sameRankKinds:         make([]string, 0),
+		maxDepth:              -1,
+		logger:                slog.New(slog.NewTextHandler(io.Discard, nil)),
 	}
 
 	for _, opt := range opts {
@@ -166,6 +591,26 @@ func New(opts ...Option) *Parser {
 	return p
 }
 
+// nameHighlight represents the color with which to paint resources, whose
+// names match a regular expression.
+type nameHighlight struct {
+	// re is the regular expression matching the names of resources
+	re *regexp.Regexp
+
+	// color is the color with which to paint the matching resources
+	color string
+}
+
+// kindAttributes represents the Dot attributes, which are set on the vertices
+// of resources of a given kind.
+type kindAttributes struct {
+	// kind is the kind of resources
+	kind string
+
+	// attrs contains the Dot attributes
+	attrs graph.DotAttributes
+}
+
 // Option is a function which configures the [Parser].
 type Option func(p *Parser)
 
@@ -189,6 +634,30 @@ func WithHighlightNamespace(namespace string, color string) Option {
 	return opt
 }
 
+// WithHighlightNameRegexp is an [Option] which configures the [Parser] to
+// paint all resources, whose names match the given regular expression, with
+// the specified color, regardless of their kind and namespace. When names
+// match more than one regular expression, the last one wins.
+func WithHighlightNameRegexp(re *regexp.Regexp, color string) Option {
+	opt := func(p *Parser) {
+		p.highlightNameRegexps = append(p.highlightNameRegexps, nameHighlight{re: re, color: color})
+	}
+
+	return opt
+}
+
+// WithStyles is an [Option] which configures the [Parser] to apply the given
+// style rules to the resource vertices. All style rules matching a resource
+// are applied in order after the highlight options and rules, so that later
+// style rules override earlier ones.
+func WithStyles(styles ...*StyleRule) Option {
+	opt := func(p *Parser) {
+		p.styles = append(p.styles, styles...)
+	}
+
+	return opt
+}
+
 // WithLayoutDirection is an [Option] which configures the [Parser] to generate
 // the graph with the specified direction.
 func WithLayoutDirection(layout LayoutDirection) Option {
@@ -199,190 +668,1594 @@ func WithLayoutDirection(layout LayoutDirection) Option {
 	return opt
 }
 
-// WithDropKind is an [Option], which configures the [Parser] to drop the
-// specified Kubernetes resource kind from the resulting graph.
-func WithDropKind(kind string) Option {
+// WithLabelTemplate is an [Option] which configures the [Parser] to render
+// the labels of the resource vertices using the given [LabelTemplate]. The
+// template takes precedence over the details included by [WithShowDetails].
+func WithLabelTemplate(lt *LabelTemplate) Option {
 	opt := func(p *Parser) {
-		p.dropResourceKinds = append(p.dropResourceKinds, strings.ToLower(kind))
+		p.labelTemplate = lt
 	}
 
 	return opt
 }
 
-// WithKeepKind is an [Option], which configures the [Parser] to keep only
-// resources of the given kind. Any other resource kind will be dropped from the
-// resulting graph.
-func WithKeepKind(kind string) Option {
+// WithGraphFont is an [Option] which configures the [Parser] to use the
+// given font name and size in points for the graph and cluster labels. An
+// empty name or a non-positive size leaves the respective Graphviz default.
+func WithGraphFont(name string, size float64) Option {
 	opt := func(p *Parser) {
-		p.keepResourceKinds = append(p.keepResourceKinds, strings.ToLower(kind))
+		p.graphFont = font{name: name, size: size}
 	}
 
 	return opt
 }
 
-// WithDropNamespace is an [Option], which configures the [Parser] to drop all
-// resources from the specified namespace.
-func WithDropNamespace(namespace string) Option {
+// WithNodeFont is an [Option] which configures the [Parser] to use the given
+// font name and size in points for the vertex labels. See [WithGraphFont] for
+// the handling of empty values.
+func WithNodeFont(name string, size float64) Option {
 	opt := func(p *Parser) {
-		p.dropNamespaces = append(p.dropNamespaces, strings.ToLower(namespace))
+		p.nodeFont = font{name: name, size: size}
 	}
 
 	return opt
 }
 
-// WithKeepNamespace is an [Option], which configures the [Parser] to keep only
-// resources from the specified namespace. Any other resource will be dropped
-// from the resulting graph.
-func WithKeepNamespace(namespace string) Option {
+// WithEdgeFont is an [Option] which configures the [Parser] to use the given
+// font name and size in points for the edge labels. See [WithGraphFont] for
+// the handling of empty values.
+func WithEdgeFont(name string, size float64) Option {
 	opt := func(p *Parser) {
-		p.keepNamespaces = append(p.keepNamespaces, strings.ToLower(namespace))
+		p.edgeFont = font{name: name, size: size}
 	}
 
 	return opt
 }
 
-// Parse parses the given sequence of [resource.Resource] items in order to
-// generate a directed [graph.Graph].
-func (p *Parser) Parse(resources []*resource.Resource) (graph.Graph[string], error) {
-	g := graph.New[string](graph.KindDirected)
+// WithDPI is an [Option] which configures the [Parser] to render images with
+// the given resolution in dots per inch. A non-positive resolution leaves the
+// Graphviz default.
+func WithDPI(dpi float64) Option {
+	opt := func(p *Parser) {
+		p.imageSize.dpi = dpi
+	}
 
-	for _, r := range resources {
-		if p.shouldDropResource(r) {
-			continue
-		}
+	return opt
+}
 
-		// Add u to the graph, and paint the vertex
-		uName := p.vertexNameFromResource(r)
-		u := g.AddVertex(uName)
-		p.applyHighlights(u, r)
+// WithMaxSize is an [Option] which configures the [Parser] to scale down
+// drawings, which are larger than the given width and height in inches. A
+// non-positive width or height leaves the respective dimension unbounded.
+func WithMaxSize(width, height float64) Option {
+	opt := func(p *Parser) {
+		p.imageSize.maxWidth = width
+		p.imageSize.maxHeight = height
+	}
 
-		// Add v to the graph, which represents the resource origin
-		origin, err := r.GetOrigin()
-		if err != nil {
-			return nil, err
-		}
+	return opt
+}
 
-		// No origin metadata found, skip it
-		if origin == nil {
-			continue
-		}
+// WithRatio is an [Option] which configures the [Parser] to lay out the
+// drawing with the given aspect ratio, e.g. [RatioFill] or Ratio("0.5").
+func WithRatio(ratio Ratio) Option {
+	opt := func(p *Parser) {
+		p.imageSize.ratio = ratio
+	}
 
-		vName := p.vertexNameFromOrigin(origin)
-		g.AddVertex(vName)
+	return opt
+}
 
-		e := g.AddEdge(uName, vName)
-		label := p.edgeLabelFromOrigin(origin)
-		e.DotAttributes["label"] = label
+// WithMaxVertices is an [Option] which configures the [Parser] to take the
+// threshold action, when the graph has more than the given number of
+// vertices. Non-positive values mean unlimited.
+func WithMaxVertices(n int) Option {
+	opt := func(p *Parser) {
+		p.maxVertices = n
 	}
 
-	// Set direction of graph layout and other graph-specific attributes
-	graphAttrs := g.GetDotAttributes()
-	graphAttrs["rankdir"] = p.layoutDirection.String()
-
-	return g, nil
+	return opt
 }
 
-// shouldDropResource is a predicate, which returns true, if the resource is to
-// be dropped from the graph, and returns false otherwise.
-func (p *Parser) shouldDropResource(r *resource.Resource) bool {
-	kind := strings.ToLower(r.GetKind())
-	namespace := strings.ToLower(r.GetNamespace())
-	gvk := r.GetGvk()
-
-	// Drop resource, if it is part of any drop-namespaces
-	for _, dn := range p.dropNamespaces {
-		if namespace == dn {
-			return true
-		}
+// WithMaxEdges is an [Option] which configures the [Parser] to take the
+// threshold action, when the graph has more than the given number of edges.
+// Non-positive values mean unlimited.
+func WithMaxEdges(n int) Option {
+	opt := func(p *Parser) {
+		p.maxEdges = n
 	}
 
-	// Drop resource, if it is part of any drop-resource-kinds
-	for _, drk := range p.dropResourceKinds {
-		if kind == drk {
-			return true
-		}
-	}
+	return opt
+}
 
-	// Drop resources, if they are outside of the configured keep-namespaces
-	keepNamespaceIsSet := false
-	keepKindIsSet := false
-	foundKeepNamespace := false
-	foundKeepKind := false
-	if len(p.keepNamespaces) > 0 && !gvk.IsClusterScoped() {
-		keepNamespaceIsSet = true
-		for _, kn := range p.keepNamespaces {
-			if namespace == kn {
-				foundKeepNamespace = true
-				break
-			}
-		}
+// WithThresholdAction is an [Option] which configures the [Parser] to take the
+// given action, when the graph has more vertices or edges than allowed by
+// [WithMaxVertices] and [WithMaxEdges]. Defaults to [ThresholdActionFail].
+func WithThresholdAction(action ThresholdAction) Option {
+	opt := func(p *Parser) {
+		p.thresholdAction = action
 	}
 
-	// Drop resources, if they are not part of the configured
-	// keep-resource-kinds.
-	if len(p.keepResourceKinds) > 0 {
-		keepKindIsSet = true
-		for _, krk := range p.keepResourceKinds {
-			if kind == krk {
-				foundKeepKind = true
-				break
-			}
-		}
-	}
+	return opt
+}
 
-	switch {
-	case keepNamespaceIsSet && !foundKeepNamespace:
-		// Resource is not part of the keep-namespaces, so drop it.
-		return true
-	case keepNamespaceIsSet && keepKindIsSet && foundKeepNamespace && !foundKeepKind:
-		// Resource is part of the keep-namespaces, but not part of the
-		// keep-resource-kinds, so drop it.
-		return true
-	case keepKindIsSet && !foundKeepKind:
-		// Resource is not part of the keep-resource-kinds, so drop it
-		return true
-	default:
-		// Don't drop the resource
-		return false
+// WithCollapse is an [Option] which configures the [Parser] to collapse the
+// resources into one vertex per namespace and kind, e.g. "default/deployment
+// (3)", which summarizes large graphs.
+func WithCollapse() Option {
+	opt := func(p *Parser) {
+		p.collapse = true
 	}
-}
 
-// applyHighlights applies the highlight styles to the [graph.Vertex] u for
-// [resource.Resource] r.
-func (p *Parser) applyHighlights(u *graph.Vertex[string], r *resource.Resource) {
-	// First we paint resources by namespace
-	namespace := strings.ToLower(r.GetNamespace())
-	kind := strings.ToLower(r.GetKind())
+	return opt
+}
 
-	namespaceColor, ok := p.highlightNamespaceMap[namespace]
-	if ok {
-		u.DotAttributes["color"] = namespaceColor
-		u.DotAttributes["fillcolor"] = namespaceColor
+// WithLegend is an [Option] which configures the [Parser] to add a legend
+// cluster to the graph, which describes the shapes of the vertices and the
+// styles of the edges, along with the highlight colors of kinds, namespaces
+// and rules.
+func WithLegend() Option {
+	opt := func(p *Parser) {
+		p.showLegend = true
 	}
 
-	// Then we paint resources by kind
-	kindColor, ok := p.highlightKindMap[kind]
-	if ok {
-		u.DotAttributes["color"] = kindColor
-		u.DotAttributes["fillcolor"] = kindColor
-	}
+	return opt
 }
 
-// vertexNameFromResource returns a string representing the vertex name for the
-// given [resource.Resource].
-func (p *Parser) vertexNameFromResource(r *resource.Resource) string {
-	namespace := r.GetNamespace()
-	name := r.GetName()
-	gvk := r.GetGvk()
-	kind := strings.ToLower(r.GetKind())
+// WithLegendPosition is an [Option] which configures the [Parser] to add a
+// legend to the graph, which is placed in the given position.
+func WithLegendPosition(position LegendPosition) Option {
+	opt := func(p *Parser) {
+		p.showLegend = true
+		p.legendPosition = position
+	}
 
-	// Cluster-scoped resource
-	if gvk.IsClusterScoped() {
-		return fmt.Sprintf("%s/%s", kind, name)
+	return opt
+}
+
+// WithTooltips is an [Option] which configures the [Parser] to set the
+// tooltip of the resource vertices to the metadata of the resources, such as
+// API version, labels and origin, which is revealed when hovering over the
+// vertices in SVG output.
+func WithTooltips() Option {
+	opt := func(p *Parser) {
+		p.showTooltips = true
 	}
 
-	// Namespace-scoped resource
-	return fmt.Sprintf("%s/%s/%s", namespace, kind, name)
+	return opt
+}
+
+// WithSourceLinks is an [Option] which configures the [Parser] to set the
+// href attribute of the vertices representing files from remote repositories,
+// so that they link to the files in the forge hosting them, e.g. GitHub. The
+// links are rendered using the given [SourceLinkTemplate].
+func WithSourceLinks(lt *SourceLinkTemplate) Option {
+	opt := func(p *Parser) {
+		p.sourceLinkTemplate = lt
+	}
+
+	return opt
+}
+
+// WithIcons is an [Option] which configures the [Parser] to render the
+// resource vertices using HTML-like labels, which embed the icon of the
+// resource kind. The icons bundled with the parser are written to the user
+// cache directory, so that Graphviz may load them. The icons found in the
+// given directory, e.g. the official Kubernetes icon set, override the bundled
+// icons. No directory is required in order to use the bundled icons only.
+func WithIcons(dir string) Option {
+	opt := func(p *Parser) {
+		p.showIcons = true
+		p.iconsDir = dir
+	}
+
+	return opt
+}
+
+// WithTheme is an [Option] which configures the [Parser] to style the graph
+// using the given [Theme].
+func WithTheme(theme Theme) Option {
+	opt := func(p *Parser) {
+		p.theme = theme
+	}
+
+	return opt
+}
+
+// WithBackground is an [Option] which configures the [Parser] to use the given
+// background color for the graph, overriding the background of the theme.
+func WithBackground(color string) Option {
+	opt := func(p *Parser) {
+		p.background = color
+	}
+
+	return opt
+}
+
+// WithTransparentBackground is an [Option] which configures the [Parser] to
+// render the graph on a transparent background, which is useful when
+// embedding graphs in slides and web pages.
+func WithTransparentBackground() Option {
+	return WithBackground(transparentBackground)
+}
+
+// WithFontContrast is an [Option] which configures the [Parser] to switch the
+// font color of vertices with a dark fill color, e.g. highlighted vertices, to
+// white, and of vertices with a light fill color to black, so that their
+// labels remain readable regardless of the chosen colors. Font colors set
+// explicitly, e.g. via style rules, are left as is.
+func WithFontContrast() Option {
+	opt := func(p *Parser) {
+		p.fontContrast = true
+	}
+
+	return opt
+}
+
+// WithAutoColor is an [Option] which configures the [Parser] to assign a
+// distinct color to each namespace or kind of resources. The colors are
+// picked from a palette by hashing the namespace or kind, so that the same
+// namespace or kind is always painted with the same color. Explicit
+// highlight colors take precedence over the automatic ones.
+func WithAutoColor(ac AutoColor) Option {
+	opt := func(p *Parser) {
+		p.autoColor = ac
+	}
+
+	return opt
+}
+
+// WithGraphAttribute is an [Option] which configures the [Parser] to set the
+// given Dot attribute on the graph, e.g. nodesep, ranksep or splines. The
+// attribute is passed to Dot as is, and overrides the attributes set by any
+// other option, e.g. the layout direction or the theme.
+func WithGraphAttribute(name string, value string) Option {
+	opt := func(p *Parser) {
+		p.graphAttributes[name] = value
+	}
+
+	return opt
+}
+
+// WithNodeAttributes is an [Option] which configures the [Parser] to set the
+// given Dot attributes on the vertices of all resources of the given kind. The
+// kind may be qualified with an API version, e.g. apps/v1/Deployment, and may
+// contain wildcards. The attributes are set after any highlight and style
+// rules, and are passed to Dot as is. This option may be specified multiple
+// times, in which case later attributes override earlier ones.
+func WithNodeAttributes(kind string, attrs graph.DotAttributes) Option {
+	opt := func(p *Parser) {
+		p.nodeAttributes = append(p.nodeAttributes, kindAttributes{kind: kind, attrs: attrs})
+	}
+
+	return opt
+}
+
+// WithEdgeStyle is an [Option] which configures the [Parser] to render the
+// edges of the given type using the specified style, e.g. dotted or bold. The
+// style may be validated using [ValidateEdgeStyle].
+func WithEdgeStyle(edgeType EdgeType, style string) Option {
+	opt := func(p *Parser) {
+		p.edgeStyles[edgeType] = style
+	}
+
+	return opt
+}
+
+// WithGradientHighlights is an [Option] which configures the [Parser] to fill
+// resources, which are highlighted by both namespace and kind, with a
+// gradient of the namespace and kind colors, instead of the kind color
+// overriding the namespace color.
+func WithGradientHighlights() Option {
+	opt := func(p *Parser) {
+		p.gradientHighlights = true
+	}
+
+	return opt
+}
+
+// WithShowDetails is an [Option] which configures the [Parser] to include
+// the key details of resources in the vertex labels, such as the container
+// images and replica count of workloads, or the type of services.
+func WithShowDetails() Option {
+	opt := func(p *Parser) {
+		p.showDetails = true
+	}
+
+	return opt
+}
+
+// WithShowImages is an [Option] which configures the [Parser] to add the
+// container images used by workloads as vertices, which are connected to the
+// workloads using them.
+func WithShowImages() Option {
+	opt := func(p *Parser) {
+		p.showImages = true
+	}
+
+	return opt
+}
+
+// WithShowPatches is an [Option] which configures the [Parser] to connect
+// resources to the kustomizations, whose patches modified them. This requires
+// the transformerAnnotations build option to be enabled in kustomize.
+func WithShowPatches() Option {
+	opt := func(p *Parser) {
+		p.showPatches = true
+	}
+
+	return opt
+}
+
+// WithGroupRemotes is an [Option] which configures the [Parser] to group
+// origins from the same remote repository and ref into a cluster, labeled with
+// the repository URL and ref.
+func WithGroupRemotes() Option {
+	opt := func(p *Parser) {
+		p.groupRemotes = true
+	}
+
+	return opt
+}
+
+// WithSeparateRanks is an [Option] which configures the [Parser] to place all
+// resource vertices on one rank, and all origin vertices on another. This
+// option has effect only when the graph contains both resources and origins.
+func WithSeparateRanks() Option {
+	opt := func(p *Parser) {
+		p.separateRanks = true
+	}
+
+	return opt
+}
+
+// WithSameRankKind is an [Option] which configures the [Parser] to place
+// all resource vertices of the given kind on the same rank. The kind may be
+// qualified with an API version, e.g. apps/v1/Deployment, and may contain
+// wildcards. This option may be specified multiple times, and each kind is
+// placed on a rank of its own.
+func WithSameRankKind(kind string) Option {
+	opt := func(p *Parser) {
+		p.sameRankKinds = append(p.sameRankKinds, kind)
+	}
+
+	return opt
+}
+
+// WithGraphMode is an [Option] which configures the [Parser] to generate
+// the graph using the specified mode.
+func WithGraphMode(mode GraphMode) Option {
+	opt := func(p *Parser) {
+		p.graphMode = mode
+	}
+
+	return opt
+}
+
+// WithResourceShape is an [Option] which configures the [Parser] to render
+// resource vertices with the specified shape.
+func WithResourceShape(shape string) Option {
+	opt := func(p *Parser) {
+		p.resourceShape = shape
+	}
+
+	return opt
+}
+
+// WithOriginShape is an [Option] which configures the [Parser] to render
+// origin vertices with the specified shape.
+func WithOriginShape(shape string) Option {
+	opt := func(p *Parser) {
+		p.originShape = shape
+	}
+
+	return opt
+}
+
+// WithDropKind is an [Option], which configures the [Parser] to drop the
+// specified Kubernetes resource kind from the resulting graph. The kind may be
+// negated using the "!" prefix, e.g. !Deployment drops anything other than
+// Deployment resources. The kind may also be qualified with the API version,
+// e.g. apps/v1/Deployment, in order to distinguish between kinds with the same
+// name from different API groups.
+func WithDropKind(kind string) Option {
+	opt := func(p *Parser) {
+		p.dropResourceKinds = append(p.dropResourceKinds, kind)
+	}
+
+	return opt
+}
+
+// WithKeepKind is an [Option], which configures the [Parser] to keep only
+// resources of the given kind. Any other resource kind will be dropped from the
+// resulting graph. The kind may be negated using the "!" prefix, e.g.
+// !ConfigMap keeps anything other than ConfigMap resources, and may be
+// qualified with the API version, e.g. apps/v1/Deployment.
+func WithKeepKind(kind string) Option {
+	opt := func(p *Parser) {
+		p.keepResourceKinds = append(p.keepResourceKinds, kind)
+	}
+
+	return opt
+}
+
+// WithDropNamespace is an [Option], which configures the [Parser] to drop all
+// resources from the specified namespace. The namespace may be negated using
+// the "!" prefix.
+func WithDropNamespace(namespace string) Option {
+	opt := func(p *Parser) {
+		p.dropNamespaces = append(p.dropNamespaces, namespace)
+	}
+
+	return opt
+}
+
+// WithKeepNamespace is an [Option], which configures the [Parser] to keep only
+// resources from the specified namespace. Any other resource will be dropped
+// from the resulting graph. The namespace may be negated using the "!" prefix.
+func WithKeepNamespace(namespace string) Option {
+	opt := func(p *Parser) {
+		p.keepNamespaces = append(p.keepNamespaces, namespace)
+	}
+
+	return opt
+}
+
+// WithDropClusterScoped is an [Option], which configures the [Parser] to drop
+// all cluster-scoped resources, e.g. ClusterRole, CustomResourceDefinition,
+// etc.
+func WithDropClusterScoped() Option {
+	opt := func(p *Parser) {
+		p.dropClusterScoped = true
+	}
+
+	return opt
+}
+
+// WithKeepClusterScopedOnly is an [Option], which configures the [Parser] to
+// keep only cluster-scoped resources. Any namespaced resource will be dropped
+// from the resulting graph.
+func WithKeepClusterScopedOnly() Option {
+	opt := func(p *Parser) {
+		p.keepClusterScopedOnly = true
+	}
+
+	return opt
+}
+
+// WithLocalOnly is an [Option], which configures the [Parser] to keep only
+// resources, which originate from the local kustomization, i.e. resources
+// whose origin doesn't refer to a remote repository. Resources without origin
+// metadata are dropped.
+func WithLocalOnly() Option {
+	opt := func(p *Parser) {
+		p.localOnly = true
+	}
+
+	return opt
+}
+
+// WithRemoteOnly is an [Option], which configures the [Parser] to keep only
+// resources, which originate from remote repositories. Resources without
+// origin metadata are dropped.
+func WithRemoteOnly() Option {
+	opt := func(p *Parser) {
+		p.remoteOnly = true
+	}
+
+	return opt
+}
+
+// WithDropGroup is an [Option], which configures the [Parser] to drop all
+// resources from the given API group, e.g. rbac.authorization.k8s.io. The
+// group may also be qualified with a version, e.g. apps/v1, in which case
+// only resources from the given group and version are dropped. Resources
+// from the core API group are matched by the "core" group, and the group may
+// be negated using the "!" prefix.
+func WithDropGroup(group string) Option {
+	opt := func(p *Parser) {
+		p.dropGroups = append(p.dropGroups, group)
+	}
+
+	return opt
+}
+
+// WithKeepGroup is an [Option], which configures the [Parser] to keep only
+// resources from the given API group. See [WithDropGroup] for the supported
+// formats of the group.
+func WithKeepGroup(group string) Option {
+	opt := func(p *Parser) {
+		p.keepGroups = append(p.keepGroups, group)
+	}
+
+	return opt
+}
+
+// WithDropOwnerKind is an [Option], which configures the [Parser] to drop all
+// resources, which have an owner reference of the given kind, e.g. dropping
+// the ReplicaSet resources owned by Deployment resources. The kind may be
+// qualified with the API version, e.g. apps/v1/Deployment, and may be negated
+// using the "!" prefix.
+func WithDropOwnerKind(kind string) Option {
+	opt := func(p *Parser) {
+		p.dropOwnerKinds = append(p.dropOwnerKinds, kind)
+	}
+
+	return opt
+}
+
+// WithKeepOwnerKind is an [Option], which configures the [Parser] to keep only
+// resources, which have an owner reference of the given kind. See
+// [WithDropOwnerKind] for the supported formats of the kind.
+func WithKeepOwnerKind(kind string) Option {
+	opt := func(p *Parser) {
+		p.keepOwnerKinds = append(p.keepOwnerKinds, kind)
+	}
+
+	return opt
+}
+
+// WithDropHasLabel is an [Option], which configures the [Parser] to drop all
+// resources, which have the given label, regardless of its value. The label
+// may be negated using the "!" prefix.
+func WithDropHasLabel(label string) Option {
+	opt := func(p *Parser) {
+		p.dropHasLabels = append(p.dropHasLabels, label)
+	}
+
+	return opt
+}
+
+// WithKeepHasLabel is an [Option], which configures the [Parser] to keep only
+// resources, which have the given label, regardless of its value. When
+// specified multiple times, resources having any of the labels are kept. The
+// label may be negated using the "!" prefix.
+func WithKeepHasLabel(label string) Option {
+	opt := func(p *Parser) {
+		p.keepHasLabels = append(p.keepHasLabels, label)
+	}
+
+	return opt
+}
+
+// WithKeepResource is an [Option], which configures the [Parser] to keep only
+// the resource represented by the given vertex name, e.g.
+// default/configmap/the-map, or clusterrole/admin for cluster-scoped
+// resources. When specified multiple times, all of the given resources are
+// kept. Any other resource will be dropped from the resulting graph.
+func WithKeepResource(vertex string) Option {
+	opt := func(p *Parser) {
+		p.keepResources = append(p.keepResources, vertex)
+	}
+
+	return opt
+}
+
+// WithDropNameRegexp is an [Option], which configures the [Parser] to drop
+// all resources, whose names match the given regular expression.
+func WithDropNameRegexp(re *regexp.Regexp) Option {
+	opt := func(p *Parser) {
+		p.dropNameRegexps = append(p.dropNameRegexps, pattern{re: re})
+	}
+
+	return opt
+}
+
+// WithKeepNameRegexp is an [Option], which configures the [Parser] to keep
+// only resources, whose names match the given regular expression. When
+// specified multiple times, resources matching any of the expressions are
+// kept.
+func WithKeepNameRegexp(re *regexp.Regexp) Option {
+	opt := func(p *Parser) {
+		p.keepNameRegexps = append(p.keepNameRegexps, pattern{re: re})
+	}
+
+	return opt
+}
+
+// WithDropNamePrefix is an [Option], which configures the [Parser] to drop
+// all resources, whose names start with the given prefix. The prefix may be
+// negated using the "!" prefix.
+func WithDropNamePrefix(prefix string) Option {
+	opt := func(p *Parser) {
+		p.dropNameRegexps = append(p.dropNameRegexps, newPattern(prefix, prefixToRegexp))
+	}
+
+	return opt
+}
+
+// WithKeepNamePrefix is an [Option], which configures the [Parser] to keep
+// only resources, whose names start with the given prefix. Resources matching
+// any of the keep-name options are kept. The prefix may be negated using the
+// "!" prefix.
+func WithKeepNamePrefix(prefix string) Option {
+	opt := func(p *Parser) {
+		p.keepNameRegexps = append(p.keepNameRegexps, newPattern(prefix, prefixToRegexp))
+	}
+
+	return opt
+}
+
+// WithDropNameSuffix is an [Option], which configures the [Parser] to drop
+// all resources, whose names end with the given suffix. The suffix may be
+// negated using the "!" prefix.
+func WithDropNameSuffix(suffix string) Option {
+	opt := func(p *Parser) {
+		p.dropNameRegexps = append(p.dropNameRegexps, newPattern(suffix, suffixToRegexp))
+	}
+
+	return opt
+}
+
+// WithKeepNameSuffix is an [Option], which configures the [Parser] to keep
+// only resources, whose names end with the given suffix. Resources matching
+// any of the keep-name options are kept. The suffix may be negated using the
+// "!" prefix.
+func WithKeepNameSuffix(suffix string) Option {
+	opt := func(p *Parser) {
+		p.keepNameRegexps = append(p.keepNameRegexps, newPattern(suffix, suffixToRegexp))
+	}
+
+	return opt
+}
+
+// WithDropOriginPath is an [Option], which configures the [Parser] to drop all
+// resources, whose origin path matches the given glob pattern, e.g. base/**.
+// The origin path of remote resources is relative to the root of their
+// repository. The pattern may be negated using the "!" prefix.
+func WithDropOriginPath(glob string) Option {
+	opt := func(p *Parser) {
+		p.dropOriginPaths = append(p.dropOriginPaths, newPattern(glob, globToRegexp))
+	}
+
+	return opt
+}
+
+// WithKeepOriginPath is an [Option], which configures the [Parser] to keep
+// only resources, whose origin path matches the given glob pattern, e.g.
+// overlays/prod/**. Resources without origin are dropped. The pattern may be
+// negated using the "!" prefix.
+func WithKeepOriginPath(glob string) Option {
+	opt := func(p *Parser) {
+		p.keepOriginPaths = append(p.keepOriginPaths, newPattern(glob, globToRegexp))
+	}
+
+	return opt
+}
+
+// WithFilterExpr is an [Option], which configures the [Parser] to keep only
+// resources, for which the given [FilterExpr] evaluates to true. When
+// specified multiple times, resources must satisfy all expressions in order to
+// be kept.
+func WithFilterExpr(f *FilterExpr) Option {
+	opt := func(p *Parser) {
+		p.filterExprs = append(p.filterExprs, f)
+	}
+
+	return opt
+}
+
+// WithCaseSensitive is an [Option], which configures the [Parser] to match
+// the kind, namespace and group filters case-sensitively, e.g. the configmap
+// kind no longer matches ConfigMap resources.
+func WithCaseSensitive() Option {
+	opt := func(p *Parser) {
+		p.caseSensitive = true
+	}
+
+	return opt
+}
+
+// WithExactMatch is an [Option], which configures the [Parser] to match the
+// kind, namespace and group filters literally, without expanding the "*" and
+// "?" wildcards.
+func WithExactMatch() Option {
+	opt := func(p *Parser) {
+		p.exactMatch = true
+	}
+
+	return opt
+}
+
+// WithRules is an [Option], which configures the [Parser] to keep, drop and
+// highlight resources using the given ordered list of [Rule] items. The first
+// keep or drop rule matching a resource decides whether it is kept, and
+// resources not matched by any of them are dropped, if there are keep rules.
+// Resources are painted with the color of the last highlight rule matching
+// them.
+func WithRules(rules ...*Rule) Option {
+	opt := func(p *Parser) {
+		p.rules = append(p.rules, rules...)
+	}
+
+	return opt
+}
+
+// WithFilterPrecedence is an [Option], which configures the order in which the
+// [Parser] evaluates the filter rules and the filtering options. Using
+// [FilterPrecedenceRules] allows rules to override the options, e.g. keep the
+// ConfigMap resources from a namespace, which is otherwise dropped.
+func WithFilterPrecedence(precedence FilterPrecedence) Option {
+	opt := func(p *Parser) {
+		p.filterPrecedence = precedence
+	}
+
+	return opt
+}
+
+// WithQuery is an [Option], which configures the [Parser] to select the
+// resources using the given [Query] before constructing the graph. When
+// specified multiple times, the queries are applied in order, each one
+// receiving the resources selected by the previous one.
+func WithQuery(q *Query) Option {
+	opt := func(p *Parser) {
+		p.queries = append(p.queries, q)
+	}
+
+	return opt
+}
+
+// WithDropGenerated is an [Option], which configures the [Parser] to drop the
+// resources produced by the kustomize configMapGenerator and secretGenerator,
+// so that only hand-written resources are part of the resulting graph.
+// Generated resources are detected by their origin, or by the content hash
+// suffix of their names, when origin metadata is not available.
+func WithDropGenerated() Option {
+	opt := func(p *Parser) {
+		p.dropGenerated = true
+	}
+
+	return opt
+}
+
+// WithPruneUnreachable is an [Option], which configures the [Parser] to drop
+// the vertices, which are not reachable from any resource vertex once the
+// graph has been constructed and filtered, e.g. origins whose resources were
+// dropped by the focus or max depth options. This option has no effect when
+// the graph contains origins only.
+func WithPruneUnreachable() Option {
+	opt := func(p *Parser) {
+		p.pruneUnreachable = true
+	}
+
+	return opt
+}
+
+// DefaultOrphanStyle returns the Dot attributes, which mark the orphan
+// resources by default, i.e. a red dashed border.
+func DefaultOrphanStyle() graph.DotAttributes {
+	attrs := graph.DotAttributes{
+		"color":    "red",
+		"style":    "filled, rounded, dashed",
+		"penwidth": "2",
+	}
+
+	return attrs
+}
+
+// WithOrphanStyle is an [Option], which configures the [Parser] to mark the
+// orphan resources, i.e. PodDisruptionBudgets, ServiceMonitors and PodMonitors,
+// which select nothing from the build. The given Dot attributes are set on top
+// of the [DefaultOrphanStyle]. The orphan style is applied before any
+// highlights, so that they may override it.
+func WithOrphanStyle(attrs graph.DotAttributes) Option {
+	opt := func(p *Parser) {
+		if p.orphanStyle == nil {
+			p.orphanStyle = DefaultOrphanStyle()
+		}
+		maps.Copy(p.orphanStyle, attrs)
+	}
+
+	return opt
+}
+
+// WithDropOrphans is an [Option], which configures the [Parser] to drop the
+// vertices, which are not connected to any other vertex once the graph has
+// been constructed and filtered, e.g. resources without origin metadata.
+func WithDropOrphans() Option {
+	opt := func(p *Parser) {
+		p.dropOrphans = true
+	}
+
+	return opt
+}
+
+// WithFocus is an [Option], which configures the [Parser] to keep only the
+// neighbourhood of the given vertex, e.g. default/deployment/my-app. Any
+// vertex, which is not within the focus depth from the vertex will be dropped
+// from the resulting graph.
+func WithFocus(vertex string) Option {
+	opt := func(p *Parser) {
+		p.focusVertices = append(p.focusVertices, vertex)
+	}
+
+	return opt
+}
+
+// WithFocusDepth is an [Option], which configures the [Parser] to keep
+// vertices within the given number of hops from the focus vertices.
+func WithFocusDepth(depth int) Option {
+	opt := func(p *Parser) {
+		p.focusDepth = depth
+	}
+
+	return opt
+}
+
+// WithRoot is an [Option], which configures the [Parser] to measure the max
+// depth configured via [WithMaxDepth] from the given vertex. When no roots are
+// specified, the vertices without outgoing edges are used as roots, e.g. the
+// origins of resources, or the sources containing them.
+func WithRoot(vertex string) Option {
+	opt := func(p *Parser) {
+		p.rootVertices = append(p.rootVertices, vertex)
+	}
+
+	return opt
+}
+
+// WithMaxDepth is an [Option], which configures the [Parser] to keep only the
+// vertices within the given number of hops from the root vertices, trimming
+// deep transitive chains. Edges are followed in reverse direction, i.e. from
+// the roots towards the resources pointing to them.
+func WithMaxDepth(depth int) Option {
+	opt := func(p *Parser) {
+		p.maxDepth = depth
+	}
+
+	return opt
+}
+
+// WithPreviousResources is an [Option], which configures the [Parser] to
+// emphasize the resources, which were added or modified since the given
+// previous build, with a bold outline. Resources are matched by their vertex
+// name, and the annotations set by kustomize during the build, e.g. the origin
+// annotation, are ignored when comparing their content.
+func WithPreviousResources(resources []*resource.Resource) Option {
+	opt := func(p *Parser) {
+		p.previousResources = resources
+	}
+
+	return opt
+}
+
+// WithLogger is an [Option], which configures the [Parser] to log the
+// progress of parsing using the given [slog.Logger], e.g. the number of parsed
+// resources, the dropped resources along with the filter dropping them, and
+// the duration of each stage. Messages are logged at debug level.
+func WithLogger(logger *slog.Logger) Option {
+	opt := func(p *Parser) {
+		p.logger = logger
+	}
+
+	return opt
+}
+
+// WithLongestPath is an [Option], which configures the [Parser] to highlight
+// the longest path of the graph, i.e. the deepest chain of resources and
+// origins, e.g. the deepest layering of overlays.
+func WithLongestPath() Option {
+	opt := func(p *Parser) {
+		p.longestPath = true
+	}
+
+	return opt
+}
+
+// WithLongestPathTo is an [Option], which configures the [Parser] to highlight
+// the longest path of the graph, which ends at the given vertex.
+func WithLongestPathTo(vertex string) Option {
+	opt := func(p *Parser) {
+		p.longestPath = true
+		p.longestPathTarget = vertex
+	}
+
+	return opt
+}
+
+// Filter returns the [resource.Resource] items, which are selected by the
+// configured queries and kept by the configured filters and rules, in their
+// original order. These are the resources, which [Parser.Parse] adds to the
+// graph.
+func (p *Parser) Filter(resources []*resource.Resource) ([]*resource.Resource, error) {
+	// Select resources using the configured queries
+	for _, q := range p.queries {
+		selected, err := q.selectResources(resources)
+		if err != nil {
+			return nil, err
+		}
+		resources = selected
+	}
+
+	p.logger.Debug("applying filters", p.filterLogAttrs()...)
+	kept := make([]*resource.Resource, 0)
+	for _, r := range resources {
+		if reason := p.dropReason(r); reason != "" {
+			p.logger.Debug("dropped resource", "resource", p.vertexNameFromResource(r), "reason", reason)
+			continue
+		}
+		kept = append(kept, r)
+	}
+	p.logger.Debug("filtered resources", "selected", len(resources), "kept", len(kept))
+
+	return kept, nil
+}
+
+// Parse parses the given sequence of [resource.Resource] items in order to
+// generate a directed [graph.Graph]. The returned graph is a [*Graph], which
+// also carries the clusters, ranks and default attributes used when rendering
+// it. Use [Parser.ParseGraph] in order to get the [*Graph] directly.
+func (p *Parser) Parse(resources []*resource.Resource) (graph.Graph[string], error) {
+	return p.ParseContext(context.Background(), resources)
+}
+
+// ParseContext is like [Parser.Parse], but stops parsing with an error, when
+// the given context is cancelled.
+func (p *Parser) ParseContext(ctx context.Context, resources []*resource.Resource) (graph.Graph[string], error) {
+	g, err := p.ParseGraphContext(ctx, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// ParseGraph parses the given sequence of [resource.Resource] items in order
+// to generate a directed [Graph], which can be rendered using a [Renderer].
+func (p *Parser) ParseGraph(resources []*resource.Resource) (*Graph, error) {
+	return p.ParseGraphContext(context.Background(), resources)
+}
+
+// stageErr returns an error, if the context was cancelled by the time the
+// parsing stage with the given name completed.
+func stageErr(ctx context.Context, stage string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("cancelled after %s stage: %w", stage, err)
+	}
+
+	return nil
+}
+
+// ParseGraphContext is like [Parser.ParseGraph], but stops parsing with an
+// error, when the given context is cancelled. The context is checked after
+// each stage, i.e. filtering, building, reducing and styling the graph.
+func (p *Parser) ParseGraphContext(ctx context.Context, resources []*resource.Resource) (*Graph, error) {
+	g := newGraph()
+	p.logger.Debug("parsing resources", "resources", len(resources))
+
+	start := time.Now()
+	kept, err := p.Filter(resources)
+	if err != nil {
+		return nil, err
+	}
+	start = p.logStage(g, "filter", start)
+	if err := stageErr(ctx, "filter"); err != nil {
+		return nil, err
+	}
+
+	resourceVertices := make(map[string]bool)
+	sameRanks := make([][]string, len(p.sameRankKinds))
+	autoColored := make(map[string]string)
+	var changes *changeDetector
+	if p.previousResources != nil {
+		changes = p.newChangeDetector(p.previousResources)
+	}
+	var icons map[string]string
+	if p.showIcons {
+		icons, err = resolveIcons(p.iconsDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var orphans map[*resource.Resource]bool
+	if p.orphanStyle != nil && p.graphMode != GraphModeOrigins {
+		orphans = findOrphans(kept)
+	}
+	for _, r := range kept {
+		if p.graphMode == GraphModeOrigins {
+			origin, err := r.GetOrigin()
+			if err != nil {
+				return nil, err
+			}
+			if origin != nil {
+				p.addOriginVertices(g, origin)
+				p.addHelmChartVertex(g, r, origin)
+			}
+			continue
+		}
+
+		// Add u to the graph, and paint the vertex
+		uName := p.vertexNameFromResource(r)
+		u := g.AddVertex(uName)
+		u.DotAttributes["shape"] = p.resourceShape
+		resourceVertices[uName] = true
+		if key := autoColorKey(p.autoColor, r); key != "" {
+			autoColored[key] = paletteColor(key)
+		}
+		for i, kind := range p.sameRankKinds {
+			if kindMatches(r.GetGvk(), kind, p.valueMatches) {
+				sameRanks[i] = append(sameRanks[i], uName)
+			}
+		}
+		if orphans[r] {
+			maps.Copy(u.DotAttributes, p.orphanStyle)
+		}
+		p.applyHighlights(u, r)
+		if changes != nil {
+			applyChange(u, changes.change(uName, r))
+		}
+		if p.showDetails {
+			details := resourceDetails(r)
+			u.DotAttributes["label"] = strings.Join(append([]string{uName}, details...), "\n")
+		}
+		if p.labelTemplate != nil {
+			label, err := p.labelTemplate.render(uName, r)
+			if err != nil {
+				return nil, err
+			}
+			u.DotAttributes["label"] = label
+		}
+		if icons != nil {
+			applyIcon(u, r, icons)
+		}
+		if p.showTooltips {
+			u.DotAttributes["tooltip"] = resourceTooltip(r)
+		}
+
+		// Origins are not part of the graph
+		if p.graphMode == GraphModeResources {
+			continue
+		}
+
+		// Connect the resource to the patches, which modified it
+		if err := p.addPatchEdges(g, uName, r); err != nil {
+			return nil, err
+		}
+
+		// Add v to the graph, which represents the resource origin
+		origin, err := r.GetOrigin()
+		if err != nil {
+			return nil, err
+		}
+
+		// No origin metadata found, skip it
+		if origin == nil {
+			continue
+		}
+
+		vName := p.vertexNameFromOrigin(origin)
+		v := g.AddVertex(vName)
+		v.DotAttributes["shape"] = p.originShape
+		p.applySourceLink(v, origin)
+		if p.groupRemotes && origin.Repo != "" {
+			g.addToCluster(p.sourceNameFromOrigin(origin), vName)
+		}
+
+		label := p.edgeLabelFromOrigin(origin)
+
+		// Resources inflated from a Helm chart are connected to the chart,
+		// which in turn is connected to the kustomization inflating it.
+		if chart, ok := p.addHelmChartVertex(g, r, origin); ok {
+			vName = chart
+			label = ""
+			if origin.Repo != "" {
+				label = p.sourceNameFromOrigin(origin)
+			}
+		}
+
+		e := g.AddEdge(uName, vName)
+		e.DotAttributes["label"] = label
+		p.styleEdge(e, EdgeTypeOrigin)
+	}
+
+	// Place the resources of the selected kinds on the same rank
+	for _, rank := range sameRanks {
+		g.addRank(rank)
+	}
+
+	// Place resources and origins on separate ranks. This is done before
+	// adding any other vertices, so that these remain unranked.
+	if p.separateRanks && p.graphMode == GraphModeFull {
+		p.addSeparateRanks(g, resourceVertices)
+	}
+
+	// Connect resources, which are related to each other
+	if p.graphMode != GraphModeOrigins {
+		p.addRelationEdges(g, kept)
+		if p.showImages {
+			p.addImageVertices(g, kept)
+		}
+	}
+
+	start = p.logStage(g, "build", start)
+	if err := stageErr(ctx, "build"); err != nil {
+		return nil, err
+	}
+
+	// Reduce the graph to the neighbourhood of the focus vertices
+	if err := p.applyFocus(g); err != nil {
+		return nil, err
+	}
+
+	// Reduce the graph to the vertices within max depth from the roots
+	if err := p.applyMaxDepth(g); err != nil {
+		return nil, err
+	}
+
+	// Drop vertices, which are not reachable from any resource
+	if p.pruneUnreachable && p.graphMode != GraphModeOrigins {
+		pruneUnreachable(g, resourceVertices)
+	}
+
+	// Drop vertices, which are left without any edges
+	if p.dropOrphans {
+		dropIsolatedVertices(g)
+	}
+
+	// Fail or collapse the resources, when the graph is too large
+	if err := p.applyThresholds(g, resourceVertices); err != nil {
+		return nil, err
+	}
+
+	start = p.logStage(g, "reduce", start)
+	if err := stageErr(ctx, "reduce"); err != nil {
+		return nil, err
+	}
+
+	// Highlight the longest path of the final graph
+	if err := p.applyLongestPath(g); err != nil {
+		return nil, err
+	}
+
+	// Set direction of graph layout and other graph-specific attributes
+	graphAttrs := g.GetDotAttributes()
+	graphAttrs["rankdir"] = p.layoutDirection.String()
+	p.applyFonts(g)
+	p.imageSize.apply(graphAttrs)
+
+	// The legend describes the final graph, so it is added last
+	if p.showLegend {
+		p.addLegend(g, autoColored)
+	}
+
+	// The font contrast and the theme apply to the legend vertices as well
+	if p.fontContrast {
+		applyFontContrast(g)
+	}
+	applyTheme(g, p.theme)
+	if p.background != "" {
+		g.GetDotAttributes()["bgcolor"] = p.background
+	}
+
+	// Graph attributes passed as is have the final say
+	maps.Copy(g.GetDotAttributes(), p.graphAttributes)
+	p.logStage(g, "style", start)
+	if err := stageErr(ctx, "style"); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// addSeparateRanks places the given resource vertices on one rank, and the
+// rest of the vertices, which represent origins, on another. Vertices, which
+// are already placed on a rank, are skipped.
+func (p *Parser) addSeparateRanks(g *Graph, resourceVertices map[string]bool) {
+	ranked := make(map[string]bool)
+	for _, rank := range g.ranks {
+		for _, v := range rank {
+			ranked[v] = true
+		}
+	}
+
+	resources := make([]string, 0)
+	origins := make([]string, 0)
+	for _, v := range g.GetVertexValues() {
+		if ranked[v] {
+			continue
+		}
+		if resourceVertices[v] {
+			resources = append(resources, v)
+		} else {
+			origins = append(origins, v)
+		}
+	}
+	slices.Sort(resources)
+	slices.Sort(origins)
+
+	g.addRank(resources)
+	g.addRank(origins)
+}
+
+// matchesAny is a predicate, which returns true, if the given value matches
+// any of the patterns.
+func matchesAny(patterns []pattern, value string) bool {
+	for _, pt := range patterns {
+		if pt.matches(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ownerGvks returns the [resid.Gvk] of each owner reference of the given
+// resource.
+func ownerGvks(r *resource.Resource) []resid.Gvk {
+	refs := getMaps(r, "metadata.ownerReferences")
+	result := make([]resid.Gvk, 0, len(refs))
+	for _, ref := range refs {
+		group, version := resid.ParseGroupVersion(stringOrDefault(ref, "apiVersion", ""))
+		kind := stringOrDefault(ref, "kind", "")
+		result = append(result, resid.NewGvk(group, version, kind))
+	}
+
+	return result
+}
+
+// isGenerated is a predicate, which returns true, if the given resource was
+// produced by the ConfigMap or Secret generators. When origin metadata is
+// available, the generator is taken from it. Otherwise, generated resources
+// are recognized by the content hash suffix of their names.
+func isGenerated(r *resource.Resource) bool {
+	if !slices.Contains(generatedKinds, strings.ToLower(r.GetKind())) {
+		return false
+	}
+
+	origin, err := r.GetOrigin()
+	if err == nil && origin != nil {
+		return slices.Contains(generatorKinds, origin.ConfiguredBy.Kind)
+	}
+
+	return nameHashSuffixRegexp.MatchString(r.GetName())
+}
+
+// kindMatches is a predicate, which returns true, if the given [resid.Gvk] is
+// of the given kind, as reported by the match function. The kind may be
+// qualified with the API version, e.g. apps/v1/Deployment or v1/ConfigMap.
+func kindMatches(gvk resid.Gvk, kind string, match func(string, string) bool) bool {
+	i := strings.LastIndex(kind, "/")
+	if i < 0 {
+		return match(kind, gvk.Kind)
+	}
+
+	return match(kind[:i], gvk.ApiVersion()) && match(kind[i+1:], gvk.Kind)
+}
+
+// groupMatches is a predicate, which returns true, if the given [resid.Gvk]
+// is part of the given API group, as reported by the match function. The
+// group may be qualified with a version, e.g. apps/v1, and the core API group
+// is represented by the "core" group.
+func groupMatches(gvk resid.Gvk, group string, match func(string, string) bool) bool {
+	apiGroup := gvk.Group
+	if apiGroup == "" {
+		apiGroup = coreGroup
+	}
+
+	name, version, found := strings.Cut(group, "/")
+	if !found {
+		return match(name, apiGroup)
+	}
+
+	return match(name, apiGroup) && match(version, gvk.Version)
+}
+
+// valueMatches is a predicate, which returns true, if the given value matches
+// the kind, namespace or group filter, taking into account the case
+// sensitivity and exact match settings of the [Parser].
+func (p *Parser) valueMatches(filter string, value string) bool {
+	if !p.caseSensitive {
+		filter = strings.ToLower(filter)
+		value = strings.ToLower(value)
+	}
+
+	if p.exactMatch {
+		return filter == value
+	}
+
+	return wildcardMatches(filter, value)
+}
+
+// shouldDropResource is a predicate, which returns true, if the resource is to
+// be dropped from the graph, and returns false otherwise.
+func (p *Parser) shouldDropResource(r *resource.Resource) bool {
+	return p.dropReason(r) != ""
+}
+
+// dropReason returns the name of the filter, which drops the resource from
+// the graph, e.g. drop-kind, or an empty string, if the resource is kept.
+func (p *Parser) dropReason(r *resource.Resource) string {
+	gvk := r.GetGvk()
+	isNamespace := func(namespace string) bool { return p.valueMatches(namespace, r.GetNamespace()) }
+	isKind := func(kind string) bool { return kindMatches(gvk, kind, p.valueMatches) }
+	isGroup := func(group string) bool { return groupMatches(gvk, group, p.valueMatches) }
+
+	// The first rule matching the resource has the final say, when rules
+	// take precedence over the options
+	if p.filterPrecedence == FilterPrecedenceRules {
+		if drop, ok := rulesDecide(p.rules, r); ok {
+			if drop {
+				return "rule"
+			}
+			return ""
+		}
+	}
+
+	// Drop resource, if it is part of any drop-namespaces
+	for _, dn := range p.dropNamespaces {
+		if negatableMatches(dn, isNamespace) {
+			return "drop-namespace"
+		}
+	}
+
+	// Drop resource, if it is part of any drop-resource-kinds
+	for _, drk := range p.dropResourceKinds {
+		if negatableMatches(drk, isKind) {
+			return "drop-kind"
+		}
+	}
+
+	// Drop resource based on its scope
+	if p.dropClusterScoped && gvk.IsClusterScoped() {
+		return "drop-cluster-scoped"
+	}
+	if p.keepClusterScopedOnly && !gvk.IsClusterScoped() {
+		return "keep-cluster-scoped-only"
+	}
+
+	// Drop resource, if it doesn't originate from the local kustomization,
+	// or from a remote repository
+	if p.localOnly || p.remoteOnly {
+		origin, err := r.GetOrigin()
+		if err != nil || origin == nil {
+			if p.localOnly {
+				return "local-only"
+			}
+			return "remote-only"
+		}
+		if p.localOnly && origin.Repo != "" {
+			return "local-only"
+		}
+		if p.remoteOnly && origin.Repo == "" {
+			return "remote-only"
+		}
+	}
+
+	// Drop resource, if it is part of any drop-groups
+	for _, dg := range p.dropGroups {
+		if negatableMatches(dg, isGroup) {
+			return "drop-group"
+		}
+	}
+
+	// Drop resource, if it is not part of the configured keep-groups
+	if len(p.keepGroups) > 0 {
+		foundKeepGroup := false
+		for _, kg := range p.keepGroups {
+			if negatableMatches(kg, isGroup) {
+				foundKeepGroup = true
+				break
+			}
+		}
+		if !foundKeepGroup {
+			return "keep-group"
+		}
+	}
+
+	// Drop resource, if it is owned by any of the drop-owner-kinds, or is
+	// not owned by any of the keep-owner-kinds
+	if len(p.dropOwnerKinds) > 0 || len(p.keepOwnerKinds) > 0 {
+		owners := ownerGvks(r)
+		isOwnerKind := func(kind string) bool {
+			return slices.ContainsFunc(owners, func(owner resid.Gvk) bool {
+				return kindMatches(owner, kind, p.valueMatches)
+			})
+		}
+		for _, kind := range p.dropOwnerKinds {
+			if negatableMatches(kind, isOwnerKind) {
+				return "drop-owner-kind"
+			}
+		}
+		if len(p.keepOwnerKinds) > 0 {
+			isKept := slices.ContainsFunc(p.keepOwnerKinds, func(kind string) bool {
+				return negatableMatches(kind, isOwnerKind)
+			})
+			if !isKept {
+				return "keep-owner-kind"
+			}
+		}
+	}
+
+	// Drop resource, if it has any of the drop-has-labels, or doesn't have
+	// any of the keep-has-labels
+	if len(p.dropHasLabels) > 0 || len(p.keepHasLabels) > 0 {
+		labels := r.GetLabels()
+		hasLabel := func(label string) bool {
+			_, ok := labels[label]
+			return ok
+		}
+		for _, label := range p.dropHasLabels {
+			if negatableMatches(label, hasLabel) {
+				return "drop-has-label"
+			}
+		}
+		if len(p.keepHasLabels) > 0 {
+			isKept := slices.ContainsFunc(p.keepHasLabels, func(label string) bool {
+				return negatableMatches(label, hasLabel)
+			})
+			if !isKept {
+				return "keep-has-label"
+			}
+		}
+	}
+
+	// Drop resource, if it is not part of the keep-resources
+	if len(p.keepResources) > 0 {
+		vertex := p.vertexNameFromResource(r)
+		isKept := slices.ContainsFunc(p.keepResources, func(v string) bool {
+			return strings.EqualFold(v, vertex)
+		})
+		if !isKept {
+			return "keep-resource"
+		}
+	}
+
+	// Drop resource, if its name matches any of the drop-name-regexps
+	name := r.GetName()
+	if matchesAny(p.dropNameRegexps, name) {
+		return "drop-name-regex"
+	}
+
+	// Drop resource, if its name doesn't match any of the
+	// keep-name-regexps
+	if len(p.keepNameRegexps) > 0 && !matchesAny(p.keepNameRegexps, name) {
+		return "keep-name-regex"
+	}
+
+	// Drop resource, if its origin path matches any of the
+	// drop-origin-paths, or doesn't match any of the keep-origin-paths
+	if len(p.dropOriginPaths) > 0 || len(p.keepOriginPaths) > 0 {
+		originPath := ""
+		if origin, err := r.GetOrigin(); err == nil && origin != nil {
+			originPath = origin.Path
+		}
+		if originPath != "" && matchesAny(p.dropOriginPaths, originPath) {
+			return "drop-origin-path"
+		}
+		if len(p.keepOriginPaths) > 0 && (originPath == "" || !matchesAny(p.keepOriginPaths, originPath)) {
+			return "keep-origin-path"
+		}
+	}
+
+	// Drop resource, if it was produced by a generator
+	if p.dropGenerated && isGenerated(r) {
+		return "drop-generated"
+	}
+
+	// Drop resource, if the filter rules say so
+	if p.filterPrecedence == FilterPrecedenceOptions && rulesDropResource(p.rules, r) {
+		return "rule"
+	}
+
+	// Drop resource, if any of the filter expressions doesn't hold
+	for _, f := range p.filterExprs {
+		if !f.matches(r) {
+			return "filter-expr"
+		}
+	}
+
+	// Drop resources, if they are outside of the configured keep-namespaces
+	keepNamespaceIsSet := false
+	keepKindIsSet := false
+	foundKeepNamespace := false
+	foundKeepKind := false
+	if len(p.keepNamespaces) > 0 && !gvk.IsClusterScoped() {
+		keepNamespaceIsSet = true
+		for _, kn := range p.keepNamespaces {
+			if negatableMatches(kn, isNamespace) {
+				foundKeepNamespace = true
+				break
+			}
+		}
+	}
+
+	// Drop resources, if they are not part of the configured
+	// keep-resource-kinds.
+	if len(p.keepResourceKinds) > 0 {
+		keepKindIsSet = true
+		for _, krk := range p.keepResourceKinds {
+			if negatableMatches(krk, isKind) {
+				foundKeepKind = true
+				break
+			}
+		}
+	}
+
+	switch {
+	case keepNamespaceIsSet && !foundKeepNamespace:
+		// Resource is not part of the keep-namespaces, so drop it.
+		return "keep-namespace"
+	case keepNamespaceIsSet && keepKindIsSet && foundKeepNamespace && !foundKeepKind:
+		// Resource is part of the keep-namespaces, but not part of the
+		// keep-resource-kinds, so drop it.
+		return "keep-kind"
+	case keepKindIsSet && !foundKeepKind:
+		// Resource is not part of the keep-resource-kinds, so drop it
+		return "keep-kind"
+	default:
+		// Don't drop the resource
+		return ""
+	}
+}
+
+// applyHighlights applies the highlight styles to the [graph.Vertex] u for
+// [resource.Resource] r.
+func (p *Parser) applyHighlights(u *graph.Vertex[string], r *resource.Resource) {
+	// Automatic colors are painted first, so that any explicit highlight
+	// overrides them
+	if key := autoColorKey(p.autoColor, r); key != "" {
+		color := paletteColor(key)
+		u.DotAttributes["color"] = color
+		u.DotAttributes["fillcolor"] = color
+	}
+
+	// First we paint resources by namespace
+	namespace := strings.ToLower(r.GetNamespace())
+	kind := strings.ToLower(r.GetKind())
+
+	namespaceColor, ok := p.highlightNamespaceMap[namespace]
+	if ok {
+		u.DotAttributes["color"] = namespaceColor
+		u.DotAttributes["fillcolor"] = namespaceColor
+	}
+
+	// Then we paint resources by kind. Resources highlighted by namespace
+	// as well are optionally filled with a gradient of both colors.
+	kindColor, ok := p.highlightKindMap[kind]
+	if ok {
+		u.DotAttributes["color"] = kindColor
+		u.DotAttributes["fillcolor"] = kindColor
+		if p.gradientHighlights && namespaceColor != "" {
+			u.DotAttributes["fillcolor"] = namespaceColor + ":" + kindColor
+		}
+	}
+
+	// Then we paint resources by name
+	for _, nh := range p.highlightNameRegexps {
+		if nh.re.MatchString(r.GetName()) {
+			u.DotAttributes["color"] = nh.color
+			u.DotAttributes["fillcolor"] = nh.color
+		}
+	}
+
+	// And finally we paint resources using the filter rules
+	applyRuleHighlights(p.rules, u, r)
+
+	// Style rules shape the look of resources, unless overridden by the
+	// Dot attributes passed as is
+	applyStyles(p.styles, u, r)
+	gvk := r.GetGvk()
+	for _, ka := range p.nodeAttributes {
+		if kindMatches(gvk, ka.kind, p.valueMatches) {
+			maps.Copy(u.DotAttributes, ka.attrs)
+		}
+	}
+}
+
+// vertexNameFromResource returns a string representing the vertex name for the
+// given [resource.Resource].
+func (p *Parser) vertexNameFromResource(r *resource.Resource) string {
+	namespace := r.GetNamespace()
+	name := r.GetName()
+	gvk := r.GetGvk()
+	kind := strings.ToLower(r.GetKind())
+
+	// Cluster-scoped resource
+	if gvk.IsClusterScoped() {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+
+	// Namespace-scoped resource
+	return fmt.Sprintf("%s/%s/%s", namespace, kind, name)
 }
 
 // vertexNameFromOrigin returns a string representing the vertex name for the
@@ -399,6 +2272,149 @@ func (p *Parser) vertexNameFromOrigin(origin *resource.Origin) string {
 	}
 }
 
+// helmChartFromResource returns a string representing the vertex name for the
+// Helm chart from which the given [resource.Resource] was inflated by the
+// HelmChartInflationGenerator. The chart name and version are taken from the
+// standard helm.sh/chart label of the resource, since kustomize doesn't record
+// them in the origin.
+func (p *Parser) helmChartFromResource(r *resource.Resource, origin *resource.Origin) (string, bool) {
+	if origin.ConfiguredBy.Kind != helmChartInflationGeneratorKind {
+		return "", false
+	}
+
+	chart := r.GetLabels()[helmChartLabel]
+	if chart == "" {
+		chart = origin.ConfiguredBy.Name
+	}
+	if chart == "" {
+		return "", false
+	}
+
+	return fmt.Sprintf("helm chart %s", chart), true
+}
+
+// addHelmChartVertex adds a vertex representing the Helm chart from which the
+// given [resource.Resource] was inflated, and connects it to the kustomization
+// which configures the HelmChartInflationGenerator. It returns the name of the
+// chart vertex, and false if the resource was not inflated from a Helm chart.
+func (p *Parser) addHelmChartVertex(g *Graph, r *resource.Resource, origin *resource.Origin) (string, bool) {
+	chart, ok := p.helmChartFromResource(r, origin)
+	if !ok {
+		return "", false
+	}
+
+	g.AddVertex(chart).DotAttributes["shape"] = p.originShape
+	if p.groupRemotes && origin.Repo != "" {
+		g.addToCluster(p.sourceNameFromOrigin(origin), chart)
+	}
+
+	e := g.AddEdge(chart, p.vertexNameFromOrigin(origin))
+	e.DotAttributes["label"] = p.edgeLabelFromOrigin(origin)
+	p.styleEdge(e, EdgeTypeOrigin)
+
+	return chart, true
+}
+
+// addPatchEdges connects the vertex u of the given [resource.Resource] to the
+// kustomizations, whose patches modified the resource.
+func (p *Parser) addPatchEdges(g *Graph, uName string, r *resource.Resource) error {
+	if !p.showPatches {
+		return nil
+	}
+
+	transformations, err := r.GetTransformations()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range transformations {
+		if !slices.Contains(patchTransformerKinds, t.ConfiguredBy.Kind) {
+			continue
+		}
+
+		// Resources generated by the same kustomization are already
+		// connected to it.
+		vName := p.vertexNameFromOrigin(t)
+		if g.EdgeExists(uName, vName) {
+			continue
+		}
+
+		v := g.AddVertex(vName)
+		v.DotAttributes["shape"] = p.originShape
+		p.applySourceLink(v, t)
+		if p.groupRemotes && t.Repo != "" {
+			g.addToCluster(p.sourceNameFromOrigin(t), vName)
+		}
+
+		e := g.AddEdge(uName, vName)
+		e.DotAttributes["label"] = p.edgeLabelFromOrigin(t)
+		p.styleEdge(e, EdgeTypePatch)
+	}
+
+	return nil
+}
+
+// sourceNameFromOrigin returns a string representing the vertex name for the
+// source of the given [resource.Origin], which is either the remote repository
+// at the given ref, or the root of the local kustomization.
+func (p *Parser) sourceNameFromOrigin(origin *resource.Origin) string {
+	if origin.Repo != "" {
+		if origin.Ref != "" {
+			return fmt.Sprintf("%s (ref %s)", origin.Repo, origin.Ref)
+		}
+		return origin.Repo
+	}
+
+	return "."
+}
+
+// addOriginVertices adds the vertices and edges representing the given
+// [resource.Origin] to the graph. The file from which a resource originates is
+// connected to the directory containing it, which in turn is connected to the
+// source of the origin.
+func (p *Parser) addOriginVertices(g *Graph, origin *resource.Origin) {
+	file := p.vertexNameFromOrigin(origin)
+	source := p.sourceNameFromOrigin(origin)
+	dir := path.Dir(file)
+	v := g.AddVertex(file)
+	v.DotAttributes["shape"] = p.originShape
+	p.applySourceLink(v, origin)
+	g.AddVertex(source).DotAttributes["shape"] = directoryShape
+	if p.groupRemotes && origin.Repo != "" {
+		g.addToCluster(source, file)
+		g.addToCluster(source, source)
+	}
+
+	// Edge label for resources created by generators and transformers
+	label := ""
+	if origin.ConfiguredIn != "" {
+		label = p.edgeLabelFromOrigin(origin)
+	}
+
+	// File is at the root of the source
+	if dir == "." {
+		e := g.AddEdge(file, source)
+		e.DotAttributes["label"] = label
+		p.styleEdge(e, EdgeTypeOrigin)
+		return
+	}
+
+	// Qualify remote directories with their repository, in order to
+	// avoid clashing with local directories of the same name.
+	if origin.Repo != "" {
+		dir = fmt.Sprintf("%s//%s", origin.Repo, dir)
+	}
+
+	g.AddVertex(dir).DotAttributes["shape"] = directoryShape
+	if p.groupRemotes && origin.Repo != "" {
+		g.addToCluster(source, dir)
+	}
+	e := g.AddEdge(file, dir)
+	e.DotAttributes["label"] = label
+	p.styleEdge(e, EdgeTypeOrigin)
+	p.styleEdge(g.AddEdge(dir, source), EdgeTypeOrigin)
+}
+
 // edgeLabelFromOrigin returns a string to be used as an edge label.
 func (p *Parser) edgeLabelFromOrigin(origin *resource.Origin) string {
 	switch {
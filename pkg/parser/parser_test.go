@@ -26,13 +26,17 @@
 package parser
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"maps"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"gopkg.in/dnaeon/go-graph.v1"
 
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -286,6 +290,47 @@ func TestWithKeepAndWithDropOptions(t *testing.T) {
 		t.Fatal("failed to create Namespace resource")
 	}
 
+	labeled, err := NewResourceFactory().FromMapWithName(
+		"labeled",
+		map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]any{
+				"name":      "labeled",
+				"namespace": "default",
+				"labels": map[string]string{
+					"sidecar.istio.io/inject": "false",
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("failed to create Pod resource")
+	}
+
+	owned, err := NewResourceFactory().FromMapWithName(
+		"owned",
+		map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "ReplicaSet",
+			"metadata": map[string]any{
+				"name":      "owned",
+				"namespace": "default",
+				"ownerReferences": []any{
+					map[string]any{
+						"apiVersion": "apps/v1",
+						"kind":       "Deployment",
+						"name":       "owner",
+						"uid":        "00000000-0000-0000-0000-000000000000",
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("failed to create ReplicaSet resource")
+	}
+
 	type testCase struct {
 		desc       string
 		r          *resource.Resource
@@ -367,6 +412,309 @@ func TestWithKeepAndWithDropOptions(t *testing.T) {
 			// Resource is not a Secret, so it should be dropped
 			opts: []Option{WithKeepNamespace("default"), WithKeepKind("Secret")},
 		},
+		{
+			desc:       "WithDropKind qualified - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropKind("v1/ConfigMap")},
+		},
+		{
+			desc:       "WithDropKind qualified - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropKind("example.com/v1/ConfigMap")},
+		},
+		{
+			desc:       "WithKeepKind qualified - should persist",
+			r:          namespace,
+			shouldDrop: false,
+			opts:       []Option{WithKeepKind("v1/Namespace")},
+		},
+		{
+			desc:       "WithKeepKind qualified and negated - should drop",
+			r:          namespace,
+			shouldDrop: true,
+			opts:       []Option{WithKeepKind("!v1/Namespace")},
+		},
+		{
+			desc:       "WithDropKind negated - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropKind("!Deployment")},
+		},
+		{
+			desc:       "WithDropKind negated - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropKind("!ConfigMap")},
+		},
+		{
+			desc:       "WithKeepKind negated - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepKind("!ConfigMap")},
+		},
+		{
+			desc:       "WithKeepNamespace negated - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepNamespace("!kube-system")},
+		},
+		{
+			desc:       "WithDropNamespace wildcard - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropNamespace("def*")},
+		},
+		{
+			desc:       "WithKeepNamespace wildcard - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepNamespace("kube-*")},
+		},
+		{
+			desc:       "WithKeepKind wildcard - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepKind("*Map")},
+		},
+		{
+			desc:       "WithDropKind wildcard and negated - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropKind("!*Policy")},
+		},
+		{
+			desc:       "WithKeepKind qualified wildcard - should persist",
+			r:          namespace,
+			shouldDrop: false,
+			opts:       []Option{WithKeepKind("v?/Namespace")},
+		},
+		{
+			desc:       "WithKeepKind case-insensitive - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepKind("configmap")},
+		},
+		{
+			desc:       "WithKeepKind case-sensitive - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithCaseSensitive(), WithKeepKind("configmap")},
+		},
+		{
+			desc:       "WithKeepKind case-sensitive - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepKind("ConfigMap"), WithCaseSensitive()},
+		},
+		{
+			desc:       "WithDropNamespace exact match - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithExactMatch(), WithDropNamespace("def*")},
+		},
+		{
+			desc:       "WithDropGroup case-sensitive - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithCaseSensitive(), WithDropGroup("Core")},
+		},
+		{
+			desc:       "WithDropOwnerKind - should drop",
+			r:          owned,
+			shouldDrop: true,
+			opts:       []Option{WithDropOwnerKind("Deployment")},
+		},
+		{
+			desc:       "WithDropOwnerKind qualified - should persist",
+			r:          owned,
+			shouldDrop: false,
+			opts:       []Option{WithDropOwnerKind("extensions/v1beta1/Deployment")},
+		},
+		{
+			desc:       "WithDropOwnerKind without owner - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropOwnerKind("Deployment")},
+		},
+		{
+			desc:       "WithKeepOwnerKind - should persist",
+			r:          owned,
+			shouldDrop: false,
+			opts:       []Option{WithKeepOwnerKind("apps/v1/Deployment")},
+		},
+		{
+			desc:       "WithKeepOwnerKind without owner - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepOwnerKind("Deployment")},
+		},
+		{
+			desc:       "WithKeepOwnerKind negated - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepOwnerKind("!Deployment")},
+		},
+		{
+			desc:       "WithDropGroup negated - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropGroup("!core")},
+		},
+		{
+			desc:       "WithDropNamePrefix negated - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropNamePrefix("!canary-")},
+		},
+		{
+			desc:       "WithDropClusterScoped - should drop",
+			r:          namespace,
+			shouldDrop: true,
+			opts:       []Option{WithDropClusterScoped()},
+		},
+		{
+			desc:       "WithDropClusterScoped - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropClusterScoped()},
+		},
+		{
+			desc:       "WithKeepClusterScopedOnly - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepClusterScopedOnly()},
+		},
+		{
+			desc:       "WithKeepClusterScopedOnly - should persist",
+			r:          namespace,
+			shouldDrop: false,
+			opts:       []Option{WithKeepClusterScopedOnly()},
+		},
+		{
+			desc:       "WithDropGroup - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropGroup("core")},
+		},
+		{
+			desc:       "WithDropGroup - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropGroup("rbac.authorization.k8s.io")},
+		},
+		{
+			desc:       "WithKeepGroup - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepGroup("monitoring.coreos.com")},
+		},
+		{
+			desc:       "WithKeepGroup with version - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepGroup("core/v1")},
+		},
+		{
+			desc:       "WithKeepGroup with version - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepGroup("core/v2")},
+		},
+		{
+			desc:       "WithDropHasLabel - should drop",
+			r:          labeled,
+			shouldDrop: true,
+			opts:       []Option{WithDropHasLabel("sidecar.istio.io/inject")},
+		},
+		{
+			desc:       "WithDropHasLabel - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropHasLabel("sidecar.istio.io/inject")},
+		},
+		{
+			desc:       "WithKeepHasLabel - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepHasLabel("sidecar.istio.io/inject")},
+		},
+		{
+			desc:       "WithKeepHasLabel negated - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepHasLabel("!sidecar.istio.io/inject")},
+		},
+		{
+			desc:       "WithKeepResource - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepResource("default/ConfigMap/kustomize-dot")},
+		},
+		{
+			desc:       "WithKeepResource - should drop",
+			r:          namespace,
+			shouldDrop: true,
+			opts:       []Option{WithKeepResource("default/configmap/kustomize-dot")},
+		},
+		{
+			desc:       "WithKeepResource cluster-scoped - should persist",
+			r:          namespace,
+			shouldDrop: false,
+			opts:       []Option{WithKeepResource("namespace/default")},
+		},
+		{
+			desc:       "WithDropNameRegexp - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropNameRegexp(regexp.MustCompile(`-dot$`))},
+		},
+		{
+			desc:       "WithDropNameRegexp - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropNameRegexp(regexp.MustCompile(`.*-canary`))},
+		},
+		{
+			desc:       "WithKeepNameRegexp - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepNameRegexp(regexp.MustCompile(`^canary-`))},
+		},
+		{
+			desc:       "WithKeepNameRegexp - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts: []Option{
+				WithKeepNameRegexp(regexp.MustCompile(`^canary-`)),
+				WithKeepNameRegexp(regexp.MustCompile(`^kustomize-`)),
+			},
+		},
+		{
+			desc:       "WithDropNamePrefix - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithDropNamePrefix("kustomize-")},
+		},
+		{
+			desc:       "WithDropNameSuffix - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithDropNameSuffix(".dot")}, // Suffix is not a regular expression
+		},
+		{
+			desc:       "WithKeepNamePrefix - should drop",
+			r:          configMap,
+			shouldDrop: true,
+			opts:       []Option{WithKeepNamePrefix("dot")},
+		},
+		{
+			desc:       "WithKeepNamePrefix and WithKeepNameSuffix - should persist",
+			r:          configMap,
+			shouldDrop: false,
+			opts:       []Option{WithKeepNamePrefix("canary-"), WithKeepNameSuffix("-dot")},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -438,6 +786,128 @@ func TestWithHighlightOptions(t *testing.T) {
 	}
 }
 
+func TestWithHighlightNameRegexp(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithHighlightKind("Service", "yellow"),
+		WithHighlightNameRegexp(regexp.MustCompile(`^the-(map|service)$`), "red"),
+		WithHighlightNameRegexp(regexp.MustCompile(`map`), "green"),
+	)
+	g, err := p.Parse(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantColors := map[string]string{
+		"default/configmap/the-map":         "green",
+		"default/service/the-service":       "red",
+		"default/deployment/the-deployment": "",
+	}
+	for vertex, wantColor := range wantColors {
+		if got := g.GetVertex(vertex).DotAttributes["fillcolor"]; got != wantColor {
+			t.Fatalf("want %s fillcolor %q, got %q", vertex, wantColor, got)
+		}
+	}
+}
+
+func TestWithGradientHighlights(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		wantColor string
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "kind overrides namespace",
+			opts:      []Option{},
+			wantColor: "yellow",
+		},
+		{
+			desc:      "gradient of namespace and kind",
+			opts:      []Option{WithGradientHighlights()},
+			wantColor: "pink:yellow",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts := append(tc.opts, WithHighlightNamespace("default", "pink"), WithHighlightKind("Service", "yellow"))
+			g, err := New(opts...).Parse(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			if got := g.GetVertex("default/service/the-service").DotAttributes["fillcolor"]; got != tc.wantColor {
+				t.Fatalf("want fillcolor %q, got %q", tc.wantColor, got)
+			}
+			if got := g.GetVertex("default/configmap/the-map").DotAttributes["fillcolor"]; got != "pink" {
+				t.Fatalf("want namespace fillcolor %q, got %q", "pink", got)
+			}
+		})
+	}
+}
+
+func TestWithNodeAttributes(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithHighlightKind("Deployment", "yellow"),
+		WithNodeAttributes("Deployment", graph.DotAttributes{"shape": "component", "style": "filled"}),
+		WithNodeAttributes("apps/v1/*", graph.DotAttributes{"peripheries": "2"}),
+		WithNodeAttributes("Service", graph.DotAttributes{"shape": "oval"}),
+	)
+	g, err := p.Parse(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	type testCase struct {
+		vertex    string
+		wantAttrs graph.DotAttributes
+	}
+
+	testCases := []testCase{
+		{
+			vertex: "default/deployment/the-deployment",
+			wantAttrs: graph.DotAttributes{
+				"color":       "yellow",
+				"fillcolor":   "yellow",
+				"peripheries": "2",
+				"shape":       "component",
+				"style":       "filled",
+			},
+		},
+		{
+			vertex:    "default/service/the-service",
+			wantAttrs: graph.DotAttributes{"shape": "oval"},
+		},
+		{
+			vertex:    "default/configmap/the-map",
+			wantAttrs: graph.DotAttributes{"shape": DefaultResourceShape},
+		},
+	}
+
+	for _, tc := range testCases {
+		got := g.GetVertex(tc.vertex).DotAttributes
+		if !maps.Equal(got, tc.wantAttrs) {
+			t.Fatalf("want %s attributes %v, got %v", tc.vertex, tc.wantAttrs, got)
+		}
+	}
+}
+
 func TestWithLayoutDirection(t *testing.T) {
 	type testCase struct {
 		desc                string
@@ -474,6 +944,252 @@ func TestWithLayoutDirection(t *testing.T) {
 	}
 }
 
+func TestWithGraphAttribute(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithTheme(ThemeDark),
+		WithGraphAttribute("splines", "ortho"),
+		WithGraphAttribute("nodesep", "0.5"),
+		WithGraphAttribute("bgcolor", "black"),
+		WithGraphAttribute("nodesep", "0.8"),
+	)
+	g, err := p.Parse(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantAttrs := map[string]string{
+		"splines": "ortho",
+		"nodesep": "0.8",
+		"bgcolor": "black",
+		"rankdir": "LR",
+	}
+	graphAttrs := g.GetDotAttributes()
+	for name, want := range wantAttrs {
+		if got := graphAttrs[name]; got != want {
+			t.Fatalf("want graph attribute %s=%q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(WithDropKind("ConfigMap"))
+	kept, err := p.Filter(resources)
+	if err != nil {
+		t.Fatalf("failed to filter resources: %s", err)
+	}
+
+	got := make([]string, 0, len(kept))
+	for _, r := range kept {
+		got = append(got, r.GetName())
+	}
+	want := []string{"the-service", "the-deployment"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want kept resources %v, got %v", want, got)
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := New().ParseContext(ctx, resources); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestParseReturnsGraph(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(WithGroupRemotes())
+	g, err := p.Parse(resources)
+	if err != nil {
+		t.Fatalf("parsing graph failed: %s", err)
+	}
+	want, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("parsing graph failed: %s", err)
+	}
+
+	got, ok := g.(*Graph)
+	if !ok {
+		t.Fatalf("want graph of type *Graph, got %T", g)
+	}
+	if len(got.GetVertices()) != len(want.GetVertices()) || len(got.GetEdges()) != len(want.GetEdges()) {
+		t.Fatalf("want the same graph from Parse and ParseGraph")
+	}
+	if len(got.clusters) == 0 {
+		t.Fatalf("want clusters of remote origins in the graph")
+	}
+}
+
+func TestAddOriginVertices(t *testing.T) {
+	type testCase struct {
+		desc      string
+		origin    *resource.Origin
+		wantEdges [][2]string
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "local resource at the root",
+			origin: &resource.Origin{Path: "deployment.yaml"},
+			wantEdges: [][2]string{
+				{"deployment.yaml", "."},
+			},
+		},
+		{
+			desc:   "local resource from a base",
+			origin: &resource.Origin{Path: "../base/deployment.yaml"},
+			wantEdges: [][2]string{
+				{"../base/deployment.yaml", "../base"},
+				{"../base", "."},
+			},
+		},
+		{
+			desc: "remote resource",
+			origin: &resource.Origin{
+				Repo: "github.com/dnaeon/kustomize-dot",
+				Ref:  "v1",
+				Path: "examples/foo.yaml",
+			},
+			wantEdges: [][2]string{
+				{"examples/foo.yaml", "github.com/dnaeon/kustomize-dot//examples"},
+				{"github.com/dnaeon/kustomize-dot//examples", "github.com/dnaeon/kustomize-dot (ref v1)"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(WithGraphMode(GraphModeOrigins))
+			g := newGraph()
+			p.addOriginVertices(g, tc.origin)
+
+			gotEs := g.GetEdges()
+			if len(gotEs) != len(tc.wantEdges) {
+				t.Fatalf("want |E|=%d, got |E|=%d", len(tc.wantEdges), len(gotEs))
+			}
+			for _, edge := range tc.wantEdges {
+				if !g.EdgeExists(edge[0], edge[1]) {
+					t.Fatalf("missing edge %s -> %s", edge[0], edge[1])
+				}
+			}
+		})
+	}
+}
+
+func TestWithDropGenerated(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: generated-with-origin
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: generated-without-origin-9m2h7k48bt
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hand-written
+  annotations:
+    config.kubernetes.io/origin: |
+      path: configmap.yaml
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: not-generated-9m2h7k48bt
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	wantDrop := []bool{true, true, false, false}
+	p := New(WithDropGenerated())
+	for i, r := range resources {
+		if got := p.shouldDropResource(r); got != wantDrop[i] {
+			t.Fatalf("shouldDrop() for %s returned %t, expected %t", r.GetName(), got, wantDrop[i])
+		}
+	}
+}
+
+func TestVertexShapes(t *testing.T) {
+	type testCase struct {
+		desc              string
+		opts              []Option
+		wantResourceShape string
+		wantOriginShape   string
+	}
+
+	testCases := []testCase{
+		{
+			desc:              "default shapes",
+			opts:              []Option{},
+			wantResourceShape: DefaultResourceShape,
+			wantOriginShape:   DefaultOriginShape,
+		},
+		{
+			desc:              "custom shapes",
+			opts:              []Option{WithResourceShape("ellipse"), WithOriginShape("folder")},
+			wantResourceShape: "ellipse",
+			wantOriginShape:   "folder",
+		},
+	}
+
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.Parse(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			u := g.GetVertex("default/configmap/the-map")
+			if u.DotAttributes["shape"] != tc.wantResourceShape {
+				t.Fatalf("want resource shape %q, got %q", tc.wantResourceShape, u.DotAttributes["shape"])
+			}
+
+			v := g.GetVertex("examples/helloWorld/configMap.yaml")
+			if v.DotAttributes["shape"] != tc.wantOriginShape {
+				t.Fatalf("want origin shape %q, got %q", tc.wantOriginShape, v.DotAttributes["shape"])
+			}
+		})
+	}
+}
+
 func TestParse(t *testing.T) {
 	type testCase struct {
 		desc          string
@@ -525,6 +1241,62 @@ func TestParse(t *testing.T) {
 			wantEs:        0,
 			opts:          []Option{WithKeepNamespace("foobar")}, // Resources are from default namespace
 		},
+		{
+			desc:          "hello world resources - WithGraphMode origins",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        5, // 3 files + 1 directory + 1 repo
+			wantEs:        4,
+			opts:          []Option{WithGraphMode(GraphModeOrigins)},
+		},
+		{
+			desc:          "hello world resources - WithGraphMode resources",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        3,
+			wantEs:        0,
+			opts:          []Option{WithGraphMode(GraphModeResources)},
+		},
+		{
+			desc:          "hello world resources - WithKeepOriginPath",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        2, // 1 resource + 1 origin
+			wantEs:        1,
+			opts:          []Option{WithKeepOriginPath("examples/**/service.yaml")},
+		},
+		{
+			desc:          "hello world resources - WithDropOriginPath",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        0,
+			wantEs:        0,
+			opts:          []Option{WithDropOriginPath("examples/helloWorld/*")},
+		},
+		{
+			desc:          "hello world resources - WithDropOriginPath negated",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        2, // 1 resource + 1 origin
+			wantEs:        1,
+			opts:          []Option{WithDropOriginPath("!**/service.yaml")},
+		},
+		{
+			desc:          "hello world resources - WithLocalOnly",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        0, // All resources come from a remote repository
+			wantEs:        0,
+			opts:          []Option{WithLocalOnly()},
+		},
+		{
+			desc:          "hello world resources - WithRemoteOnly",
+			data:          fixtures.HelloWorld,
+			wantResources: 3,
+			wantVs:        6, // 3 resources + 3 origins
+			wantEs:        3,
+			opts:          []Option{WithRemoteOnly()},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -557,3 +1329,118 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestHelmChartOrigins(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: minecraft
+  namespace: default
+  labels:
+    helm.sh/chart: minecraft-3.1.3
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: overlays/prod/kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: HelmChartInflationGenerator
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: overlays/prod/kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.Parse(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantEdges := [][3]string{
+		{"default/service/minecraft", "helm chart minecraft-3.1.3", ""},
+		{"helm chart minecraft-3.1.3", "overlays/prod/kustomization.yaml", "builtin/HelmChartInflationGenerator"},
+		{"default/configmap/settings", "overlays/prod/kustomization.yaml", "builtin/ConfigMapGenerator"},
+	}
+	for _, edge := range wantEdges {
+		e := g.GetEdge(edge[0], edge[1])
+		if e == nil {
+			t.Fatalf("missing edge %s -> %s", edge[0], edge[1])
+		}
+		if e.DotAttributes["label"] != edge[2] {
+			t.Fatalf("want edge label %q, got %q", edge[2], e.DotAttributes["label"])
+		}
+	}
+}
+
+func TestWithShowPatches(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/deployment.yaml
+    alpha.config.kubernetes.io/transformations: |
+      - configuredIn: overlays/prod/kustomization.yaml
+        configuredBy:
+          apiVersion: builtin
+          kind: PatchTransformer
+      - configuredIn: overlays/prod/kustomization.yaml
+        configuredBy:
+          apiVersion: builtin
+          kind: LabelTransformer
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc   string
+		opts   []Option
+		wantEs int
+	}
+
+	testCases := []testCase{
+		{
+			desc:   "patches are not shown by default",
+			opts:   []Option{},
+			wantEs: 1,
+		},
+		{
+			desc:   "WithShowPatches",
+			opts:   []Option{WithShowPatches()},
+			wantEs: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(tc.opts...)
+			g, err := p.Parse(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			gotEs := g.GetEdges()
+			if len(gotEs) != tc.wantEs {
+				t.Fatalf("want |E|=%d, got |E|=%d", tc.wantEs, len(gotEs))
+			}
+		})
+	}
+}
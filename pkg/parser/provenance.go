@@ -0,0 +1,174 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ErrResourceNotFound is returned when a resource referenced by name cannot be
+// found.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// The annotations, which contain the normalized provenance of a resource. See
+// [Provenance.Annotations] for more details.
+const (
+	// AnnotationOriginRepo is the repository of remote resources
+	AnnotationOriginRepo = "kustomize-dot.dnaeon.github.io/origin-repo"
+
+	// AnnotationOriginRef is the ref of the repository of remote resources
+	AnnotationOriginRef = "kustomize-dot.dnaeon.github.io/origin-ref"
+
+	// AnnotationOriginPath is the path of the file the resource came from,
+	// or the generator config of generated resources.
+	AnnotationOriginPath = "kustomize-dot.dnaeon.github.io/origin-path"
+
+	// AnnotationGenerator is the kind and name of the generator, which
+	// created the resource.
+	AnnotationGenerator = "kustomize-dot.dnaeon.github.io/generator"
+)
+
+// Provenance describes where a [resource.Resource] came from.
+type Provenance struct {
+	// Resource is the name of the resource, e.g. default/deployment/web
+	Resource string `json:"resource"`
+
+	// Origin is the origin of the resource, if known
+	Origin *resource.Origin `json:"origin,omitempty"`
+
+	// Transformations contains the origins of the transformers, which
+	// modified the resource.
+	Transformations []*resource.Origin `json:"transformations,omitempty"`
+}
+
+// newProvenance returns the [Provenance] of the given resource.
+func (p *Parser) newProvenance(r *resource.Resource) (*Provenance, error) {
+	origin, err := r.GetOrigin()
+	if err != nil {
+		return nil, err
+	}
+
+	transformations, err := r.GetTransformations()
+	if err != nil {
+		return nil, err
+	}
+
+	pv := &Provenance{
+		Resource:        p.vertexNameFromResource(r),
+		Origin:          origin,
+		Transformations: transformations,
+	}
+
+	return pv, nil
+}
+
+// Annotations returns the normalized provenance as annotations, which do not
+// depend on the build options of kustomize in the way the origin annotation
+// does. Only the known fields of the provenance are returned, e.g. the
+// [AnnotationGenerator] is returned for generated resources only.
+func (pv *Provenance) Annotations() map[string]string {
+	annotations := make(map[string]string)
+	if pv.Origin == nil {
+		return annotations
+	}
+
+	values := map[string]string{
+		AnnotationOriginRepo: pv.Origin.Repo,
+		AnnotationOriginRef:  pv.Origin.Ref,
+		AnnotationOriginPath: cmp.Or(pv.Origin.Path, pv.Origin.ConfiguredIn),
+	}
+	if pv.Origin.ConfiguredBy.Kind != "" {
+		values[AnnotationGenerator] = path.Join(pv.Origin.ConfiguredBy.Kind, pv.Origin.ConfiguredBy.Name)
+	}
+	for k, v := range values {
+		if v != "" {
+			annotations[k] = v
+		}
+	}
+
+	return annotations
+}
+
+// ProvenanceOfResources returns the [Provenance] of the given resources, in
+// their original order.
+func (p *Parser) ProvenanceOfResources(resources []*resource.Resource) ([]*Provenance, error) {
+	result := make([]*Provenance, 0, len(resources))
+	for _, r := range resources {
+		pv, err := p.newProvenance(r)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pv)
+	}
+
+	return result, nil
+}
+
+// ResourcesFromOrigin returns the [Provenance] of the resources, which came
+// from an origin matching the given glob pattern, e.g. base/monitoring/**. The
+// pattern is matched against the origin path, the repository of remote
+// resources, and the generator config of generated resources.
+func (p *Parser) ResourcesFromOrigin(resources []*resource.Resource, glob string) ([]*Provenance, error) {
+	patterns := []pattern{newPattern(glob, globToRegexp)}
+	result := make([]*Provenance, 0)
+	for _, r := range resources {
+		pv, err := p.newProvenance(r)
+		if err != nil {
+			return nil, err
+		}
+		if pv.Origin == nil {
+			continue
+		}
+
+		values := []string{pv.Origin.Path, pv.Origin.Repo, pv.Origin.ConfiguredIn}
+		for _, v := range values {
+			if v != "" && matchesAny(patterns, v) {
+				result = append(result, pv)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ProvenanceOf returns the [Provenance] of the resource with the given name,
+// e.g. default/deployment/web.
+func (p *Parser) ProvenanceOf(resources []*resource.Resource, name string) (*Provenance, error) {
+	for _, r := range resources {
+		if strings.EqualFold(p.vertexNameFromResource(r), name) {
+			return p.newProvenance(r)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, name)
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestResourcesFromOrigin(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/monitoring/deployment.yaml
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/web/service.yaml
+      repo: https://github.com/example/manifests
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: base/monitoring/kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: default
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		glob string
+		want []string
+	}
+
+	testCases := []testCase{
+		{
+			glob: "base/monitoring/**",
+			want: []string{"default/deployment/web", "default/configmap/web-config"},
+		},
+		{
+			glob: "https://github.com/example/manifests",
+			want: []string{"default/service/web"},
+		},
+		{
+			glob: "overlays/**",
+			want: []string{},
+		},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		result, err := p.ResourcesFromOrigin(resources, tc.glob)
+		if err != nil {
+			t.Fatalf("failed to query resources from %s: %s", tc.glob, err)
+		}
+		got := make([]string, 0, len(result))
+		for _, pv := range result {
+			got = append(got, pv.Resource)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("want resources %v from %s, got %v", tc.want, tc.glob, got)
+		}
+	}
+}
+
+func TestProvenanceOf(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/deployment.yaml
+    alpha.config.kubernetes.io/transformations: |
+      - configuredIn: overlays/prod/kustomization.yaml
+        configuredBy:
+          apiVersion: builtin
+          kind: PatchTransformer
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	pv, err := p.ProvenanceOf(resources, "default/Deployment/web")
+	if err != nil {
+		t.Fatalf("failed to get provenance: %s", err)
+	}
+	if pv.Origin == nil || pv.Origin.Path != "base/deployment.yaml" {
+		t.Fatalf("want origin base/deployment.yaml, got %v", pv.Origin)
+	}
+	if len(pv.Transformations) != 1 || pv.Transformations[0].ConfiguredIn != "overlays/prod/kustomization.yaml" {
+		t.Fatalf("want transformation from overlays/prod/kustomization.yaml, got %v", pv.Transformations)
+	}
+
+	if _, err := p.ProvenanceOf(resources, "default/deployment/missing"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("want ErrResourceNotFound, got %v", err)
+	}
+}
+
+func TestProvenanceAnnotations(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/web/service.yaml
+      repo: https://github.com/example/manifests
+      ref: v1.0.0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: default
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: base/monitoring/kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+        name: web-config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: default
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	provenances, err := p.ProvenanceOfResources(resources)
+	if err != nil {
+		t.Fatalf("failed to get provenance: %s", err)
+	}
+
+	want := []map[string]string{
+		{
+			AnnotationOriginRepo: "https://github.com/example/manifests",
+			AnnotationOriginRef:  "v1.0.0",
+			AnnotationOriginPath: "base/web/service.yaml",
+		},
+		{
+			AnnotationOriginPath: "base/monitoring/kustomization.yaml",
+			AnnotationGenerator:  "ConfigMapGenerator/web-config",
+		},
+		{},
+	}
+	if len(provenances) != len(want) {
+		t.Fatalf("want %d provenances, got %d", len(want), len(provenances))
+	}
+	for i, pv := range provenances {
+		if got := pv.Annotations(); !maps.Equal(got, want[i]) {
+			t.Fatalf("want annotations %v of %s, got %v", want[i], pv.Resource, got)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ErrInvalidQuery is returned when a query cannot be parsed or compiled.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrQueryFailed is returned when a query fails to run against the
+// resources.
+var ErrQueryFailed = errors.New("query failed")
+
+// Query is a compiled jq expression, which selects resources before the graph
+// is constructed. The expression is given the array of resources as input,
+// and the resources it outputs are kept, e.g. .[] | select(.kind == "Service").
+type Query struct {
+	// expr is the source of the query
+	expr string
+
+	// code is the compiled query
+	code *gojq.Code
+}
+
+// NewQuery parses and compiles the given jq expression into a [Query].
+func NewQuery(expr string) (*Query, error) {
+	parsed, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidQuery, expr, err)
+	}
+
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidQuery, expr, err)
+	}
+
+	q := &Query{
+		expr: expr,
+		code: code,
+	}
+
+	return q, nil
+}
+
+// String returns the source of the query.
+func (q *Query) String() string {
+	return q.expr
+}
+
+// selectResources runs the query against the given resources, and returns the
+// resources, which were part of the query output. Output values, which are
+// arrays, are flattened, and anything other than resources is ignored. The
+// order of the resources is preserved.
+func (q *Query) selectResources(resources []*resource.Resource) ([]*resource.Resource, error) {
+	items := make([]any, 0, len(resources))
+	for _, r := range resources {
+		m, err := r.Map()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, m)
+	}
+
+	selected := make(map[string]bool)
+	iter := q.code.Run(items)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return nil, fmt.Errorf("%w: %s: %w", ErrQueryFailed, q.expr, err)
+		}
+
+		values := []any{v}
+		if arr, ok := v.([]any); ok {
+			values = arr
+		}
+		for _, val := range values {
+			if m, ok := val.(map[string]any); ok {
+				selected[queryKeyFromMap(m)] = true
+			}
+		}
+	}
+
+	result := make([]*resource.Resource, 0)
+	for _, r := range resources {
+		if selected[queryKeyFromResource(r)] {
+			result = append(result, r)
+		}
+	}
+
+	return result, nil
+}
+
+// queryKeyFromResource returns the key, which identifies the given
+// [resource.Resource] in the query output.
+func queryKeyFromResource(r *resource.Resource) string {
+	return fmt.Sprintf("%s|%s|%s|%s", r.GetApiVersion(), r.GetKind(), r.GetNamespace(), r.GetName())
+}
+
+// queryKeyFromMap returns the key, which identifies the resource represented
+// by the given map in the query output.
+func queryKeyFromMap(m map[string]any) string {
+	apiVersion, _ := m["apiVersion"].(string)
+	kind, _ := m["kind"].(string)
+	metadata, _ := m["metadata"].(map[string]any)
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+
+	return fmt.Sprintf("%s|%s|%s|%s", apiVersion, kind, namespace, name)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestQuery(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		expr      string
+		wantNames []string
+	}
+
+	testCases := []testCase{
+		{
+			desc:      "select by kind",
+			expr:      `.[] | select(.kind == "Service")`,
+			wantNames: []string{"the-service"},
+		},
+		{
+			desc:      "select array",
+			expr:      `map(select(.kind != "Service"))`,
+			wantNames: []string{"the-map", "the-deployment"},
+		},
+		{
+			desc:      "non-resource output",
+			expr:      `.[] | .metadata.name`,
+			wantNames: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			q, err := NewQuery(tc.expr)
+			if err != nil {
+				t.Fatalf("failed to compile query: %s", err)
+			}
+
+			selected, err := q.selectResources(resources)
+			if err != nil {
+				t.Fatalf("failed to run query: %s", err)
+			}
+
+			if len(selected) != len(tc.wantNames) {
+				t.Fatalf("want %d resource(s), got %d", len(tc.wantNames), len(selected))
+			}
+			for i, r := range selected {
+				if r.GetName() != tc.wantNames[i] {
+					t.Fatalf("want resource %q, got %q", tc.wantNames[i], r.GetName())
+				}
+			}
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	if _, err := NewQuery(`.[] | select(`); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("want ErrInvalidQuery, got %v", err)
+	}
+
+	q, err := NewQuery(`error("boom")`)
+	if err != nil {
+		t.Fatalf("failed to compile query: %s", err)
+	}
+
+	p := New(WithQuery(q))
+	if _, err := p.ParseGraph(nil); !errors.Is(err, ErrQueryFailed) {
+		t.Fatalf("want ErrQueryFailed, got %v", err)
+	}
+}
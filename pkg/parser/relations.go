@@ -0,0 +1,638 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// relation represents a relationship between a resource and another
+// resource from the build.
+type relation struct {
+	// to is the resource, which is the target of the relationship
+	to *resource.Resource
+
+	// label is the label of the edge representing the relationship
+	label string
+}
+
+// relationDetector knows how to discover relationships between resources of
+// a given kind and the rest of the resources in the build.
+type relationDetector struct {
+	// kinds contains the lower-cased resource kinds the detector applies
+	// to.
+	kinds []string
+
+	// detect returns the relationships for the given resource.
+	detect func(r *resource.Resource, idx *resourceIndex) []relation
+
+	// markOrphans specifies whether resources for which no relationships
+	// were discovered are orphans.
+	markOrphans bool
+}
+
+// relationDetectors contains the known relationship detectors.
+var relationDetectors = []relationDetector{
+	{
+		kinds:       []string{"poddisruptionbudget"},
+		detect:      detectPodDisruptionBudgetTargets,
+		markOrphans: true,
+	},
+	{
+		kinds:       []string{"servicemonitor"},
+		detect:      detectServiceMonitorTargets,
+		markOrphans: true,
+	},
+	{
+		kinds:       []string{"podmonitor"},
+		detect:      detectPodMonitorTargets,
+		markOrphans: true,
+	},
+	{
+		kinds:  []string{"httproute", "grpcroute"},
+		detect: detectRouteReferences,
+	},
+	{
+		kinds:  []string{"validatingwebhookconfiguration", "mutatingwebhookconfiguration"},
+		detect: detectWebhookServices,
+	},
+	{
+		kinds:  []string{"certificate"},
+		detect: detectCertificateReferences,
+	},
+	{
+		kinds:  workloadKinds(),
+		detect: detectWorkloadServiceAccount,
+	},
+	{
+		kinds:  workloadKinds(),
+		detect: detectWorkloadClasses,
+	},
+	{
+		kinds:  []string{"statefulset"},
+		detect: detectStatefulSetService,
+	},
+}
+
+// workloadPodTemplatePaths contains the mapping between workload kinds and the
+// path to the pod template managed by the workload. An empty path denotes that
+// the workload is a pod itself.
+var workloadPodTemplatePaths = map[string]string{
+	"pod":                   "",
+	"deployment":            "spec.template",
+	"statefulset":           "spec.template",
+	"daemonset":             "spec.template",
+	"replicaset":            "spec.template",
+	"replicationcontroller": "spec.template",
+	"job":                   "spec.template",
+	"cronjob":               "spec.jobTemplate.spec.template",
+}
+
+// workloadKinds returns the lower-cased kinds of the known workloads.
+func workloadKinds() []string {
+	kinds := make([]string, 0, len(workloadPodTemplatePaths))
+	for kind := range workloadPodTemplatePaths {
+		kinds = append(kinds, kind)
+	}
+
+	return kinds
+}
+
+// resourceIndex provides lookups of resources, which are part of the graph.
+type resourceIndex struct {
+	resources []*resource.Resource
+}
+
+// newResourceIndex creates a new [resourceIndex] for the given resources.
+func newResourceIndex(resources []*resource.Resource) *resourceIndex {
+	idx := &resourceIndex{
+		resources: resources,
+	}
+
+	return idx
+}
+
+// workloads returns the workloads from any of the given namespaces. If no
+// namespaces are specified, then workloads from all namespaces are returned.
+func (idx *resourceIndex) workloads(namespaces ...string) []*resource.Resource {
+	result := make([]*resource.Resource, 0)
+	for _, r := range idx.resources {
+		_, ok := workloadPodTemplatePaths[strings.ToLower(r.GetKind())]
+		if !ok {
+			continue
+		}
+		if len(namespaces) > 0 && !slices.Contains(namespaces, r.GetNamespace()) {
+			continue
+		}
+		result = append(result, r)
+	}
+
+	return result
+}
+
+// find returns the resource with the given kind, namespace and name, or nil if
+// no such resource exists.
+func (idx *resourceIndex) find(kind, namespace, name string) *resource.Resource {
+	for _, r := range idx.resources {
+		if strings.EqualFold(r.GetKind(), kind) && r.GetNamespace() == namespace && r.GetName() == name {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// ofKind returns the resources of the given kind, which are part of any of
+// the given namespaces. If no namespaces are specified, then resources from
+// all namespaces are returned.
+func (idx *resourceIndex) ofKind(kind string, namespaces ...string) []*resource.Resource {
+	result := make([]*resource.Resource, 0)
+	for _, r := range idx.resources {
+		if !strings.EqualFold(r.GetKind(), kind) {
+			continue
+		}
+		if len(namespaces) > 0 && !slices.Contains(namespaces, r.GetNamespace()) {
+			continue
+		}
+		result = append(result, r)
+	}
+
+	return result
+}
+
+// addRelationEdges discovers the relationships between the given resources
+// and connects the respective vertices with edges.
+func (p *Parser) addRelationEdges(g graph.Graph[string], resources []*resource.Resource) {
+	idx := newResourceIndex(resources)
+	for _, r := range resources {
+		kind := strings.ToLower(r.GetKind())
+		for _, detector := range relationDetectors {
+			if !slices.Contains(detector.kinds, kind) {
+				continue
+			}
+
+			uName := p.vertexNameFromResource(r)
+			for _, rel := range detector.detect(r, idx) {
+				vName := p.vertexNameFromResource(rel.to)
+				e := g.AddEdge(uName, vName)
+				e.DotAttributes["label"] = rel.label
+				p.styleEdge(e, EdgeTypeRelationship)
+			}
+		}
+	}
+}
+
+// findOrphans returns the resources, for which a detector marking orphans
+// discovered no relationships with the rest of the given resources.
+func findOrphans(resources []*resource.Resource) map[*resource.Resource]bool {
+	idx := newResourceIndex(resources)
+	orphans := make(map[*resource.Resource]bool)
+	for _, r := range resources {
+		kind := strings.ToLower(r.GetKind())
+		for _, detector := range relationDetectors {
+			if !detector.markOrphans || !slices.Contains(detector.kinds, kind) {
+				continue
+			}
+			if len(detector.detect(r, idx)) == 0 {
+				orphans[r] = true
+			}
+		}
+	}
+
+	return orphans
+}
+
+// detectPodDisruptionBudgetTargets returns the workloads, which are selected
+// by the given PodDisruptionBudget.
+func detectPodDisruptionBudgetTargets(r *resource.Resource, idx *resourceIndex) []relation {
+	selector, ok := getMap(r, "spec.selector")
+	if !ok {
+		// A PodDisruptionBudget with a nil selector matches no pods
+		return nil
+	}
+
+	relations := make([]relation, 0)
+	for _, w := range idx.workloads(r.GetNamespace()) {
+		if selectorMatches(selector, podLabels(w)) {
+			relations = append(relations, relation{to: w, label: "protects"})
+		}
+	}
+
+	return relations
+}
+
+// detectServiceMonitorTargets returns the services, which are scraped by the
+// given Prometheus Operator ServiceMonitor.
+func detectServiceMonitorTargets(r *resource.Resource, idx *resourceIndex) []relation {
+	selector, ok := getMap(r, "spec.selector")
+	if !ok {
+		return nil
+	}
+
+	relations := make([]relation, 0)
+	for _, svc := range idx.ofKind("Service", monitorNamespaces(r)...) {
+		if selectorMatches(selector, svc.GetLabels()) {
+			relations = append(relations, relation{to: svc, label: "scrapes"})
+		}
+	}
+
+	return relations
+}
+
+// detectPodMonitorTargets returns the workloads, which are scraped by the
+// given Prometheus Operator PodMonitor.
+func detectPodMonitorTargets(r *resource.Resource, idx *resourceIndex) []relation {
+	selector, ok := getMap(r, "spec.selector")
+	if !ok {
+		return nil
+	}
+
+	relations := make([]relation, 0)
+	for _, w := range idx.workloads(monitorNamespaces(r)...) {
+		if selectorMatches(selector, podLabels(w)) {
+			relations = append(relations, relation{to: w, label: "scrapes"})
+		}
+	}
+
+	return relations
+}
+
+// monitorNamespaces returns the namespaces in which the given ServiceMonitor
+// or PodMonitor looks for targets, based on its namespaceSelector. An empty
+// result means that targets are selected from all namespaces.
+func monitorNamespaces(r *resource.Resource) []string {
+	nsSelector, ok := getMap(r, "spec.namespaceSelector")
+	if !ok {
+		return []string{r.GetNamespace()}
+	}
+
+	if anyNamespace, _ := nsSelector["any"].(bool); anyNamespace {
+		return nil
+	}
+
+	matchNames, _ := nsSelector["matchNames"].([]any)
+	namespaces := make([]string, 0, len(matchNames))
+	for _, item := range matchNames {
+		if ns, ok := item.(string); ok {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{r.GetNamespace()}
+	}
+
+	return namespaces
+}
+
+// detectRouteReferences returns the parent Gateways and backend Services,
+// which are referenced by the given Gateway API route.
+func detectRouteReferences(r *resource.Resource, idx *resourceIndex) []relation {
+	relations := make([]relation, 0)
+
+	for _, ref := range getMaps(r, "spec.parentRefs") {
+		kind := stringOrDefault(ref, "kind", "Gateway")
+		namespace := stringOrDefault(ref, "namespace", r.GetNamespace())
+		name, _ := ref["name"].(string)
+		if parent := idx.find(kind, namespace, name); parent != nil {
+			relations = append(relations, relation{to: parent, label: "parent"})
+		}
+	}
+
+	for _, rule := range getMaps(r, "spec.rules") {
+		backendRefs, _ := rule["backendRefs"].([]any)
+		for _, item := range backendRefs {
+			ref, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			kind := stringOrDefault(ref, "kind", "Service")
+			namespace := stringOrDefault(ref, "namespace", r.GetNamespace())
+			name, _ := ref["name"].(string)
+			if backend := idx.find(kind, namespace, name); backend != nil {
+				relations = append(relations, relation{to: backend, label: "backend"})
+			}
+		}
+	}
+
+	return relations
+}
+
+// detectWebhookServices returns the services, which are referenced by the
+// webhooks of the given admission webhook configuration.
+func detectWebhookServices(r *resource.Resource, idx *resourceIndex) []relation {
+	// Webhooks calling the same Service share a single edge, which is
+	// labeled with the names of all of them.
+	services := make([]*resource.Resource, 0)
+	names := make(map[*resource.Resource][]string)
+	for _, webhook := range getMaps(r, "webhooks") {
+		clientConfig, _ := webhook["clientConfig"].(map[string]any)
+		service, ok := clientConfig["service"].(map[string]any)
+		if !ok {
+			// Webhook is configured with an URL
+			continue
+		}
+
+		namespace, _ := service["namespace"].(string)
+		name, _ := service["name"].(string)
+		svc := idx.find("Service", namespace, name)
+		if svc == nil {
+			continue
+		}
+		if _, ok := names[svc]; !ok {
+			services = append(services, svc)
+		}
+		if label, _ := webhook["name"].(string); label != "" {
+			names[svc] = append(names[svc], label)
+		}
+	}
+
+	relations := make([]relation, 0, len(services))
+	for _, svc := range services {
+		relations = append(relations, relation{to: svc, label: strings.Join(names[svc], ", ")})
+	}
+
+	return relations
+}
+
+// detectCertificateReferences returns the Issuer or ClusterIssuer and the
+// Secret, which are referenced by the given cert-manager Certificate.
+func detectCertificateReferences(r *resource.Resource, idx *resourceIndex) []relation {
+	relations := make([]relation, 0)
+
+	issuerRef, ok := getMap(r, "spec.issuerRef")
+	if ok {
+		kind := stringOrDefault(issuerRef, "kind", "Issuer")
+		name, _ := issuerRef["name"].(string)
+		namespace := r.GetNamespace()
+		if strings.EqualFold(kind, "ClusterIssuer") {
+			namespace = ""
+		}
+		if issuer := idx.find(kind, namespace, name); issuer != nil {
+			relations = append(relations, relation{to: issuer, label: "issued by"})
+		}
+	}
+
+	secretName, err := r.GetString("spec.secretName")
+	if err == nil {
+		if secret := idx.find("Secret", r.GetNamespace(), secretName); secret != nil {
+			relations = append(relations, relation{to: secret, label: "stored in"})
+		}
+	}
+
+	return relations
+}
+
+// detectWorkloadServiceAccount returns the ServiceAccount, which is used by
+// the pods of the given workload.
+func detectWorkloadServiceAccount(r *resource.Resource, idx *resourceIndex) []relation {
+	podSpec, ok := getPodSpec(r)
+	if !ok {
+		return nil
+	}
+
+	name := stringOrDefault(podSpec, "serviceAccountName", "")
+	if name == "" {
+		// Fallback to the deprecated field, and then to the ServiceAccount,
+		// which Kubernetes assigns by default.
+		name = stringOrDefault(podSpec, "serviceAccount", "default")
+	}
+
+	sa := idx.find("ServiceAccount", r.GetNamespace(), name)
+	if sa == nil {
+		return nil
+	}
+
+	return []relation{{to: sa, label: "runs as"}}
+}
+
+// detectWorkloadClasses returns the cluster-scoped PriorityClass and
+// RuntimeClass, which are referenced by the pods of the given workload.
+func detectWorkloadClasses(r *resource.Resource, idx *resourceIndex) []relation {
+	podSpec, ok := getPodSpec(r)
+	if !ok {
+		return nil
+	}
+
+	relations := make([]relation, 0)
+	if name := stringOrDefault(podSpec, "priorityClassName", ""); name != "" {
+		if pc := idx.find("PriorityClass", "", name); pc != nil {
+			relations = append(relations, relation{to: pc, label: "priority"})
+		}
+	}
+
+	if name := stringOrDefault(podSpec, "runtimeClassName", ""); name != "" {
+		if rc := idx.find("RuntimeClass", "", name); rc != nil {
+			relations = append(relations, relation{to: rc, label: "runtime"})
+		}
+	}
+
+	return relations
+}
+
+// detectStatefulSetService returns the headless Service, which governs the
+// network identity of the pods managed by the given StatefulSet.
+func detectStatefulSetService(r *resource.Resource, idx *resourceIndex) []relation {
+	name, err := r.GetString("spec.serviceName")
+	if err != nil || name == "" {
+		return nil
+	}
+
+	svc := idx.find("Service", r.GetNamespace(), name)
+	if svc == nil {
+		return nil
+	}
+
+	return []relation{{to: svc, label: "governed by"}}
+}
+
+// podTemplateFieldPath returns the period-delimited path to the given field
+// of the pod template managed by the workload.
+func podTemplateFieldPath(r *resource.Resource, field string) (string, bool) {
+	path, ok := workloadPodTemplatePaths[strings.ToLower(r.GetKind())]
+	if !ok {
+		return "", false
+	}
+
+	if path == "" {
+		return field, true
+	}
+
+	return path + "." + field, true
+}
+
+// getPodSpec returns the spec of the pods managed by the given workload.
+func getPodSpec(r *resource.Resource) (map[string]any, bool) {
+	path, ok := podTemplateFieldPath(r, "spec")
+	if !ok {
+		return nil, false
+	}
+
+	return getMap(r, path)
+}
+
+// podLabels returns the labels of the pods managed by the given workload.
+func podLabels(r *resource.Resource) map[string]string {
+	path, ok := podTemplateFieldPath(r, "metadata.labels")
+	if !ok {
+		return nil
+	}
+
+	labels, _ := getStringMap(r, path)
+
+	return labels
+}
+
+// getMap returns the map found at the given period-delimited path of the
+// resource.
+func getMap(r *resource.Resource, path string) (map[string]any, bool) {
+	value, err := r.GetFieldValue(path)
+	if err != nil {
+		return nil, false
+	}
+
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return m, true
+}
+
+// getMaps returns the maps contained within the sequence found at the given
+// period-delimited path of the resource. Any non-map items from the sequence
+// are skipped.
+func getMaps(r *resource.Resource, path string) []map[string]any {
+	value, err := r.GetFieldValue(path)
+	if err != nil {
+		return nil
+	}
+
+	items, _ := value.([]any)
+	result := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// stringOrDefault returns the string value of the given key from the map, or
+// the default value, if the key is missing or empty.
+func stringOrDefault(m map[string]any, key string, defaultValue string) string {
+	value, _ := m[key].(string)
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getStringMap returns the map of strings found at the given
+// period-delimited path of the resource.
+func getStringMap(r *resource.Resource, path string) (map[string]string, bool) {
+	m, ok := getMap(r, path)
+	if !ok {
+		return nil, false
+	}
+
+	return toStringMap(m), true
+}
+
+// toStringMap converts the given map to a map of strings, skipping any
+// non-string values.
+func toStringMap(m map[string]any) map[string]string {
+	result := make(map[string]string)
+	for k, v := range m {
+		s, ok := v.(string)
+		if ok {
+			result[k] = s
+		}
+	}
+
+	return result
+}
+
+// selectorMatches is a predicate, which returns true, if the given label
+// selector matches the given labels. The label selector is expected to be in
+// the form of a Kubernetes LabelSelector, which contains matchLabels and/or
+// matchExpressions. An empty selector matches everything.
+func selectorMatches(selector map[string]any, labels map[string]string) bool {
+	matchLabels, _ := selector["matchLabels"].(map[string]any)
+	for k, v := range toStringMap(matchLabels) {
+		if labels[k] != v {
+			return false
+		}
+	}
+
+	matchExpressions, _ := selector["matchExpressions"].([]any)
+	for _, item := range matchExpressions {
+		expr, ok := item.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		key, _ := expr["key"].(string)
+		operator, _ := expr["operator"].(string)
+		rawValues, _ := expr["values"].([]any)
+		values := make([]string, 0, len(rawValues))
+		for _, rv := range rawValues {
+			if s, ok := rv.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		value, exists := labels[key]
+		switch operator {
+		case "In":
+			if !exists || !slices.Contains(values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && slices.Contains(values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		default:
+			// Unknown operators never match
+			return false
+		}
+	}
+
+	return true
+}
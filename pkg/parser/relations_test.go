@@ -0,0 +1,726 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// pdbResources contains a Deployment, a PodDisruptionBudget which protects
+// it, and an orphan PodDisruptionBudget, which doesn't match anything.
+const pdbResources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+  namespace: default
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: orphan
+  namespace: default
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: missing
+`
+
+func TestSelectorMatches(t *testing.T) {
+	type testCase struct {
+		desc     string
+		selector map[string]any
+		labels   map[string]string
+		want     bool
+	}
+
+	labels := map[string]string{
+		"app":  "web",
+		"tier": "frontend",
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "empty selector",
+			selector: map[string]any{},
+			labels:   labels,
+			want:     true,
+		},
+		{
+			desc: "matchLabels - match",
+			selector: map[string]any{
+				"matchLabels": map[string]any{"app": "web"},
+			},
+			labels: labels,
+			want:   true,
+		},
+		{
+			desc: "matchLabels - no match",
+			selector: map[string]any{
+				"matchLabels": map[string]any{"app": "db"},
+			},
+			labels: labels,
+			want:   false,
+		},
+		{
+			desc: "matchExpressions In - match",
+			selector: map[string]any{
+				"matchExpressions": []any{
+					map[string]any{"key": "tier", "operator": "In", "values": []any{"frontend", "backend"}},
+				},
+			},
+			labels: labels,
+			want:   true,
+		},
+		{
+			desc: "matchExpressions NotIn - no match",
+			selector: map[string]any{
+				"matchExpressions": []any{
+					map[string]any{"key": "tier", "operator": "NotIn", "values": []any{"frontend"}},
+				},
+			},
+			labels: labels,
+			want:   false,
+		},
+		{
+			desc: "matchExpressions Exists and DoesNotExist - match",
+			selector: map[string]any{
+				"matchExpressions": []any{
+					map[string]any{"key": "app", "operator": "Exists"},
+					map[string]any{"key": "canary", "operator": "DoesNotExist"},
+				},
+			},
+			labels: labels,
+			want:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := selectorMatches(tc.selector, tc.labels)
+			if got != tc.want {
+				t.Fatalf("selectorMatches() returned %t, expected %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPodDisruptionBudgetRelations(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(pdbResources))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	if !g.EdgeExists("default/poddisruptionbudget/web", "default/deployment/web") {
+		t.Fatal("missing edge between PodDisruptionBudget and Deployment")
+	}
+
+	gotEs := g.GetEdges()
+	if len(gotEs) != 1 {
+		t.Fatalf("want |E|=1, got |E|=%d", len(gotEs))
+	}
+
+	orphan := g.GetVertex("default/poddisruptionbudget/orphan")
+	if _, ok := orphan.DotAttributes["penwidth"]; ok {
+		t.Fatal("orphan PodDisruptionBudget is marked, although marking orphans is disabled")
+	}
+}
+
+func TestOrphanStyle(t *testing.T) {
+	type testCase struct {
+		desc string
+		opts []Option
+		want graph.DotAttributes
+	}
+
+	testCases := []testCase{
+		{
+			desc: "default style",
+			opts: []Option{WithOrphanStyle(nil)},
+			want: graph.DotAttributes{
+				"color":    "red",
+				"style":    "filled, rounded, dashed",
+				"penwidth": "2",
+			},
+		},
+		{
+			desc: "configured style",
+			opts: []Option{WithOrphanStyle(graph.DotAttributes{"color": "orange", "penwidth": "3"})},
+			want: graph.DotAttributes{
+				"color":    "orange",
+				"style":    "filled, rounded, dashed",
+				"penwidth": "3",
+			},
+		},
+		{
+			desc: "highlight overrides the style",
+			opts: []Option{
+				WithOrphanStyle(nil),
+				WithHighlightKind("PodDisruptionBudget", "yellow"),
+			},
+			want: graph.DotAttributes{
+				"color":    "yellow",
+				"style":    "filled, rounded, dashed",
+				"penwidth": "2",
+			},
+		},
+	}
+
+	resources, err := ResourcesFromBytes([]byte(pdbResources))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := New(tc.opts...).ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			orphan := g.GetVertex("default/poddisruptionbudget/orphan")
+			for k, v := range tc.want {
+				if got := orphan.DotAttributes[k]; got != v {
+					t.Fatalf("want orphan attribute %s=%q, got %q", k, v, got)
+				}
+			}
+
+			// Resources with relationships are not marked
+			web := g.GetVertex("default/poddisruptionbudget/web")
+			if _, ok := web.DotAttributes["penwidth"]; ok {
+				t.Fatal("PodDisruptionBudget with target is marked as orphan")
+			}
+		})
+	}
+}
+
+func TestMonitorRelations(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.KubePrometheus))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	podMonitor := `
+apiVersion: monitoring.coreos.com/v1
+kind: PodMonitor
+metadata:
+  name: node-exporter
+  namespace: default
+spec:
+  namespaceSelector:
+    matchNames:
+      - monitoring
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: node-exporter
+`
+	extra, err := ResourcesFromBytes([]byte(podMonitor))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	resources = append(resources, extra...)
+
+	p := New(WithOrphanStyle(nil))
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	type testCase struct {
+		desc string
+		from string
+		to   string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "ServiceMonitor to Service",
+			from: "monitoring/servicemonitor/grafana",
+			to:   "monitoring/service/grafana",
+		},
+		{
+			desc: "ServiceMonitor to Service with multiple labels",
+			from: "monitoring/servicemonitor/node-exporter",
+			to:   "monitoring/service/node-exporter",
+		},
+		{
+			desc: "PodMonitor to workload in another namespace",
+			from: "default/podmonitor/node-exporter",
+			to:   "monitoring/daemonset/node-exporter",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if !g.EdgeExists(tc.from, tc.to) {
+				t.Fatalf("missing edge %s -> %s", tc.from, tc.to)
+			}
+		})
+	}
+
+	// The kube-dns service is not part of the build
+	orphan := g.GetVertex("monitoring/servicemonitor/coredns")
+	if orphan.DotAttributes["color"] != "red" {
+		t.Fatal("orphan ServiceMonitor is not painted as orphan")
+	}
+}
+
+func TestGatewayRouteRelations(t *testing.T) {
+	data := `
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: public
+  namespace: infra
+spec:
+  gatewayClassName: example
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: grpc
+  namespace: default
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+    - name: public
+      namespace: infra
+  rules:
+    - backendRefs:
+        - name: web
+          port: 80
+        - name: missing
+          port: 80
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: GRPCRoute
+metadata:
+  name: grpc
+  namespace: default
+spec:
+  parentRefs:
+    - name: public
+      namespace: infra
+  rules:
+    - backendRefs:
+        - name: grpc
+          port: 9000
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantEdges := [][2]string{
+		{"default/httproute/web", "infra/gateway/public"},
+		{"default/httproute/web", "default/service/web"},
+		{"default/grpcroute/grpc", "infra/gateway/public"},
+		{"default/grpcroute/grpc", "default/service/grpc"},
+	}
+	for _, edge := range wantEdges {
+		if !g.EdgeExists(edge[0], edge[1]) {
+			t.Fatalf("missing edge %s -> %s", edge[0], edge[1])
+		}
+	}
+
+	gotEs := g.GetEdges()
+	if len(gotEs) != len(wantEdges) {
+		t.Fatalf("want |E|=%d, got |E|=%d", len(wantEdges), len(gotEs))
+	}
+}
+
+func TestWebhookRelations(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook
+  namespace: cert-manager
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating
+webhooks:
+  - name: validate.example.com
+    clientConfig:
+      service:
+        name: webhook
+        namespace: cert-manager
+  - name: remote.example.com
+    clientConfig:
+      url: https://example.com/validate
+  - name: validate-more.example.com
+    clientConfig:
+      service:
+        name: webhook
+        namespace: cert-manager
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating
+webhooks:
+  - name: mutate.example.com
+    clientConfig:
+      service:
+        name: webhook
+        namespace: cert-manager
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantEdges := [][2]string{
+		{"validatingwebhookconfiguration/validating", "cert-manager/service/webhook"},
+		{"mutatingwebhookconfiguration/mutating", "cert-manager/service/webhook"},
+	}
+	for _, edge := range wantEdges {
+		if !g.EdgeExists(edge[0], edge[1]) {
+			t.Fatalf("missing edge %s -> %s", edge[0], edge[1])
+		}
+	}
+
+	gotEs := g.GetEdges()
+	if len(gotEs) != len(wantEdges) {
+		t.Fatalf("want |E|=%d, got |E|=%d", len(wantEdges), len(gotEs))
+	}
+
+	// Webhooks calling the same Service are listed in the label of the edge
+	e := g.GetEdge("validatingwebhookconfiguration/validating", "cert-manager/service/webhook")
+	wantLabel := "validate.example.com, validate-more.example.com"
+	if got := e.DotAttributes["label"]; got != wantLabel {
+		t.Fatalf("want edge label %q, got %q", wantLabel, got)
+	}
+}
+
+func TestCertificateRelations(t *testing.T) {
+	data := `
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: letsencrypt
+---
+apiVersion: cert-manager.io/v1
+kind: Issuer
+metadata:
+  name: self-signed
+  namespace: default
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-tls
+  namespace: default
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+  namespace: default
+spec:
+  secretName: web-tls
+  issuerRef:
+    name: letsencrypt
+    kind: ClusterIssuer
+    group: cert-manager.io
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: internal
+  namespace: default
+spec:
+  secretName: internal-tls
+  issuerRef:
+    name: self-signed
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantEdges := [][2]string{
+		{"default/certificate/web", p.vertexNameFromResource(resources[0])},
+		{"default/certificate/web", "default/secret/web-tls"},
+		{"default/certificate/internal", "default/issuer/self-signed"},
+	}
+	for _, edge := range wantEdges {
+		if !g.EdgeExists(edge[0], edge[1]) {
+			t.Fatalf("missing edge %s -> %s", edge[0], edge[1])
+		}
+	}
+
+	gotEs := g.GetEdges()
+	if len(gotEs) != len(wantEdges) {
+		t.Fatalf("want |E|=%d, got |E|=%d", len(wantEdges), len(gotEs))
+	}
+}
+
+func TestServiceAccountRelations(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.KubePrometheus))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(WithOrphanStyle(nil))
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	type testCase struct {
+		desc string
+		from string
+		to   string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "Deployment to ServiceAccount",
+			from: "monitoring/deployment/grafana",
+			to:   "monitoring/serviceaccount/grafana",
+		},
+		{
+			desc: "DaemonSet to ServiceAccount",
+			from: "monitoring/daemonset/node-exporter",
+			to:   "monitoring/serviceaccount/node-exporter",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if !g.EdgeExists(tc.from, tc.to) {
+				t.Fatalf("missing edge %s -> %s", tc.from, tc.to)
+			}
+		})
+	}
+}
+
+func TestWorkloadClassRelations(t *testing.T) {
+	data := `
+apiVersion: scheduling.k8s.io/v1
+kind: PriorityClass
+metadata:
+  name: critical
+value: 1000000
+---
+apiVersion: node.k8s.io/v1
+kind: RuntimeClass
+metadata:
+  name: gvisor
+handler: runsc
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          priorityClassName: critical
+          runtimeClassName: gvisor
+          containers:
+            - name: backup
+              image: busybox
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: sandbox
+  namespace: default
+spec:
+  runtimeClassName: gvisor
+  containers:
+    - name: sandbox
+      image: busybox
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	priorityClass := p.vertexNameFromResource(resources[0])
+	runtimeClass := p.vertexNameFromResource(resources[1])
+	wantEdges := [][2]string{
+		{"default/cronjob/backup", priorityClass},
+		{"default/cronjob/backup", runtimeClass},
+		{"default/pod/sandbox", runtimeClass},
+	}
+	for _, edge := range wantEdges {
+		if !g.EdgeExists(edge[0], edge[1]) {
+			t.Fatalf("missing edge %s -> %s", edge[0], edge[1])
+		}
+	}
+
+	gotEs := g.GetEdges()
+	if len(gotEs) != len(wantEdges) {
+		t.Fatalf("want |E|=%d, got |E|=%d", len(wantEdges), len(gotEs))
+	}
+}
+
+func TestStatefulSetServiceRelations(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+  namespace: default
+spec:
+  clusterIP: None
+  selector:
+    app: db
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: default
+spec:
+  serviceName: db
+  selector:
+    matchLabels:
+      app: db
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+        - name: db
+          image: postgres
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: cache
+  namespace: other
+spec:
+  serviceName: db
+  selector:
+    matchLabels:
+      app: cache
+  template:
+    metadata:
+      labels:
+        app: cache
+    spec:
+      containers:
+        - name: cache
+          image: redis
+`
+	resources, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New()
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	if !g.EdgeExists("default/statefulset/db", "default/service/db") {
+		t.Fatal("missing edge from statefulset to headless service")
+	}
+	if g.EdgeExists("other/statefulset/cache", "default/service/db") {
+		t.Fatal("unexpected edge to service from another namespace")
+	}
+
+	gotEs := g.GetEdges()
+	if len(gotEs) != 1 {
+		t.Fatalf("want |E|=1, got |E|=%d", len(gotEs))
+	}
+}
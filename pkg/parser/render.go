@@ -0,0 +1,238 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned when there is no [Renderer] for a format.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ErrRenderFailed is returned when an external tool fails to render a graph.
+var ErrRenderFailed = errors.New("render failed")
+
+// Format is a type which represents the output format of a [Graph].
+type Format string
+
+// String implements the [fmt.Stringer] interface
+func (f Format) String() string {
+	return string(f)
+}
+
+const (
+	// FormatDot specifies the Dot language
+	FormatDot Format = "dot"
+
+	// FormatSVG specifies SVG images rendered by Graphviz
+	FormatSVG Format = "svg"
+
+	// FormatPNG specifies PNG images rendered by Graphviz
+	FormatPNG Format = "png"
+
+	// FormatMermaid specifies Mermaid flowcharts
+	FormatMermaid Format = "mermaid"
+
+	// FormatJSON specifies the JSON representation of the graph
+	FormatJSON Format = "json"
+)
+
+// Renderer is a function, which writes a [Graph] in a given [Format] to an
+// [io.Writer].
+type Renderer func(g *Graph, w io.Writer) error
+
+// GraphvizCommand is the Graphviz command used to render images.
+var GraphvizCommand = "dot"
+
+// renderers contains the registered renderers by format.
+var renderers = map[Format]Renderer{
+	FormatDot:     WriteDot,
+	FormatSVG:     graphvizRenderer(FormatSVG),
+	FormatPNG:     graphvizRenderer(FormatPNG),
+	FormatMermaid: WriteMermaid,
+	FormatJSON:    WriteJSON,
+}
+
+// RegisterRenderer registers the [Renderer] for the given [Format], replacing
+// any renderer registered for it already. New formats are plugged in this way,
+// and are then available to [NewRenderer] and [Render]. This function is not
+// safe for concurrent use, and should be called during initialization.
+func RegisterRenderer(format Format, renderer Renderer) {
+	renderers[format] = renderer
+	delete(contextRenderers, format)
+}
+
+// Formats returns the formats of the registered renderers in sorted order.
+func Formats() []Format {
+	formats := make([]Format, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+
+	return formats
+}
+
+// NewRenderer returns the [Renderer] for the given [Format].
+func NewRenderer(format Format) (Renderer, error) {
+	renderer, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	return renderer, nil
+}
+
+// contextRenderers contains the constructors of the built-in renderers by
+// format, which support cancellation, because they run external tools.
+var contextRenderers = map[Format]func(ctx context.Context) Renderer{
+	FormatSVG: func(ctx context.Context) Renderer {
+		return NewGraphvizRendererContext(ctx, EngineDot, FormatSVG.String())
+	},
+	FormatPNG: func(ctx context.Context) Renderer {
+		return NewGraphvizRendererContext(ctx, EngineDot, FormatPNG.String())
+	},
+}
+
+// NewRendererContext is like [NewRenderer], but the returned [Renderer] stops
+// any external tool it runs, when the given context is cancelled.
+func NewRendererContext(ctx context.Context, format Format) (Renderer, error) {
+	if newRenderer, ok := contextRenderers[format]; ok {
+		return newRenderer(ctx), nil
+	}
+
+	return NewRenderer(format)
+}
+
+// Render writes the [Graph] in the given [Format] to the [io.Writer].
+func Render(g *Graph, format Format, w io.Writer) error {
+	renderer, err := NewRenderer(format)
+	if err != nil {
+		return err
+	}
+
+	return renderer(g, w)
+}
+
+// Engine is a type which represents a Graphviz layout engine.
+type Engine string
+
+// String implements the [fmt.Stringer] interface
+func (e Engine) String() string {
+	return string(e)
+}
+
+const (
+	// EngineDot lays out graphs hierarchically
+	EngineDot Engine = "dot"
+
+	// EngineNeato lays out graphs using a spring model
+	EngineNeato Engine = "neato"
+
+	// EngineFdp lays out graphs using a force-directed model
+	EngineFdp Engine = "fdp"
+
+	// EngineSfdp lays out large graphs using a force-directed model
+	EngineSfdp Engine = "sfdp"
+
+	// EngineCirco lays out graphs in circles
+	EngineCirco Engine = "circo"
+
+	// EngineTwopi lays out graphs radially
+	EngineTwopi Engine = "twopi"
+
+	// EngineOsage lays out clustered graphs
+	EngineOsage Engine = "osage"
+
+	// EnginePatchwork lays out graphs as squarified tree maps
+	EnginePatchwork Engine = "patchwork"
+)
+
+// Engines returns the supported Graphviz layout engines.
+func Engines() []Engine {
+	engines := []Engine{
+		EngineDot,
+		EngineNeato,
+		EngineFdp,
+		EngineSfdp,
+		EngineCirco,
+		EngineTwopi,
+		EngineOsage,
+		EnginePatchwork,
+	}
+
+	return engines
+}
+
+// graphvizRenderer returns a [Renderer], which renders the given image format
+// using the dot layout engine.
+func graphvizRenderer(format Format) Renderer {
+	return NewGraphvizRenderer(EngineDot, format.String())
+}
+
+// NewGraphvizRenderer returns a [Renderer], which pipes the Dot representation
+// of the graph through the [GraphvizCommand] in order to lay it out with the
+// given engine, and render it as the given output type, e.g. svg, png or pdf.
+func NewGraphvizRenderer(engine Engine, outputType string) Renderer {
+	return NewGraphvizRendererContext(context.Background(), engine, outputType)
+}
+
+// NewGraphvizRendererContext is like [NewGraphvizRenderer], but the
+// [GraphvizCommand] is killed, when the given context is cancelled.
+func NewGraphvizRendererContext(ctx context.Context, engine Engine, outputType string) Renderer {
+	renderer := func(g *Graph, w io.Writer) error {
+		var in bytes.Buffer
+		if err := WriteDot(g, &in); err != nil {
+			return err
+		}
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, GraphvizCommand, "-K"+engine.String(), "-T"+outputType)
+		cmd.Stdin = &in
+		cmd.Stdout = w
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("%w: %s: %w", ErrRenderFailed, outputType, ctxErr)
+			}
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("%w: %s: %w: %s", ErrRenderFailed, outputType, err, msg)
+			}
+			return fmt.Errorf("%w: %s: %w", ErrRenderFailed, outputType, err)
+		}
+
+		return nil
+	}
+
+	return renderer
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	g := newGraph()
+	g.AddVertex("a")
+
+	for _, format := range []Format{FormatDot, FormatMermaid, FormatJSON} {
+		var buf bytes.Buffer
+		if err := Render(g, format, &buf); err != nil {
+			t.Fatalf("failed to render %s: %s", format, err)
+		}
+		if buf.Len() == 0 {
+			t.Fatalf("got empty %s output", format)
+		}
+	}
+
+	if err := Render(g, Format("unknown"), io.Discard); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("want ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	format := Format("test")
+	t.Cleanup(func() { delete(renderers, format) })
+
+	RegisterRenderer(format, func(g *Graph, w io.Writer) error {
+		_, err := io.WriteString(w, "test")
+		return err
+	})
+	if !slices.Contains(Formats(), format) {
+		t.Fatalf("want %s in formats, got %v", format, Formats())
+	}
+
+	var buf bytes.Buffer
+	if err := Render(newGraph(), format, &buf); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if buf.String() != "test" {
+		t.Fatalf("want test, got %q", buf.String())
+	}
+}
+
+func TestGraphvizRendererFailed(t *testing.T) {
+	command := GraphvizCommand
+	GraphvizCommand = "kustomize-dot-missing-graphviz"
+	t.Cleanup(func() { GraphvizCommand = command })
+
+	if err := Render(newGraph(), FormatSVG, io.Discard); !errors.Is(err, ErrRenderFailed) {
+		t.Fatalf("want ErrRenderFailed, got %v", err)
+	}
+}
+
+func TestNewGraphvizRenderer(t *testing.T) {
+	// Fake Graphviz command, which echoes its arguments and input
+	command := filepath.Join(t.TempDir(), "dot")
+	script := "#!/bin/sh\necho \"$@\"\ncat\n"
+	if err := os.WriteFile(command, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write command: %s", err)
+	}
+
+	previous := GraphvizCommand
+	GraphvizCommand = command
+	t.Cleanup(func() { GraphvizCommand = previous })
+
+	g := newGraph()
+	g.AddVertex("a")
+
+	var buf bytes.Buffer
+	if err := NewGraphvizRenderer(EngineNeato, "pdf")(g, &buf); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	args, dot, _ := strings.Cut(buf.String(), "\n")
+	if args != "-Kneato -Tpdf" {
+		t.Fatalf("want -Kneato -Tpdf args, got %q", args)
+	}
+	if !strings.Contains(dot, `"a"`) {
+		t.Fatalf("want dot input, got %q", dot)
+	}
+}
+
+func TestNewGraphvizRendererContext(t *testing.T) {
+	// Fake Graphviz command, which never completes
+	command := filepath.Join(t.TempDir(), "dot")
+	script := "#!/bin/sh\nexec sleep 10\n"
+	if err := os.WriteFile(command, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write command: %s", err)
+	}
+
+	previous := GraphvizCommand
+	GraphvizCommand = command
+	t.Cleanup(func() { GraphvizCommand = previous })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := NewGraphvizRendererContext(ctx, EngineDot, "svg")(newGraph(), io.Discard)
+	if !errors.Is(err, ErrRenderFailed) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want ErrRenderFailed and deadline exceeded, got %v", err)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	g := newGraph()
+	g.AddVertex("b")
+	g.AddVertex("a").DotAttributes["label"] = "vertex a"
+	g.AddEdge("b", "a").DotAttributes["style"] = "dashed"
+	g.addToCluster("ns", "a")
+
+	var buf bytes.Buffer
+	if err := WriteJSON(g, &buf); err != nil {
+		t.Fatalf("failed to write json: %s", err)
+	}
+
+	var got jsonGraph
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode json: %s", err)
+	}
+
+	if len(got.Vertices) != 2 || got.Vertices[0].Name != "a" || got.Vertices[0].Attributes["label"] != "vertex a" {
+		t.Fatalf("unexpected vertices: %v", got.Vertices)
+	}
+	if len(got.Edges) != 1 || got.Edges[0].From != "b" || got.Edges[0].To != "a" || got.Edges[0].Attributes["style"] != "dashed" {
+		t.Fatalf("unexpected edges: %v", got.Edges)
+	}
+	if len(got.Clusters) != 1 || got.Clusters[0].Label != "ns" || !slices.Equal(got.Clusters[0].Vertices, []string{"a"}) {
+		t.Fatalf("unexpected clusters: %v", got.Clusters)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g := newGraph()
+	g.GetDotAttributes()["rankdir"] = "LR"
+	g.AddVertex("b").DotAttributes["fillcolor"] = "red"
+	g.AddVertex("a").DotAttributes["label"] = `say "hi"\nthere`
+	g.AddEdge("b", "a").DotAttributes["style"] = "dotted"
+	g.addToCluster("ns", "a")
+
+	var buf bytes.Buffer
+	if err := WriteMermaid(g, &buf); err != nil {
+		t.Fatalf("failed to write mermaid: %s", err)
+	}
+
+	want := `flowchart LR
+	n0["say #quot;hi#quot;<br>there"]
+	n1["b"]
+	subgraph cluster_0["ns"]
+		n0
+	end
+	n1 -.-> n0
+	style n1 fill:#ff0000
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ErrInvalidRule is returned when a filter rule is not valid.
+var ErrInvalidRule = errors.New("invalid rule")
+
+// RuleAction represents the action taken by a [Rule], when it matches a
+// resource.
+type RuleAction string
+
+const (
+	// RuleActionKeep specifies that matching resources are kept.
+	RuleActionKeep RuleAction = "keep"
+
+	// RuleActionDrop specifies that matching resources are dropped.
+	RuleActionDrop RuleAction = "drop"
+
+	// RuleActionHighlight specifies that matching resources are painted
+	// with the color of the rule.
+	RuleActionHighlight RuleAction = "highlight"
+)
+
+// Rule represents a filter rule, which matches resources by kind, namespace,
+// labels and origin path. Empty criteria match any resource. The kind and
+// namespace may be negated using the "!" prefix, the kind may be qualified
+// with the API version, e.g. apps/v1/Deployment, and the origin path is a glob
+// pattern.
+type Rule struct {
+	// Action specifies what to do with matching resources
+	Action RuleAction `yaml:"action"`
+
+	// Kind is the kind of matching resources
+	Kind string `yaml:"kind,omitempty"`
+
+	// Namespace is the namespace of matching resources
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Labels contains the labels, which matching resources must have
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Origin is the glob pattern matching the origin path of resources
+	Origin string `yaml:"origin,omitempty"`
+
+	// Color is the color used by highlight rules to paint both the outline
+	// and the fill of the vertex
+	Color string `yaml:"color,omitempty"`
+
+	// BorderColor is the color of the vertex outline used by highlight
+	// rules, which takes precedence over the color of the rule
+	BorderColor string `yaml:"borderColor,omitempty"`
+
+	// PenWidth is the width of the vertex outline used by highlight rules
+	PenWidth float64 `yaml:"penwidth,omitempty"`
+
+	// Style is the comma-separated list of vertex styles used by highlight
+	// rules, e.g. "filled, rounded, dashed"
+	Style string `yaml:"style,omitempty"`
+}
+
+// ruleFile represents a file containing filter rules.
+type ruleFile struct {
+	// Rules contains the ordered list of rules
+	Rules []*Rule `yaml:"rules"`
+}
+
+// RulesFromBytes parses and validates the filter rules from the given data.
+func RulesFromBytes(data []byte) ([]*Rule, error) {
+	var rf ruleFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range rf.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+	}
+
+	return rf.Rules, nil
+}
+
+// RulesFromPath parses and validates the filter rules from the given path.
+func RulesFromPath(path string) ([]*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return RulesFromBytes(data)
+}
+
+// Validate validates the rule.
+func (rule *Rule) Validate() error {
+	switch rule.Action {
+	case RuleActionKeep, RuleActionDrop:
+		return nil
+	case RuleActionHighlight:
+		if rule.Color == "" && rule.BorderColor == "" && rule.PenWidth == 0 && rule.Style == "" {
+			return fmt.Errorf("%w: highlight rule without color, border color, penwidth or style", ErrInvalidRule)
+		}
+		if rule.PenWidth < 0 {
+			return fmt.Errorf("%w: negative penwidth %v", ErrInvalidRule, rule.PenWidth)
+		}
+		if !isNodeStyle(rule.Style) {
+			return fmt.Errorf("%w: unknown style %q", ErrInvalidRule, rule.Style)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidRule, rule.Action)
+	}
+}
+
+// matches is a predicate, which returns true, if the given resource matches
+// all criteria of the rule.
+func (rule *Rule) matches(r *resource.Resource) bool {
+	gvk := r.GetGvk()
+	isKind := func(kind string) bool { return kindMatches(gvk, kind, foldMatches) }
+	if rule.Kind != "" && !negatableMatches(rule.Kind, isKind) {
+		return false
+	}
+
+	if rule.Namespace != "" && !filterMatches(rule.Namespace, r.GetNamespace()) {
+		return false
+	}
+
+	labels := r.GetLabels()
+	for k, v := range rule.Labels {
+		if val, ok := labels[k]; !ok || val != v {
+			return false
+		}
+	}
+
+	if rule.Origin != "" {
+		origin, err := r.GetOrigin()
+		if err != nil || origin == nil {
+			return false
+		}
+		if !newPattern(rule.Origin, globToRegexp).matches(origin.Path) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rulesDecide returns whether the given resource is dropped by the first keep
+// or drop rule matching it, and whether such a rule was found at all.
+func rulesDecide(rules []*Rule, r *resource.Resource) (bool, bool) {
+	for _, rule := range rules {
+		if !rule.matches(r) {
+			continue
+		}
+		switch rule.Action {
+		case RuleActionKeep:
+			return false, true
+		case RuleActionDrop:
+			return true, true
+		}
+	}
+
+	return false, false
+}
+
+// rulesDropResource is a predicate, which returns true, if the given resource
+// is dropped by the rules. The first keep or drop rule matching the resource
+// decides its fate. Resources, which are not matched by any rule are dropped,
+// if there are any keep rules, and are kept otherwise.
+func rulesDropResource(rules []*Rule, r *resource.Resource) bool {
+	if drop, ok := rulesDecide(rules, r); ok {
+		return drop
+	}
+
+	return slices.ContainsFunc(rules, func(rule *Rule) bool {
+		return rule.Action == RuleActionKeep
+	})
+}
+
+// highlight sets the attributes of the highlight rule on the given vertex.
+// The border color overrides the outline painted by the color of the rule,
+// and empty attributes are left as is, so that a rule may emphasize a vertex
+// without changing its fill.
+func (rule *Rule) highlight(u *graph.Vertex[string]) {
+	if rule.Color != "" {
+		u.DotAttributes["color"] = rule.Color
+		u.DotAttributes["fillcolor"] = rule.Color
+	}
+	if rule.BorderColor != "" {
+		u.DotAttributes["color"] = rule.BorderColor
+	}
+	if rule.PenWidth > 0 {
+		u.DotAttributes["penwidth"] = strconv.FormatFloat(rule.PenWidth, 'f', -1, 64)
+	}
+	if rule.Style != "" {
+		u.DotAttributes["style"] = rule.Style
+	}
+}
+
+// applyRuleHighlights applies the highlight rules matching the given resource
+// to the [graph.Vertex] u in order, so that later rules override earlier ones.
+func applyRuleHighlights(rules []*Rule, u *graph.Vertex[string], r *resource.Resource) {
+	for _, rule := range rules {
+		if rule.Action == RuleActionHighlight && rule.matches(r) {
+			rule.highlight(u)
+		}
+	}
+}
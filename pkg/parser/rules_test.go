@@ -0,0 +1,280 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+func TestRulesFromBytes(t *testing.T) {
+	type testCase struct {
+		desc      string
+		data      string
+		wantRules int
+		wantError error
+	}
+
+	testCases := []testCase{
+		{
+			desc: "valid rules",
+			data: `
+rules:
+  - action: drop
+    kind: ConfigMap
+  - action: highlight
+    namespace: default
+    color: pink
+`,
+			wantRules: 2,
+		},
+		{
+			desc: "unknown action",
+			data: `
+rules:
+  - action: paint
+`,
+			wantError: ErrInvalidRule,
+		},
+		{
+			desc: "highlight without color",
+			data: `
+rules:
+  - action: highlight
+    kind: Service
+`,
+			wantError: ErrInvalidRule,
+		},
+		{
+			desc: "highlight with border only",
+			data: `
+rules:
+  - action: highlight
+    kind: Service
+    borderColor: red
+    penwidth: 3
+    style: filled, rounded, dashed
+`,
+			wantRules: 1,
+		},
+		{
+			desc: "highlight with unknown style",
+			data: `
+rules:
+  - action: highlight
+    kind: Service
+    style: sparkly
+`,
+			wantError: ErrInvalidRule,
+		},
+		{
+			desc: "highlight with negative penwidth",
+			data: `
+rules:
+  - action: highlight
+    kind: Service
+    penwidth: -2
+`,
+			wantError: ErrInvalidRule,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rules, err := RulesFromBytes([]byte(tc.data))
+			if !errors.Is(err, tc.wantError) {
+				t.Fatalf("want error %v, got %v", tc.wantError, err)
+			}
+			if len(rules) != tc.wantRules {
+				t.Fatalf("want %d rule(s), got %d", tc.wantRules, len(rules))
+			}
+		})
+	}
+}
+
+func TestWithRules(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		rules     []*Rule
+		wantVs    []string
+		wantNotVs []string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "drop by kind",
+			rules: []*Rule{
+				{Action: RuleActionDrop, Kind: "ConfigMap"},
+			},
+			wantVs:    []string{"default/service/the-service", "default/deployment/the-deployment"},
+			wantNotVs: []string{"default/configmap/the-map"},
+		},
+		{
+			desc: "first matching rule wins",
+			rules: []*Rule{
+				{Action: RuleActionKeep, Kind: "Service"},
+				{Action: RuleActionDrop, Namespace: "default"},
+			},
+			wantVs:    []string{"default/service/the-service"},
+			wantNotVs: []string{"default/configmap/the-map", "default/deployment/the-deployment"},
+		},
+		{
+			desc: "keep by labels and origin",
+			rules: []*Rule{
+				{Action: RuleActionKeep, Labels: map[string]string{"app": "hello"}, Origin: "**/deployment.yaml"},
+			},
+			wantVs:    []string{"default/deployment/the-deployment"},
+			wantNotVs: []string{"default/configmap/the-map", "default/service/the-service"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(WithRules(tc.rules...), WithGraphMode(GraphModeResources))
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			for _, v := range tc.wantVs {
+				if !g.VertexExists(v) {
+					t.Fatalf("want vertex %s", v)
+				}
+			}
+			for _, v := range tc.wantNotVs {
+				if g.VertexExists(v) {
+					t.Fatalf("unexpected vertex %s", v)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRulesHighlight(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	rules := []*Rule{
+		{Action: RuleActionHighlight, Namespace: "default", Color: "pink"},
+		{Action: RuleActionHighlight, Kind: "Service", Color: "yellow"},
+		{Action: RuleActionHighlight, Kind: "ConfigMap", BorderColor: "red", PenWidth: 2, Style: "filled, dashed"},
+	}
+	p := New(WithRules(rules...))
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	wantColors := map[string]string{
+		"default/configmap/the-map":   "pink",
+		"default/service/the-service": "yellow",
+	}
+	for v, color := range wantColors {
+		if got := g.GetVertex(v).DotAttributes["fillcolor"]; got != color {
+			t.Fatalf("want color %s for %s, got %s", color, v, got)
+		}
+	}
+
+	wantAttrs := graph.DotAttributes{
+		"color":     "red",
+		"fillcolor": "pink",
+		"penwidth":  "2",
+		"shape":     DefaultResourceShape,
+		"style":     "filled, dashed",
+	}
+	if got := g.GetVertex("default/configmap/the-map").DotAttributes; !maps.Equal(got, wantAttrs) {
+		t.Fatalf("want attributes %v, got %v", wantAttrs, got)
+	}
+}
+
+func TestWithFilterPrecedence(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	rules := []*Rule{
+		{Action: RuleActionKeep, Kind: "ConfigMap", Namespace: "default"},
+	}
+
+	type testCase struct {
+		desc   string
+		opts   []Option
+		wantVs []string
+	}
+
+	testCases := []testCase{
+		{
+			desc: "options take precedence",
+			opts: []Option{
+				WithRules(rules...),
+				WithDropNamespace("default"),
+			},
+			wantVs: []string{},
+		},
+		{
+			desc: "rules take precedence",
+			opts: []Option{
+				WithRules(rules...),
+				WithDropNamespace("default"),
+				WithFilterPrecedence(FilterPrecedenceRules),
+			},
+			wantVs: []string{"default/configmap/the-map"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts := append(tc.opts, WithGraphMode(GraphModeResources))
+			p := New(opts...)
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			gotVs := g.GetVertexValues()
+			if len(gotVs) != len(tc.wantVs) {
+				t.Fatalf("want |V|=%d, got |V|=%d", len(tc.wantVs), len(gotVs))
+			}
+			for _, v := range tc.wantVs {
+				if !g.VertexExists(v) {
+					t.Fatalf("want vertex %s", v)
+				}
+			}
+		})
+	}
+}
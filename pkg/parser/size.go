@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"strconv"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// Ratio is a type which represents the Graphviz aspect ratio of the drawing,
+// which is either one of the predefined values, or a number, i.e. the desired
+// ratio of height to width, e.g. 0.5.
+type Ratio string
+
+// String implements the [fmt.Stringer] interface
+func (r Ratio) String() string {
+	return string(r)
+}
+
+const (
+	// RatioFill scales the drawing up, until it fills the maximum size
+	RatioFill Ratio = "fill"
+
+	// RatioCompress compresses the drawing, so that it fits the maximum
+	// size
+	RatioCompress Ratio = "compress"
+
+	// RatioExpand scales the drawing up uniformly, until it reaches the
+	// maximum size in at least one dimension
+	RatioExpand Ratio = "expand"
+
+	// RatioAuto lets Graphviz rotate large drawings in order to fit a page
+	RatioAuto Ratio = "auto"
+)
+
+// Ratios returns the predefined aspect ratios.
+func Ratios() []Ratio {
+	return []Ratio{
+		RatioFill,
+		RatioCompress,
+		RatioExpand,
+		RatioAuto,
+	}
+}
+
+// unboundedSize is the size in inches used for the dimension of the drawing,
+// which has no maximum size, since Graphviz expects both of them.
+const unboundedSize = 10000
+
+// imageSize represents the size and resolution of the rendered images.
+type imageSize struct {
+	// dpi is the resolution in dots per inch
+	dpi float64
+
+	// maxWidth is the maximum width of the drawing in inches
+	maxWidth float64
+
+	// maxHeight is the maximum height of the drawing in inches
+	maxHeight float64
+
+	// ratio is the aspect ratio of the drawing
+	ratio Ratio
+}
+
+// apply sets the size attributes in the given graph attributes. The zero
+// values are ignored.
+func (s imageSize) apply(attrs graph.DotAttributes) {
+	if s.dpi > 0 {
+		attrs["dpi"] = strconv.FormatFloat(s.dpi, 'f', -1, 64)
+	}
+	if s.maxWidth > 0 || s.maxHeight > 0 {
+		width := s.maxWidth
+		if width <= 0 {
+			width = unboundedSize
+		}
+		height := s.maxHeight
+		if height <= 0 {
+			height = unboundedSize
+		}
+		attrs["size"] = strconv.FormatFloat(width, 'f', -1, 64) + "," + strconv.FormatFloat(height, 'f', -1, 64)
+	}
+	if s.ratio != "" {
+		attrs["ratio"] = s.ratio.String()
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestImageSize(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc      string
+		opts      []Option
+		wantDPI   string
+		wantSize  string
+		wantRatio string
+	}
+
+	testCases := []testCase{
+		{desc: "defaults", opts: nil},
+		{
+			desc:      "all attributes",
+			opts:      []Option{WithDPI(150), WithMaxSize(7.5, 10), WithRatio(RatioFill)},
+			wantDPI:   "150",
+			wantSize:  "7.5,10",
+			wantRatio: "fill",
+		},
+		{
+			desc:      "max width only",
+			opts:      []Option{WithMaxSize(8, 0), WithRatio(Ratio("0.5"))},
+			wantSize:  "8,10000",
+			wantRatio: "0.5",
+		},
+	}
+
+	for _, tc := range testCases {
+		g, err := New(tc.opts...).ParseGraph(resources)
+		if err != nil {
+			t.Fatalf("%s: failed to parse resources as graph: %s", tc.desc, err)
+		}
+		attrs := g.GetDotAttributes()
+		if got := attrs["dpi"]; got != tc.wantDPI {
+			t.Fatalf("%s: want dpi %q, got %q", tc.desc, tc.wantDPI, got)
+		}
+		if got := attrs["size"]; got != tc.wantSize {
+			t.Fatalf("%s: want size %q, got %q", tc.desc, tc.wantSize, got)
+		}
+		if got := attrs["ratio"]; got != tc.wantRatio {
+			t.Fatalf("%s: want ratio %q, got %q", tc.desc, tc.wantRatio, got)
+		}
+	}
+}
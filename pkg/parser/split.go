@@ -0,0 +1,114 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ErrUnsupportedSplitBy is returned when resources are asked to be split by
+// an unsupported property.
+var ErrUnsupportedSplitBy = errors.New("unsupported split by")
+
+// SplitBy is a type which represents the property of resources, by which they
+// are split into separate groups.
+type SplitBy string
+
+// String implements the [fmt.Stringer] interface
+func (sb SplitBy) String() string {
+	return string(sb)
+}
+
+const (
+	// SplitByNamespace specifies that resources are split by namespace
+	SplitByNamespace SplitBy = "namespace"
+
+	// SplitByRepo specifies that resources are split by the repository of
+	// their origin
+	SplitByRepo SplitBy = "repo"
+)
+
+const (
+	// SplitGroupClusterScoped is the group of the cluster-scoped resources,
+	// when resources are split by namespace.
+	SplitGroupClusterScoped = "cluster-scoped"
+
+	// SplitGroupLocal is the group of the resources, which don't come from
+	// a remote repository, when resources are split by repo.
+	SplitGroupLocal = "local"
+)
+
+// SplitBys returns the supported properties, by which resources are split.
+func SplitBys() []SplitBy {
+	return []SplitBy{
+		SplitByNamespace,
+		SplitByRepo,
+	}
+}
+
+// splitKey returns the group of the resource, when resources are split by
+// the given property.
+func splitKey(sb SplitBy, r *resource.Resource) (string, error) {
+	switch sb {
+	case SplitByNamespace:
+		if r.GetGvk().IsClusterScoped() {
+			return SplitGroupClusterScoped, nil
+		}
+		if ns := r.GetNamespace(); ns != "" {
+			return ns, nil
+		}
+		return "default", nil
+	case SplitByRepo:
+		origin, err := r.GetOrigin()
+		if err != nil {
+			return "", err
+		}
+		if origin == nil || origin.Repo == "" {
+			return SplitGroupLocal, nil
+		}
+		return origin.Repo, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedSplitBy, sb)
+	}
+}
+
+// Split splits the given resources into groups by the given property. The
+// resources of each group are kept in their original order.
+func Split(resources []*resource.Resource, sb SplitBy) (map[string][]*resource.Resource, error) {
+	groups := make(map[string][]*resource.Resource)
+	for _, r := range resources {
+		key, err := splitKey(sb, r)
+		if err != nil {
+			return nil, err
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	return groups, nil
+}
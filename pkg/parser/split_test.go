@@ -0,0 +1,102 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestSplit(t *testing.T) {
+	data := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: monitoring
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: monitoring
+`
+	extra, err := ResourcesFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+	resources = append(resources, extra...)
+
+	testCases := []struct {
+		desc  string
+		by    SplitBy
+		wants map[string]int
+	}{
+		{
+			desc: "split by namespace",
+			by:   SplitByNamespace,
+			wants: map[string]int{
+				"default":               3,
+				"monitoring":            1,
+				SplitGroupClusterScoped: 1,
+			},
+		},
+		{
+			desc: "split by repo",
+			by:   SplitByRepo,
+			wants: map[string]int{
+				"https://github.com/kubernetes-sigs/kustomize": 3,
+				SplitGroupLocal: 2,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			groups, err := Split(resources, tc.by)
+			if err != nil {
+				t.Fatalf("failed to split resources: %s", err)
+			}
+			if len(groups) != len(tc.wants) {
+				t.Fatalf("want %d groups, got %d", len(tc.wants), len(groups))
+			}
+			for group, want := range tc.wants {
+				if got := len(groups[group]); got != want {
+					t.Fatalf("want %d resources in group %s, got %d", want, group, got)
+				}
+			}
+		})
+	}
+
+	if _, err := Split(resources, SplitBy("kind")); !errors.Is(err, ErrUnsupportedSplitBy) {
+		t.Fatalf("want error %s, got %v", ErrUnsupportedSplitBy, err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ErrInvalidStyle is returned when a style rule is not valid.
+var ErrInvalidStyle = errors.New("invalid style")
+
+// nodeStyles contains the styles supported by Graphviz for vertices.
+//
+// See https://graphviz.org/docs/attr-types/style/ for more details.
+var nodeStyles = []string{
+	"bold",
+	"dashed",
+	"diagonals",
+	"dotted",
+	"filled",
+	"invis",
+	"radial",
+	"rounded",
+	"solid",
+	"striped",
+	"wedged",
+}
+
+// isNodeStyle is a predicate, which returns true, if the given
+// comma-separated list of styles contains only styles supported for vertices.
+// An empty list is considered valid.
+func isNodeStyle(style string) bool {
+	if style == "" {
+		return true
+	}
+
+	for _, item := range strings.Split(style, ",") {
+		if !slices.Contains(nodeStyles, strings.TrimSpace(item)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// StyleRule represents a style rule, which matches resources by kind,
+// namespace, labels and origin path in the same way as a [Rule], and sets
+// the visual attributes of the vertices representing them. Empty attributes
+// are left as is.
+type StyleRule struct {
+	// Kind is the kind of matching resources
+	Kind string `yaml:"kind,omitempty"`
+
+	// Namespace is the namespace of matching resources
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Labels contains the labels, which matching resources must have
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Origin is the glob pattern matching the origin path of resources
+	Origin string `yaml:"origin,omitempty"`
+
+	// Color is the color of the vertex outline
+	Color string `yaml:"color,omitempty"`
+
+	// FillColor is the color used to fill the vertex
+	FillColor string `yaml:"fillColor,omitempty"`
+
+	// FontColor is the color of the vertex label
+	FontColor string `yaml:"fontColor,omitempty"`
+
+	// Shape is the shape of the vertex
+	Shape string `yaml:"shape,omitempty"`
+
+	// PenWidth is the width of the vertex outline
+	PenWidth float64 `yaml:"penwidth,omitempty"`
+
+	// Style is the comma-separated list of vertex styles, e.g.
+	// "filled, rounded, dashed"
+	Style string `yaml:"style,omitempty"`
+}
+
+// styleFile represents a file containing style rules.
+type styleFile struct {
+	// Styles contains the ordered list of style rules
+	Styles []*StyleRule `yaml:"styles"`
+}
+
+// StylesFromBytes parses and validates the style rules from the given data.
+func StylesFromBytes(data []byte) ([]*StyleRule, error) {
+	var sf styleFile
+	if err := yaml.Unmarshal(data, &sf); err != nil {
+		return nil, err
+	}
+
+	for i, style := range sf.Styles {
+		if err := style.Validate(); err != nil {
+			return nil, fmt.Errorf("style #%d: %w", i+1, err)
+		}
+	}
+
+	return sf.Styles, nil
+}
+
+// StylesFromPath parses and validates the style rules from the given path.
+func StylesFromPath(path string) ([]*StyleRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return StylesFromBytes(data)
+}
+
+// Validate validates the style rule.
+func (style *StyleRule) Validate() error {
+	if style.PenWidth < 0 {
+		return fmt.Errorf("%w: negative penwidth %v", ErrInvalidStyle, style.PenWidth)
+	}
+
+	if !isNodeStyle(style.Style) {
+		return fmt.Errorf("%w: unknown style %q", ErrInvalidStyle, style.Style)
+	}
+
+	if style.Color == "" && style.FillColor == "" && style.FontColor == "" && style.Shape == "" && style.PenWidth == 0 && style.Style == "" {
+		return fmt.Errorf("%w: style without attributes", ErrInvalidStyle)
+	}
+
+	return nil
+}
+
+// criteria returns a [Rule] with the matching criteria of the style rule.
+func (style *StyleRule) criteria() *Rule {
+	rule := &Rule{
+		Kind:      style.Kind,
+		Namespace: style.Namespace,
+		Labels:    style.Labels,
+		Origin:    style.Origin,
+	}
+
+	return rule
+}
+
+// matches is a predicate, which returns true, if the given resource matches
+// all criteria of the style rule.
+func (style *StyleRule) matches(r *resource.Resource) bool {
+	return style.criteria().matches(r)
+}
+
+// apply sets the attributes of the style rule on the given vertex.
+func (style *StyleRule) apply(u *graph.Vertex[string]) {
+	attrs := map[string]string{
+		"color":     style.Color,
+		"fillcolor": style.FillColor,
+		"fontcolor": style.FontColor,
+		"shape":     style.Shape,
+		"style":     style.Style,
+	}
+	if style.PenWidth > 0 {
+		attrs["penwidth"] = strconv.FormatFloat(style.PenWidth, 'f', -1, 64)
+	}
+
+	for k, v := range attrs {
+		if v != "" {
+			u.DotAttributes[k] = v
+		}
+	}
+}
+
+// applyStyles applies the style rules matching the given resource to the
+// [graph.Vertex] u in order, so that later rules override earlier ones.
+func applyStyles(styles []*StyleRule, u *graph.Vertex[string], r *resource.Resource) {
+	for _, style := range styles {
+		if style.matches(r) {
+			style.apply(u)
+		}
+	}
+}
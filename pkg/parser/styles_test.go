@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+func TestStylesFromBytes(t *testing.T) {
+	type testCase struct {
+		desc       string
+		data       string
+		wantStyles int
+		wantError  error
+	}
+
+	testCases := []testCase{
+		{
+			desc: "valid styles",
+			data: `
+styles:
+  - kind: Deployment
+    color: red
+    shape: box3d
+  - namespace: default
+    penwidth: 2.5
+`,
+			wantStyles: 2,
+		},
+		{
+			desc: "style without attributes",
+			data: `
+styles:
+  - kind: Service
+`,
+			wantError: ErrInvalidStyle,
+		},
+		{
+			desc: "unknown style",
+			data: `
+styles:
+  - kind: Service
+    style: filled, sparkly
+`,
+			wantError: ErrInvalidStyle,
+		},
+		{
+			desc: "negative penwidth",
+			data: `
+styles:
+  - kind: Service
+    penwidth: -1
+`,
+			wantError: ErrInvalidStyle,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			styles, err := StylesFromBytes([]byte(tc.data))
+			if !errors.Is(err, tc.wantError) {
+				t.Fatalf("want error %v, got %v", tc.wantError, err)
+			}
+			if len(styles) != tc.wantStyles {
+				t.Fatalf("want %d style(s), got %d", tc.wantStyles, len(styles))
+			}
+		})
+	}
+}
+
+func TestWithStyles(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithHighlightKind("Deployment", "yellow"),
+		WithStyles(
+			&StyleRule{Namespace: "default", Color: "gray", PenWidth: 2},
+			&StyleRule{Kind: "Deployment", Color: "red", Shape: "box3d"},
+			&StyleRule{Labels: map[string]string{"app": "other"}, Color: "green"},
+		),
+	)
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	type testCase struct {
+		vertex    string
+		wantAttrs graph.DotAttributes
+	}
+
+	testCases := []testCase{
+		{
+			vertex: "default/deployment/the-deployment",
+			wantAttrs: graph.DotAttributes{
+				"color":     "red",
+				"fillcolor": "yellow",
+				"penwidth":  "2",
+				"shape":     "box3d",
+			},
+		},
+		{
+			vertex: "default/service/the-service",
+			wantAttrs: graph.DotAttributes{
+				"color":    "gray",
+				"penwidth": "2",
+				"shape":    DefaultResourceShape,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		got := g.GetVertex(tc.vertex).DotAttributes
+		if !maps.Equal(got, tc.wantAttrs) {
+			t.Fatalf("want %s attributes %v, got %v", tc.vertex, tc.wantAttrs, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"maps"
+
+	"gopkg.in/dnaeon/go-graph.v1"
+)
+
+// Theme is a type which represents a named set of graph, node and edge
+// attributes, which are applied together.
+type Theme string
+
+// String implements the [fmt.Stringer] interface
+func (t Theme) String() string {
+	return string(t)
+}
+
+const (
+	// ThemeDefault specifies the default look of the graph
+	ThemeDefault Theme = "default"
+
+	// ThemeLight specifies a light theme with muted blue vertices
+	ThemeLight Theme = "light"
+
+	// ThemeDark specifies a dark theme, suitable for dark backgrounds
+	ThemeDark Theme = "dark"
+
+	// ThemePastel specifies a light theme with pastel colors
+	ThemePastel Theme = "pastel"
+
+	// ThemeColorblindSafe specifies a theme, which uses colors from the
+	// Okabe-Ito palette, which are distinguishable by people with color
+	// vision deficiencies.
+	ThemeColorblindSafe Theme = "colorblind-safe"
+)
+
+// transparentBackground is the background color of graphs rendered on a
+// transparent background.
+const transparentBackground = "transparent"
+
+// themeAttributes contains the attributes set by a [Theme].
+type themeAttributes struct {
+	// graph contains the graph attributes
+	graph graph.DotAttributes
+
+	// node contains the default attributes for all vertices
+	node graph.DotAttributes
+
+	// edge contains the default attributes for all edges
+	edge graph.DotAttributes
+
+	// highlight contains the attributes for vertices with a fill color of
+	// their own, e.g. highlighted vertices, unless already set on them.
+	highlight graph.DotAttributes
+}
+
+// themes contains the attributes of the supported themes. The default theme
+// doesn't override any attributes.
+var themes = map[Theme]themeAttributes{
+	ThemeDefault: {},
+	ThemeLight: {
+		graph: graph.DotAttributes{
+			"bgcolor":   "white",
+			"fontcolor": "#1f2933",
+		},
+		node: graph.DotAttributes{
+			"color":     "#4a90d9",
+			"fillcolor": "#e8f1fb",
+			"fontcolor": "#1f2933",
+		},
+		edge: graph.DotAttributes{
+			"color":     "#52606d",
+			"fontcolor": "#52606d",
+		},
+	},
+	ThemeDark: {
+		graph: graph.DotAttributes{
+			"bgcolor":   "#1e1e1e",
+			"fontcolor": "#d4d4d4",
+			"pencolor":  "#a0a0a0",
+		},
+		node: graph.DotAttributes{
+			"color":     "#569cd6",
+			"fillcolor": "#264f78",
+			"fontcolor": "#ffffff",
+		},
+		edge: graph.DotAttributes{
+			"color":     "#a0a0a0",
+			"fontcolor": "#d4d4d4",
+		},
+		highlight: graph.DotAttributes{
+			"fontcolor": "#1e1e1e",
+		},
+	},
+	ThemePastel: {
+		graph: graph.DotAttributes{
+			"bgcolor":   "#fffaf4",
+			"fontcolor": "#6d6875",
+		},
+		node: graph.DotAttributes{
+			"color":     "#b5838d",
+			"fillcolor": "#ffe5d9",
+			"fontcolor": "#4a4e69",
+		},
+		edge: graph.DotAttributes{
+			"color":     "#9a8c98",
+			"fontcolor": "#6d6875",
+		},
+	},
+	ThemeColorblindSafe: {
+		graph: graph.DotAttributes{
+			"bgcolor":   "white",
+			"fontcolor": "black",
+		},
+		node: graph.DotAttributes{
+			"color":     "#0072b2",
+			"fillcolor": "#56b4e9",
+			"fontcolor": "black",
+		},
+		edge: graph.DotAttributes{
+			"color":     "#d55e00",
+			"fontcolor": "#d55e00",
+		},
+	},
+}
+
+// Themes returns the names of the supported themes.
+func Themes() []Theme {
+	return []Theme{
+		ThemeDefault,
+		ThemeLight,
+		ThemeDark,
+		ThemePastel,
+		ThemeColorblindSafe,
+	}
+}
+
+// applyTheme applies the attributes of the given [Theme] to the graph and to
+// the vertices with a fill color of their own. Unknown themes are ignored.
+func applyTheme(g *Graph, theme Theme) {
+	attrs, ok := themes[theme]
+	if !ok {
+		return
+	}
+
+	maps.Copy(g.GetDotAttributes(), attrs.graph)
+	maps.Copy(g.nodeAttributes, attrs.node)
+	maps.Copy(g.edgeAttributes, attrs.edge)
+
+	for _, v := range g.GetVertices() {
+		if _, ok := v.DotAttributes["fillcolor"]; !ok {
+			continue
+		}
+		for k, val := range attrs.highlight {
+			if _, ok := v.DotAttributes[k]; !ok {
+				v.DotAttributes[k] = val
+			}
+		}
+	}
+}
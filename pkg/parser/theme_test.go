@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   1. Redistributions of source code must retain the above copyright
+//      notice, this list of conditions and the following disclaimer.
+//   2. Redistributions in binary form must reproduce the above copyright
+//      notice, this list of conditions and the following disclaimer in the
+//      documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/kustomize-dot/pkg/fixtures"
+)
+
+func TestWithTheme(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc     string
+		theme    Theme
+		wantDot  []string
+		wantNode string
+	}
+
+	testCases := []testCase{
+		{
+			desc:     "default theme",
+			theme:    ThemeDefault,
+			wantDot:  []string{`rankdir="LR"`},
+			wantNode: "lightblue",
+		},
+		{
+			desc:     "dark theme",
+			theme:    ThemeDark,
+			wantDot:  []string{`bgcolor="#1e1e1e"`, `pencolor="#a0a0a0"`, `edge [color="#a0a0a0" fontcolor="#d4d4d4"]`},
+			wantNode: "#264f78",
+		},
+		{
+			desc:     "colorblind-safe theme",
+			theme:    ThemeColorblindSafe,
+			wantDot:  []string{`bgcolor="white"`},
+			wantNode: "#56b4e9",
+		},
+		{
+			desc:     "unknown theme",
+			theme:    Theme("unknown"),
+			wantDot:  []string{`rankdir="LR"`},
+			wantNode: "lightblue",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := New(WithTheme(tc.theme))
+			g, err := p.ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			if got := g.nodeAttributes["fillcolor"]; got != tc.wantNode {
+				t.Fatalf("want node fillcolor %q, got %q", tc.wantNode, got)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDot(g, &buf); err != nil {
+				t.Fatalf("failed to write dot: %s", err)
+			}
+			for _, want := range tc.wantDot {
+				if !strings.Contains(buf.String(), want) {
+					t.Fatalf("want %s in dot output", want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithThemeHighlights(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	p := New(
+		WithTheme(ThemeDark),
+		WithHighlightKind("Deployment", "yellow"),
+		WithStyles(&StyleRule{Kind: "Service", FillColor: "pink", FontColor: "blue"}),
+	)
+	g, err := p.ParseGraph(resources)
+	if err != nil {
+		t.Fatalf("failed to parse resources as graph: %s", err)
+	}
+
+	type testCase struct {
+		vertex        string
+		wantFontColor string
+	}
+
+	testCases := []testCase{
+		{vertex: "default/deployment/the-deployment", wantFontColor: "#1e1e1e"},
+		{vertex: "default/service/the-service", wantFontColor: "blue"},
+		{vertex: "default/configmap/the-map", wantFontColor: ""},
+	}
+
+	for _, tc := range testCases {
+		got := g.GetVertex(tc.vertex).DotAttributes["fontcolor"]
+		if got != tc.wantFontColor {
+			t.Fatalf("want %s fontcolor %q, got %q", tc.vertex, tc.wantFontColor, got)
+		}
+	}
+}
+
+func TestWithBackground(t *testing.T) {
+	resources, err := ResourcesFromBytes([]byte(fixtures.HelloWorld))
+	if err != nil {
+		t.Fatalf("parsing resources failed: %s", err)
+	}
+
+	type testCase struct {
+		desc        string
+		opts        []Option
+		wantBgColor string
+	}
+
+	testCases := []testCase{
+		{
+			desc:        "no background",
+			opts:        []Option{},
+			wantBgColor: "",
+		},
+		{
+			desc:        "background of the theme",
+			opts:        []Option{WithTheme(ThemeDark)},
+			wantBgColor: "#1e1e1e",
+		},
+		{
+			desc:        "background overrides the theme",
+			opts:        []Option{WithTheme(ThemeDark), WithBackground("navy")},
+			wantBgColor: "navy",
+		},
+		{
+			desc:        "transparent background",
+			opts:        []Option{WithTheme(ThemeLight), WithTransparentBackground()},
+			wantBgColor: "transparent",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := New(tc.opts...).ParseGraph(resources)
+			if err != nil {
+				t.Fatalf("failed to parse resources as graph: %s", err)
+			}
+
+			if got := g.GetDotAttributes()["bgcolor"]; got != tc.wantBgColor {
+				t.Fatalf("want bgcolor %q, got %q", tc.wantBgColor, got)
+			}
+		})
+	}
+}
+
+func TestThemes(t *testing.T) {
+	for _, theme := range Themes() {
+		if _, ok := themes[theme]; !ok {
+			t.Fatalf("theme %s has no attributes", theme)
+		}
+	}
+}